@@ -0,0 +1,324 @@
+// Package analytics aggregates transactions by labels, category, member, counterparty and period
+package analytics
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pixelfactoryio/goqonto/v2"
+)
+
+// Dimension grouping dimension. Keys returns the keys of a transaction along the dimension, a
+// transaction with several keys (e.g. several labels) is counted in every group.
+type Dimension struct {
+	Name string
+	Keys func(t goqonto.Transaction) []string
+}
+
+// ByLabel groups transactions by label name, unlabelled transactions have an empty key
+var ByLabel = Dimension{Name: "label", Keys: func(t goqonto.Transaction) []string {
+	if len(t.Labels) == 0 {
+		return []string{""}
+	}
+	keys := make([]string, 0, len(t.Labels))
+	for _, l := range t.Labels {
+		keys = append(keys, l.Name)
+	}
+	return keys
+}}
+
+// ByCategory groups transactions by category
+var ByCategory = field("category", func(t goqonto.Transaction) string { return t.Category })
+
+// ByInitiator groups transactions by initiator membership ID
+var ByInitiator = field("initiator", func(t goqonto.Transaction) string { return t.InitiatorID })
+
+// ByOperationType groups transactions by operation type
+var ByOperationType = field("operation_type", func(t goqonto.Transaction) string { return t.OperationType })
+
+// BySide groups transactions by side
+var BySide = field("side", func(t goqonto.Transaction) string { return t.Side })
+
+// ByCounterparty groups transactions by counterparty label, ignoring case and surrounding spaces
+var ByCounterparty = field("counterparty", Counterparty)
+
+// ByMonth groups transactions by month (yyyy-MM) of their date in loc, UTC if nil
+func ByMonth(loc *time.Location) Dimension {
+	return field("month", func(t goqonto.Transaction) string {
+		return Date(t).In(location(loc)).Format("2006-01")
+	})
+}
+
+// ByWeek groups transactions by ISO 8601 week (yyyy-Www) of their date in loc, UTC if nil
+func ByWeek(loc *time.Location) Dimension {
+	return field("week", func(t goqonto.Transaction) string {
+		year, week := Date(t).In(location(loc)).ISOWeek()
+		return fmt.Sprintf("%d-W%02d", year, week)
+	})
+}
+
+func field(name string, value func(goqonto.Transaction) string) Dimension {
+	return Dimension{Name: name, Keys: func(t goqonto.Transaction) []string {
+		return []string{value(t)}
+	}}
+}
+
+func location(loc *time.Location) *time.Location {
+	if loc == nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// Counterparty returns the normalized counterparty of a transaction
+func Counterparty(t goqonto.Transaction) string {
+	return strings.ToUpper(strings.Join(strings.Fields(t.Label), " "))
+}
+
+// Date returns the settlement date of a transaction, or its emission date if it is not settled
+func Date(t goqonto.Transaction) time.Time {
+	if !t.SettledAt.IsZero() {
+		return t.SettledAt
+	}
+	return t.EmittedAt
+}
+
+// Total amounts of a group in a single currency
+type Total struct {
+	Currency    string
+	Count       int
+	DebitCents  int
+	CreditCents int
+}
+
+// NetCents returns the credits minus the debits
+func (t Total) NetCents() int {
+	return t.CreditCents - t.DebitCents
+}
+
+// Group aggregated transactions sharing the same keys
+type Group struct {
+	// Keys along each dimension, in the order of the dimensions.
+	Keys []string
+
+	// Number of transactions.
+	Count int
+
+	// Totals by currency, sorted by currency. Amounts in different currencies are never summed.
+	Totals []Total
+}
+
+// Total returns the total of the group in currency
+func (g Group) Total(currency string) Total {
+	for _, t := range g.Totals {
+		if t.Currency == currency {
+			return t
+		}
+	}
+	return Total{Currency: currency}
+}
+
+func (g *Group) add(t goqonto.Transaction) {
+	g.Count++
+
+	k := sort.Search(len(g.Totals), func(i int) bool { return g.Totals[i].Currency >= t.Currency })
+	if k == len(g.Totals) || g.Totals[k].Currency != t.Currency {
+		g.Totals = append(g.Totals, Total{})
+		copy(g.Totals[k+1:], g.Totals[k:])
+		g.Totals[k] = Total{Currency: t.Currency}
+	}
+
+	g.Totals[k].Count++
+	if t.Side == goqonto.TransactionSideDebit {
+		g.Totals[k].DebitCents += t.AmountCents
+	} else {
+		g.Totals[k].CreditCents += t.AmountCents
+	}
+}
+
+// GroupBy aggregates the transactions along the combination of dimensions. Groups are sorted by
+// keys. Declined and reversed transactions are ignored.
+func GroupBy(transactions []goqonto.Transaction, dimensions ...Dimension) []Group {
+	index := make(map[string]*Group)
+	var groups []*Group
+
+	for _, t := range transactions {
+		if t.Status == goqonto.TransactionStatusDeclined || t.Status == goqonto.TransactionStatusReversed {
+			continue
+		}
+
+		for _, keys := range combinations(t, dimensions) {
+			id := strings.Join(keys, "\x00")
+			g, ok := index[id]
+			if !ok {
+				g = &Group{Keys: keys}
+				index[id] = g
+				groups = append(groups, g)
+			}
+			g.add(t)
+		}
+	}
+
+	sort.Slice(groups, func(i, j int) bool {
+		return lessKeys(groups[i].Keys, groups[j].Keys)
+	})
+
+	result := make([]Group, len(groups))
+	for k, g := range groups {
+		result[k] = *g
+	}
+	return result
+}
+
+// combinations returns every combination of the keys of t along the dimensions
+func combinations(t goqonto.Transaction, dimensions []Dimension) [][]string {
+	result := [][]string{{}}
+
+	for _, d := range dimensions {
+		var next [][]string
+		for _, prefix := range result {
+			seen := make(map[string]bool)
+			for _, key := range d.Keys(t) {
+				if seen[key] {
+					continue
+				}
+				seen[key] = true
+				next = append(next, append(append([]string(nil), prefix...), key))
+			}
+		}
+		result = next
+	}
+
+	return result
+}
+
+func lessKeys(a, b []string) bool {
+	for k := range a {
+		if a[k] != b[k] {
+			return a[k] < b[k]
+		}
+	}
+	return false
+}
+
+// TopCounterparties returns the n counterparties with the largest amounts in currency on the
+// given side, by decreasing amount
+func TopCounterparties(transactions []goqonto.Transaction, currency, side string, n int) []Group {
+	var filtered []goqonto.Transaction
+	for _, t := range transactions {
+		if t.Currency == currency && t.Side == side {
+			filtered = append(filtered, t)
+		}
+	}
+
+	groups := GroupBy(filtered, ByCounterparty)
+
+	amount := func(g Group) int {
+		total := g.Total(currency)
+		return total.DebitCents + total.CreditCents
+	}
+
+	sort.SliceStable(groups, func(i, j int) bool {
+		return amount(groups[i]) > amount(groups[j])
+	})
+
+	if n >= 0 && len(groups) > n {
+		groups = groups[:n]
+	}
+	return groups
+}
+
+// Delta change of the net amount of a group between a month and the previous one
+type Delta struct {
+	// Keys along the dimensions, without the month.
+	Keys []string
+
+	Currency string
+	Month    string
+
+	Cents         int
+	PreviousCents int
+	DeltaCents    int
+
+	// Relative change, 0 if the previous amount is 0.
+	Percent float64
+}
+
+// MonthOverMonth returns the month-over-month changes of the net amounts (credits minus debits) of
+// the groups along the dimensions, months being computed in loc. Months without transactions
+// between the first and last month of a group count as zero. Deltas are sorted by keys, currency
+// and month.
+func MonthOverMonth(transactions []goqonto.Transaction, loc *time.Location, dimensions ...Dimension) []Delta {
+	groups := GroupBy(transactions, append(append([]Dimension(nil), dimensions...), ByMonth(loc))...)
+
+	type series struct {
+		keys     []string
+		currency string
+		months   map[string]int
+	}
+
+	index := make(map[string]*series)
+	var all []*series
+
+	for _, g := range groups {
+		keys, month := g.Keys[:len(dimensions)], g.Keys[len(dimensions)]
+		for _, total := range g.Totals {
+			id := strings.Join(append(append([]string(nil), keys...), total.Currency), "\x00")
+			s, ok := index[id]
+			if !ok {
+				s = &series{keys: keys, currency: total.Currency, months: make(map[string]int)}
+				index[id] = s
+				all = append(all, s)
+			}
+			s.months[month] = total.NetCents()
+		}
+	}
+
+	sort.SliceStable(all, func(i, j int) bool {
+		if lessKeys(all[i].keys, all[j].keys) || lessKeys(all[j].keys, all[i].keys) {
+			return lessKeys(all[i].keys, all[j].keys)
+		}
+		return all[i].currency < all[j].currency
+	})
+
+	var deltas []Delta
+	for _, s := range all {
+		months := make([]string, 0, len(s.months))
+		for m := range s.months {
+			months = append(months, m)
+		}
+		sort.Strings(months)
+
+		first, _ := time.Parse("2006-01", months[0])
+		last, _ := time.Parse("2006-01", months[len(months)-1])
+
+		previous := s.months[months[0]]
+		for m := first.AddDate(0, 1, 0); !m.After(last); m = m.AddDate(0, 1, 0) {
+			d := Delta{
+				Keys:          s.keys,
+				Currency:      s.currency,
+				Month:         m.Format("2006-01"),
+				Cents:         s.months[m.Format("2006-01")],
+				PreviousCents: previous,
+			}
+			d.DeltaCents = d.Cents - d.PreviousCents
+			if d.PreviousCents != 0 {
+				d.Percent = float64(d.DeltaCents) / float64(abs(d.PreviousCents)) * 100
+			}
+
+			deltas = append(deltas, d)
+			previous = d.Cents
+		}
+	}
+
+	return deltas
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
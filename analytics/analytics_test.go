@@ -0,0 +1,90 @@
+package analytics
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/pixelfactoryio/goqonto/v2"
+)
+
+func tx(label, side, currency string, cents int, month time.Month, day int, labels ...string) goqonto.Transaction {
+	t := goqonto.Transaction{
+		Label:       label,
+		Side:        side,
+		Currency:    currency,
+		AmountCents: cents,
+		Status:      goqonto.TransactionStatusCompleted,
+		Category:    "other",
+		SettledAt:   time.Date(2020, month, day, 12, 0, 0, 0, time.UTC),
+	}
+	for _, l := range labels {
+		t.Labels = append(t.Labels, goqonto.Label{Name: l})
+	}
+	return t
+}
+
+func testTransactions() []goqonto.Transaction {
+	declined := tx("Declined", "debit", "EUR", 99900, time.January, 5)
+	declined.Status = goqonto.TransactionStatusDeclined
+
+	return []goqonto.Transaction{
+		tx("AWS", "debit", "EUR", 10000, time.January, 3, "infra", "eng"),
+		tx("aws ", "debit", "EUR", 12000, time.February, 3, "infra"),
+		tx("Github", "debit", "USD", 2100, time.January, 10, "eng"),
+		tx("Customer", "credit", "EUR", 50000, time.January, 20),
+		tx("Bakery", "debit", "EUR", 500, time.March, 1),
+		declined,
+	}
+}
+
+func TestGroupBy(t *testing.T) {
+	groups := GroupBy(testTransactions(), ByLabel, BySide)
+
+	want := []Group{
+		{Keys: []string{"", "credit"}, Count: 1, Totals: []Total{{Currency: "EUR", Count: 1, CreditCents: 50000}}},
+		{Keys: []string{"", "debit"}, Count: 1, Totals: []Total{{Currency: "EUR", Count: 1, DebitCents: 500}}},
+		{Keys: []string{"eng", "debit"}, Count: 2, Totals: []Total{
+			{Currency: "EUR", Count: 1, DebitCents: 10000},
+			{Currency: "USD", Count: 1, DebitCents: 2100},
+		}},
+		{Keys: []string{"infra", "debit"}, Count: 2, Totals: []Total{{Currency: "EUR", Count: 2, DebitCents: 22000}}},
+	}
+
+	if !reflect.DeepEqual(groups, want) {
+		t.Errorf("GroupBy \n got %v\n want %v\n", groups, want)
+	}
+}
+
+func TestGroupByWeek(t *testing.T) {
+	groups := GroupBy(testTransactions()[:1], ByWeek(nil), ByCategory)
+
+	if len(groups) != 1 || !reflect.DeepEqual(groups[0].Keys, []string{"2020-W01", "other"}) {
+		t.Errorf("GroupBy week \n got %v\n want [2020-W01 other]\n", groups)
+	}
+}
+
+func TestTopCounterparties(t *testing.T) {
+	top := TopCounterparties(testTransactions(), "EUR", goqonto.TransactionSideDebit, 1)
+
+	want := []Group{{Keys: []string{"AWS"}, Count: 2, Totals: []Total{{Currency: "EUR", Count: 2, DebitCents: 22000}}}}
+	if !reflect.DeepEqual(top, want) {
+		t.Errorf("TopCounterparties \n got %v\n want %v\n", top, want)
+	}
+}
+
+func TestMonthOverMonth(t *testing.T) {
+	deltas := MonthOverMonth(testTransactions(), nil, ByLabel)
+
+	want := []Delta{
+		{Keys: []string{""}, Currency: "EUR", Month: "2020-02", Cents: 0, PreviousCents: 50000,
+			DeltaCents: -50000, Percent: -100},
+		{Keys: []string{""}, Currency: "EUR", Month: "2020-03", Cents: -500, PreviousCents: 0, DeltaCents: -500},
+		{Keys: []string{"infra"}, Currency: "EUR", Month: "2020-02", Cents: -12000, PreviousCents: -10000,
+			DeltaCents: -2000, Percent: -20},
+	}
+
+	if !reflect.DeepEqual(deltas, want) {
+		t.Errorf("MonthOverMonth \n got %+v\n want %+v\n", deltas, want)
+	}
+}
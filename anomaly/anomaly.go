@@ -0,0 +1,276 @@
+// Package anomaly flags unusual card transactions against baselines learned from the card and member
+// history
+package anomaly
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pixelfactoryio/goqonto/v2"
+)
+
+// AlertAmount is an amount far from the usual amounts of the card.
+const AlertAmount = "amount"
+
+// AlertForeignCurrency is a payment in a foreign currency never used with the card.
+const AlertForeignCurrency = "foreign_currency"
+
+// AlertNewMerchant is a first payment to a merchant.
+const AlertNewMerchant = "new_merchant"
+
+// AlertUnusualHour is a payment at an hour the card is seldom used.
+const AlertUnusualHour = "unusual_hour"
+
+// AlertDuplicate is a payment of the same amount to the same merchant shortly after another one.
+const AlertDuplicate = "duplicate"
+
+// Options detection options
+type Options struct {
+	// Location in which hours are computed. Defaults to UTC.
+	Location *time.Location
+
+	// Absolute z-score above which an amount is an outlier. Defaults to 3.
+	ZScoreThreshold float64
+
+	// Minimum number of payments of a baseline before amounts and hours are checked. Defaults to 10.
+	MinSamples int
+
+	// Share of the payments below which an hour is unusual. Defaults to 0.02.
+	HourMinShare float64
+
+	// Window in which identical payments are duplicates. Defaults to 10 minutes.
+	DuplicateWindow time.Duration
+}
+
+// Alert unusual transaction
+type Alert struct {
+	Transaction goqonto.Transaction
+	Kind        string
+
+	// Z-score for AlertAmount, 0 otherwise.
+	Score float64
+
+	Message string
+}
+
+// stats baseline of a card or a member
+type stats struct {
+	count      int
+	mean, m2   float64
+	currencies map[string]bool
+	merchants  map[string]bool
+	hours      [24]int
+}
+
+func newStats() *stats {
+	return &stats{currencies: make(map[string]bool), merchants: make(map[string]bool)}
+}
+
+// add updates the baseline with Welford's online algorithm
+func (s *stats) add(t goqonto.Transaction, loc *time.Location) {
+	s.count++
+	amount := float64(t.AmountCents)
+	delta := amount - s.mean
+	s.mean += delta / float64(s.count)
+	s.m2 += delta * (amount - s.mean)
+
+	s.currencies[t.LocalCurrency] = true
+	s.merchants[merchant(t)] = true
+	s.hours[t.EmittedAt.In(loc).Hour()]++
+}
+
+func (s *stats) stddev() float64 {
+	if s.count < 2 {
+		return 0
+	}
+	return math.Sqrt(s.m2 / float64(s.count-1))
+}
+
+// Detector card anomaly detector
+type Detector struct {
+	opt     Options
+	cards   map[string]*stats
+	members map[string]*stats
+	history []goqonto.Transaction
+}
+
+// NewDetector returns a detector whose per-card and per-member baselines are learned from history.
+// Only card debits which were not declined nor reversed are learned.
+func NewDetector(history []goqonto.Transaction, opt *Options) *Detector {
+	d := &Detector{cards: make(map[string]*stats), members: make(map[string]*stats)}
+
+	if opt != nil {
+		d.opt = *opt
+	}
+	if d.opt.Location == nil {
+		d.opt.Location = time.UTC
+	}
+	if d.opt.ZScoreThreshold == 0 {
+		d.opt.ZScoreThreshold = 3
+	}
+	if d.opt.MinSamples == 0 {
+		d.opt.MinSamples = 10
+	}
+	if d.opt.HourMinShare == 0 {
+		d.opt.HourMinShare = 0.02
+	}
+	if d.opt.DuplicateWindow == 0 {
+		d.opt.DuplicateWindow = 10 * time.Minute
+	}
+
+	for _, t := range history {
+		d.Learn(t)
+	}
+
+	return d
+}
+
+// Learn adds a transaction to the baselines
+func (d *Detector) Learn(t goqonto.Transaction) {
+	if !isCardPayment(t) {
+		return
+	}
+
+	card, ok := d.cards[cardKey(t)]
+	if !ok {
+		card = newStats()
+		d.cards[cardKey(t)] = card
+	}
+	card.add(t, d.opt.Location)
+
+	member, ok := d.members[t.InitiatorID]
+	if !ok {
+		member = newStats()
+		d.members[t.InitiatorID] = member
+	}
+	member.add(t, d.opt.Location)
+
+	d.history = append(d.history, t)
+}
+
+// Detect returns the alerts raised by the transactions, sorted by emission date. The baselines of the
+// card are used, or those of the member if the card has fewer than MinSamples payments. Transactions
+// are not learned, call Learn once they are reviewed.
+func (d *Detector) Detect(transactions []goqonto.Transaction) []Alert {
+	var alerts []Alert
+
+	var seen []goqonto.Transaction
+	for _, t := range transactions {
+		if !isCardPayment(t) {
+			continue
+		}
+
+		alerts = append(alerts, d.check(t)...)
+		if dup, ok := d.duplicate(t, seen); ok {
+			alerts = append(alerts, Alert{
+				Transaction: t,
+				Kind:        AlertDuplicate,
+				Message: fmt.Sprintf("same amount charged by %s at %s", t.Label,
+					dup.EmittedAt.In(d.opt.Location).Format("2006-01-02 15:04")),
+			})
+		}
+		seen = append(seen, t)
+	}
+
+	sort.SliceStable(alerts, func(i, j int) bool {
+		return alerts[i].Transaction.EmittedAt.Before(alerts[j].Transaction.EmittedAt)
+	})
+
+	return alerts
+}
+
+func (d *Detector) check(t goqonto.Transaction) []Alert {
+	var alerts []Alert
+
+	s := d.cards[cardKey(t)]
+	if s == nil || s.count < d.opt.MinSamples {
+		if member := d.members[t.InitiatorID]; member != nil && (s == nil || member.count > s.count) {
+			s = member
+		}
+	}
+	if s == nil {
+		s = newStats()
+	}
+
+	if t.LocalCurrency != "" && t.LocalCurrency != t.Currency && !s.currencies[t.LocalCurrency] {
+		alerts = append(alerts, Alert{
+			Transaction: t,
+			Kind:        AlertForeignCurrency,
+			Message:     fmt.Sprintf("first payment in %s", t.LocalCurrency),
+		})
+	}
+
+	if !s.merchants[merchant(t)] {
+		alerts = append(alerts, Alert{
+			Transaction: t,
+			Kind:        AlertNewMerchant,
+			Message:     fmt.Sprintf("first payment to %s", t.Label),
+		})
+	}
+
+	if s.count < d.opt.MinSamples {
+		return alerts
+	}
+
+	if sd := s.stddev(); sd > 0 {
+		z := (float64(t.AmountCents) - s.mean) / sd
+		if math.Abs(z) > d.opt.ZScoreThreshold {
+			alerts = append(alerts, Alert{
+				Transaction: t,
+				Kind:        AlertAmount,
+				Score:       z,
+				Message:     fmt.Sprintf("amount %.1f standard deviations from the usual amount", z),
+			})
+		}
+	}
+
+	hour := t.EmittedAt.In(d.opt.Location).Hour()
+	if float64(s.hours[hour]) < d.opt.HourMinShare*float64(s.count) || s.hours[hour] == 0 {
+		alerts = append(alerts, Alert{
+			Transaction: t,
+			Kind:        AlertUnusualHour,
+			Message:     fmt.Sprintf("payment at %02dh, an unusual hour for this card", hour),
+		})
+	}
+
+	return alerts
+}
+
+// duplicate returns a payment of the same card, merchant and local amount emitted within the
+// duplicate window before or after t, in the history or in seen
+func (d *Detector) duplicate(t goqonto.Transaction, seen []goqonto.Transaction) (goqonto.Transaction, bool) {
+	for _, candidates := range [][]goqonto.Transaction{seen, d.history} {
+		for _, c := range candidates {
+			if t.ID != "" && c.ID == t.ID {
+				continue
+			}
+
+			delta := t.EmittedAt.Sub(c.EmittedAt)
+			if delta < 0 {
+				delta = -delta
+			}
+
+			if cardKey(c) == cardKey(t) && merchant(c) == merchant(t) && c.LocalAmountCents == t.LocalAmountCents &&
+				c.LocalCurrency == t.LocalCurrency && delta <= d.opt.DuplicateWindow {
+				return c, true
+			}
+		}
+	}
+	return goqonto.Transaction{}, false
+}
+
+func isCardPayment(t goqonto.Transaction) bool {
+	return t.OperationType == goqonto.TransactionOperationTypeCard && t.Side == goqonto.TransactionSideDebit &&
+		t.Status != goqonto.TransactionStatusDeclined && t.Status != goqonto.TransactionStatusReversed
+}
+
+func cardKey(t goqonto.Transaction) string {
+	return t.InitiatorID + "/" + t.CardLastDigits
+}
+
+func merchant(t goqonto.Transaction) string {
+	return strings.ToUpper(strings.Join(strings.Fields(t.Label), " "))
+}
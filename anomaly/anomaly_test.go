@@ -0,0 +1,92 @@
+package anomaly
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/pixelfactoryio/goqonto/v2"
+)
+
+func payment(id, label string, cents int, currency string, at time.Time) goqonto.Transaction {
+	return goqonto.Transaction{
+		ID:               id,
+		Label:            label,
+		OperationType:    goqonto.TransactionOperationTypeCard,
+		Side:             goqonto.TransactionSideDebit,
+		Status:           goqonto.TransactionStatusCompleted,
+		InitiatorID:      "member-1",
+		CardLastDigits:   "1234",
+		Currency:         "EUR",
+		AmountCents:      cents,
+		LocalCurrency:    currency,
+		LocalAmountCents: cents,
+		EmittedAt:        at,
+	}
+}
+
+func testHistory() []goqonto.Transaction {
+	var history []goqonto.Transaction
+	start := time.Date(2020, time.January, 1, 12, 0, 0, 0, time.UTC)
+	for i := 0; i < 20; i++ {
+		at := start.AddDate(0, 0, i).Add(time.Duration(i%4) * time.Hour)
+		history = append(history, payment(fmt.Sprintf("h%d", i), "Restaurant", 2000+(i%5)*100, "EUR", at))
+	}
+	return history
+}
+
+func TestDetect(t *testing.T) {
+	d := NewDetector(testHistory(), nil)
+
+	at := time.Date(2020, time.February, 1, 13, 0, 0, 0, time.UTC)
+	transactions := []goqonto.Transaction{
+		payment("t1", "Restaurant", 2100, "EUR", at),
+		payment("t2", "Restaurant", 25000, "EUR", at.Add(time.Hour)),
+		payment("t3", "Hotel London", 9000, "GBP", at.Add(2*time.Hour)),
+		payment("t4", "Restaurant", 2200, "EUR", at.Add(11*time.Hour)),
+		payment("t5", "Restaurant", 2100, "EUR", at.Add(5*time.Minute)),
+	}
+
+	alerts := d.Detect(transactions)
+
+	var got []string
+	for _, a := range alerts {
+		got = append(got, a.Transaction.ID+" "+a.Kind)
+	}
+
+	want := []string{
+		"t5 duplicate",
+		"t2 amount",
+		"t3 foreign_currency",
+		"t3 new_merchant",
+		"t3 amount",
+		"t4 unusual_hour",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Detect \n got %v\n want %v\n", got, want)
+	}
+
+	if alerts[1].Score < 3 {
+		t.Errorf("Detect amount score \n got %v\n want > 3\n", alerts[1].Score)
+	}
+}
+
+func TestDetectMemberBaseline(t *testing.T) {
+	d := NewDetector(testHistory(), nil)
+
+	// A new card of the member falls back to the member baseline.
+	tx := payment("t1", "Restaurant", 2100, "EUR", time.Date(2020, time.February, 1, 13, 0, 0, 0, time.UTC))
+	tx.CardLastDigits = "9999"
+
+	if alerts := d.Detect([]goqonto.Transaction{tx}); len(alerts) != 0 {
+		t.Errorf("Detect \n got %v\n want no alert\n", alerts)
+	}
+
+	// Non card transactions are ignored.
+	tx.OperationType = goqonto.TransactionOperationTypeTransfer
+	tx.AmountCents = 1000000
+	if alerts := d.Detect([]goqonto.Transaction{tx}); len(alerts) != 0 {
+		t.Errorf("Detect \n got %v\n want no alert\n", alerts)
+	}
+}
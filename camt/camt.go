@@ -0,0 +1,640 @@
+// Package camt generates ISO 20022 camt.053 end of day statements and camt.052 intraday
+// account reports from Qonto bank accounts and transactions
+package camt
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pixelfactoryio/goqonto/v2"
+	"github.com/pixelfactoryio/goqonto/v2/history"
+)
+
+// Namespaces of the supported ISO 20022 messages
+const (
+	NamespaceCamt052 = "urn:iso:std:iso:20022:tech:xsd:camt.052.001.02"
+	NamespaceCamt053 = "urn:iso:std:iso:20022:tech:xsd:camt.053.001.02"
+)
+
+// Balance types
+const (
+	BalanceOpeningBooked    = "OPBD"
+	BalanceClosingBooked    = "CLBD"
+	BalanceClosingAvailable = "CLAV"
+	BalanceInterimBooked    = "ITBD"
+	BalanceInterimAvailable = "ITAV"
+)
+
+// Credit and debit indicators
+const (
+	CreditDebitCredit = "CRDT"
+	CreditDebitDebit  = "DBIT"
+)
+
+// Entry statuses
+const (
+	EntryStatusBooked  = "BOOK"
+	EntryStatusPending = "PDNG"
+)
+
+const (
+	// proprietaryCodeIssuer issuer of the proprietary bank transaction codes
+	proprietaryCodeIssuer = "QONTO"
+
+	dateLayout = "2006-01-02"
+
+	// Maximum lengths of Max35Text, Max70Text and Max140Text elements
+	max35  = 35
+	max70  = 70
+	max140 = 140
+)
+
+// ErrNoBankAccountCurrency is returned when the bank account has no currency
+var ErrNoBankAccountCurrency = errors.New("camt: bank account currency is required")
+
+// Options statement and report generation options
+type Options struct {
+	// Message identification. Defaults to a value derived from the organization slug and CreatedAt.
+	MessageID string
+
+	// Creation date of the message. Defaults to the current time.
+	CreatedAt time.Time
+
+	// Period covered by the statement or report. Statements cover whole days, from defaults to To,
+	// To defaults to CreatedAt.
+	From time.Time
+	To   time.Time
+
+	// Location in which booking days are computed. Defaults to UTC.
+	Location *time.Location
+
+	// Electronic sequence number of the statement. Defaults to 1.
+	SequenceNumber int
+
+	// Name of the account owner. The owner is omitted when empty.
+	OwnerName string
+
+	// Time at which the bank account balances were read, see history.Options.
+	AsOf time.Time
+}
+
+// Document ISO 20022 camt document
+type Document struct {
+	XMLName   xml.Name                 `xml:"Document"`
+	Namespace string                   `xml:"xmlns,attr"`
+	Statement *BankToCustomerStatement `xml:"BkToCstmrStmt,omitempty"`
+	Report    *BankToCustomerReport    `xml:"BkToCstmrAcctRpt,omitempty"`
+}
+
+// BankToCustomerStatement camt.053 message
+type BankToCustomerStatement struct {
+	GroupHeader GroupHeader        `xml:"GrpHdr"`
+	Statements  []AccountStatement `xml:"Stmt"`
+}
+
+// BankToCustomerReport camt.052 message
+type BankToCustomerReport struct {
+	GroupHeader GroupHeader        `xml:"GrpHdr"`
+	Reports     []AccountStatement `xml:"Rpt"`
+}
+
+// GroupHeader message identification
+type GroupHeader struct {
+	MessageID string `xml:"MsgId"`
+	CreatedAt string `xml:"CreDtTm"`
+}
+
+// AccountStatement statement (camt.053) or report (camt.052) of a bank account
+type AccountStatement struct {
+	ID                       string               `xml:"Id"`
+	ElectronicSequenceNumber int                  `xml:"ElctrncSeqNb,omitempty"`
+	CreatedAt                string               `xml:"CreDtTm"`
+	Period                   *Period              `xml:"FrToDt,omitempty"`
+	Account                  Account              `xml:"Acct"`
+	Balances                 []Balance            `xml:"Bal"`
+	Summary                  *TransactionsSummary `xml:"TxsSummry,omitempty"`
+	Entries                  []Entry              `xml:"Ntry"`
+}
+
+// Period date and time range
+type Period struct {
+	From string `xml:"FrDtTm"`
+	To   string `xml:"ToDtTm"`
+}
+
+// Account cash account
+type Account struct {
+	IBAN     string `xml:"Id>IBAN"`
+	Currency string `xml:"Ccy"`
+	Name     string `xml:"Nm,omitempty"`
+	Owner    *Party `xml:"Ownr,omitempty"`
+	BIC      string `xml:"Svcr>FinInstnId>BIC,omitempty"`
+}
+
+// Party party identification
+type Party struct {
+	Name string `xml:"Nm"`
+}
+
+// Amount amount with its currency
+type Amount struct {
+	Value    string `xml:",chardata"`
+	Currency string `xml:"Ccy,attr"`
+}
+
+// Balance cash balance
+type Balance struct {
+	Type        string `xml:"Tp>CdOrPrtry>Cd"`
+	Amount      Amount `xml:"Amt"`
+	CreditDebit string `xml:"CdtDbtInd"`
+	Date        string `xml:"Dt>Dt"`
+}
+
+// TransactionsSummary number and sums of entries
+type TransactionsSummary struct {
+	Total   NumberAndSum `xml:"TtlNtries"`
+	Credits NumberAndSum `xml:"TtlCdtNtries"`
+	Debits  NumberAndSum `xml:"TtlDbtNtries"`
+}
+
+// NumberAndSum number and sum of entries, with the net amount for the total
+type NumberAndSum struct {
+	Count       int    `xml:"NbOfNtries"`
+	Sum         string `xml:"Sum"`
+	NetAmount   string `xml:"TtlNetNtryAmt,omitempty"`
+	CreditDebit string `xml:"CdtDbtInd,omitempty"`
+}
+
+// Entry statement entry
+type Entry struct {
+	Amount              Amount              `xml:"Amt"`
+	CreditDebit         string              `xml:"CdtDbtInd"`
+	Status              string              `xml:"Sts"`
+	BookingDate         *DateAndDateTime    `xml:"BookgDt,omitempty"`
+	ValueDate           *DateAndDateTime    `xml:"ValDt,omitempty"`
+	ServicerReference   string              `xml:"AcctSvcrRef,omitempty"`
+	BankTransactionCode BankTransactionCode `xml:"BkTxCd"`
+	Details             *EntryDetails       `xml:"NtryDtls,omitempty"`
+	AdditionalInfo      string              `xml:"AddtlNtryInf,omitempty"`
+}
+
+// DateAndDateTime date or date and time
+type DateAndDateTime struct {
+	Date     string `xml:"Dt,omitempty"`
+	DateTime string `xml:"DtTm,omitempty"`
+}
+
+// BankTransactionCode ISO and proprietary bank transaction codes
+type BankTransactionCode struct {
+	Domain      *Domain      `xml:"Domn,omitempty"`
+	Proprietary *Proprietary `xml:"Prtry,omitempty"`
+}
+
+// Domain ISO bank transaction code
+type Domain struct {
+	Code          string `xml:"Cd"`
+	FamilyCode    string `xml:"Fmly>Cd"`
+	SubFamilyCode string `xml:"Fmly>SubFmlyCd"`
+}
+
+// Proprietary proprietary bank transaction code
+type Proprietary struct {
+	Code   string `xml:"Cd"`
+	Issuer string `xml:"Issr"`
+}
+
+// EntryDetails details of an entry
+type EntryDetails struct {
+	Transactions []TransactionDetails `xml:"TxDtls"`
+}
+
+// TransactionDetails details of the transaction of an entry
+type TransactionDetails struct {
+	ServicerReference string          `xml:"Refs>AcctSvcrRef,omitempty"`
+	RelatedParties    *RelatedParties `xml:"RltdPties,omitempty"`
+	Remittance        *Remittance     `xml:"RmtInf,omitempty"`
+}
+
+// RelatedParties counterparties of a transaction
+type RelatedParties struct {
+	Debtor   *Party `xml:"Dbtr,omitempty"`
+	Creditor *Party `xml:"Cdtr,omitempty"`
+}
+
+// Remittance remittance information
+type Remittance struct {
+	Unstructured []string `xml:"Ustrd"`
+}
+
+// NewStatement builds the camt.053 end of day statement of a bank account for the days between
+// Options.From and Options.To. It contains the completed transactions settled during the period,
+// along with the opening and closing booked balances reconstructed from the current balances.
+func NewStatement(
+	org goqonto.Organization,
+	account goqonto.BankAccount,
+	transactions []goqonto.Transaction,
+	opt *Options) (*Document, error) {
+
+	g, err := newGenerator(org, account, opt)
+	if err != nil {
+		return nil, err
+	}
+
+	first := startOfDay(g.from, g.loc)
+	last := startOfDay(g.to, g.loc)
+	end := last.AddDate(0, 0, 1)
+
+	series, err := history.Reconstruct(account, transactions, first.AddDate(0, 0, -1), last, &history.Options{
+		Location: g.loc,
+		AsOf:     g.asOf,
+	})
+	if err != nil {
+		return nil, err
+	}
+	opening, closing := series[0], series[len(series)-1]
+
+	var entries []goqonto.Transaction
+	for _, t := range transactions {
+		if t.Status == goqonto.TransactionStatusCompleted && within(t.SettledAt, first, end) {
+			entries = append(entries, t)
+		}
+	}
+
+	stmt := g.statement("STMT", first, end.Add(-time.Second))
+	stmt.Balances = []Balance{
+		g.balance(BalanceOpeningBooked, opening.BalanceCents, first.AddDate(0, 0, -1)),
+		g.balance(BalanceClosingBooked, closing.BalanceCents, last),
+		g.balance(BalanceClosingAvailable, closing.AuthorizedBalanceCents, last),
+	}
+	g.addEntries(&stmt, entries)
+
+	return &Document{
+		Namespace: NamespaceCamt053,
+		Statement: &BankToCustomerStatement{
+			GroupHeader: g.groupHeader(),
+			Statements:  []AccountStatement{stmt},
+		},
+	}, nil
+}
+
+// NewReport builds the camt.052 intraday report of a bank account between Options.From and
+// Options.To. It contains the completed transactions settled and the pending transactions emitted
+// during the period, along with the current interim booked and available balances.
+func NewReport(
+	org goqonto.Organization,
+	account goqonto.BankAccount,
+	transactions []goqonto.Transaction,
+	opt *Options) (*Document, error) {
+
+	g, err := newGenerator(org, account, opt)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []goqonto.Transaction
+	for _, t := range transactions {
+		switch t.Status {
+		case goqonto.TransactionStatusCompleted:
+			if between(t.SettledAt, g.from, g.to) {
+				entries = append(entries, t)
+			}
+		case goqonto.TransactionStatusPending:
+			if between(t.EmittedAt, g.from, g.to) {
+				entries = append(entries, t)
+			}
+		}
+	}
+
+	rpt := g.statement("RPT", g.from, g.to)
+	rpt.Balances = []Balance{
+		g.balance(BalanceInterimBooked, account.BalanceCents, g.asOf),
+		g.balance(BalanceInterimAvailable, account.AuthorizedBalanceCents, g.asOf),
+	}
+	g.addEntries(&rpt, entries)
+
+	return &Document{
+		Namespace: NamespaceCamt052,
+		Report: &BankToCustomerReport{
+			GroupHeader: g.groupHeader(),
+			Reports:     []AccountStatement{rpt},
+		},
+	}, nil
+}
+
+// Write writes the XML document, preceded by the XML declaration, to w
+func (d *Document) Write(w io.Writer) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(d); err != nil {
+		return err
+	}
+
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// generator holds the resolved options of a statement or report
+type generator struct {
+	org       goqonto.Organization
+	account   goqonto.BankAccount
+	opt       Options
+	loc       *time.Location
+	createdAt time.Time
+	from      time.Time
+	to        time.Time
+	asOf      time.Time
+}
+
+func newGenerator(org goqonto.Organization, account goqonto.BankAccount, opt *Options) (*generator, error) {
+	if account.Currency == "" {
+		return nil, ErrNoBankAccountCurrency
+	}
+
+	g := &generator{org: org, account: account}
+	if opt != nil {
+		g.opt = *opt
+	}
+
+	g.loc = g.opt.Location
+	if g.loc == nil {
+		g.loc = time.UTC
+	}
+
+	g.createdAt = g.opt.CreatedAt
+	if g.createdAt.IsZero() {
+		g.createdAt = time.Now()
+	}
+
+	g.to = g.opt.To
+	if g.to.IsZero() {
+		g.to = g.createdAt
+	}
+
+	g.from = g.opt.From
+	if g.from.IsZero() {
+		g.from = startOfDay(g.to, g.loc)
+	}
+
+	if g.to.Before(g.from) {
+		return nil, fmt.Errorf("camt: end of period %s is before its start %s", g.to, g.from)
+	}
+
+	g.asOf = g.opt.AsOf
+	if g.asOf.IsZero() {
+		g.asOf = account.UpdatedAt
+	}
+	if g.asOf.IsZero() {
+		g.asOf = g.createdAt
+	}
+
+	return g, nil
+}
+
+func (g *generator) groupHeader() GroupHeader {
+	id := g.opt.MessageID
+	if id == "" {
+		id = fmt.Sprintf("%s-%s", g.org.Slug, g.createdAt.UTC().Format("20060102150405"))
+	}
+
+	return GroupHeader{
+		MessageID: truncate(id, max35),
+		CreatedAt: g.createdAt.Format(time.RFC3339),
+	}
+}
+
+func (g *generator) statement(prefix string, from, to time.Time) AccountStatement {
+	seq := g.opt.SequenceNumber
+	if seq == 0 {
+		seq = 1
+	}
+
+	account := Account{
+		IBAN:     g.account.IBAN,
+		Currency: g.account.Currency,
+		Name:     truncate(g.account.Name, max70),
+		BIC:      g.account.BIC,
+	}
+	if g.opt.OwnerName != "" {
+		account.Owner = &Party{Name: truncate(g.opt.OwnerName, max140)}
+	}
+
+	return AccountStatement{
+		ID:                       truncate(fmt.Sprintf("%s-%s-%d", prefix, from.Format("20060102"), seq), max35),
+		ElectronicSequenceNumber: seq,
+		CreatedAt:                g.createdAt.Format(time.RFC3339),
+		Period: &Period{
+			From: from.Format(time.RFC3339),
+			To:   to.Format(time.RFC3339),
+		},
+		Account: account,
+	}
+}
+
+func (g *generator) balance(typ string, cents int, at time.Time) Balance {
+	indicator := CreditDebitCredit
+	if cents < 0 {
+		indicator = CreditDebitDebit
+		cents = -cents
+	}
+
+	return Balance{
+		Type:        typ,
+		Amount:      g.amount(cents),
+		CreditDebit: indicator,
+		Date:        at.In(g.loc).Format(dateLayout),
+	}
+}
+
+func (g *generator) amount(cents int) Amount {
+	return Amount{
+		Value:    formatCents(cents),
+		Currency: g.account.Currency,
+	}
+}
+
+// addEntries adds the entries of the transactions, ordered by booking date, and their summary
+func (g *generator) addEntries(stmt *AccountStatement, transactions []goqonto.Transaction) {
+	sort.SliceStable(transactions, func(i, j int) bool {
+		return entryDate(transactions[i]).Before(entryDate(transactions[j]))
+	})
+
+	var credits, debits NumberAndSum
+	var creditCents, debitCents int
+
+	for _, t := range transactions {
+		stmt.Entries = append(stmt.Entries, g.entry(t))
+
+		if t.Side == goqonto.TransactionSideDebit {
+			debits.Count++
+			debitCents += t.AmountCents
+		} else {
+			credits.Count++
+			creditCents += t.AmountCents
+		}
+	}
+
+	if len(transactions) == 0 {
+		return
+	}
+
+	credits.Sum = formatCents(creditCents)
+	debits.Sum = formatCents(debitCents)
+
+	net := creditCents - debitCents
+	indicator := CreditDebitCredit
+	if net < 0 {
+		indicator = CreditDebitDebit
+		net = -net
+	}
+
+	stmt.Summary = &TransactionsSummary{
+		Total: NumberAndSum{
+			Count:       credits.Count + debits.Count,
+			Sum:         formatCents(creditCents + debitCents),
+			NetAmount:   formatCents(net),
+			CreditDebit: indicator,
+		},
+		Credits: credits,
+		Debits:  debits,
+	}
+}
+
+func (g *generator) entry(t goqonto.Transaction) Entry {
+	debit := t.Side == goqonto.TransactionSideDebit
+
+	e := Entry{
+		Amount:              g.amount(t.AmountCents),
+		CreditDebit:         CreditDebitCredit,
+		Status:              EntryStatusBooked,
+		ServicerReference:   servicerReference(t),
+		BankTransactionCode: bankTransactionCode(t),
+		AdditionalInfo:      t.TransactionID,
+	}
+
+	if debit {
+		e.CreditDebit = CreditDebitDebit
+	}
+
+	if t.Status == goqonto.TransactionStatusCompleted {
+		e.BookingDate = &DateAndDateTime{DateTime: t.SettledAt.In(g.loc).Format(time.RFC3339)}
+	} else {
+		e.Status = EntryStatusPending
+	}
+
+	if !t.EmittedAt.IsZero() {
+		e.ValueDate = &DateAndDateTime{Date: t.EmittedAt.In(g.loc).Format(dateLayout)}
+	}
+
+	details := TransactionDetails{ServicerReference: e.ServicerReference}
+
+	if t.Label != "" {
+		party := &Party{Name: truncate(t.Label, max140)}
+		if debit {
+			details.RelatedParties = &RelatedParties{Creditor: party}
+		} else {
+			details.RelatedParties = &RelatedParties{Debtor: party}
+		}
+	}
+
+	if t.Reference != "" {
+		details.Remittance = &Remittance{Unstructured: splitText(t.Reference, max140)}
+	}
+
+	e.Details = &EntryDetails{Transactions: []TransactionDetails{details}}
+
+	return e
+}
+
+// bankTransactionCodes ISO bank transaction codes (domain, family, sub family) by operation type and side
+var bankTransactionCodes = map[string][3]string{
+	"transfer/debit":      {"PMNT", "ICDT", "ESCT"},
+	"transfer/credit":     {"PMNT", "RCDT", "ESCT"},
+	"income/credit":       {"PMNT", "RCDT", "ESCT"},
+	"swift_income/credit": {"PMNT", "RCDT", "XBCT"},
+	"card/debit":          {"PMNT", "CCRD", "POSD"},
+	"card/credit":         {"PMNT", "CCRD", "RIMP"},
+	"direct_debit/debit":  {"PMNT", "IDDT", "ESDD"},
+	"cheque/debit":        {"PMNT", "ICHQ", "CCHQ"},
+	"cheque/credit":       {"PMNT", "RCHQ", "BCHQ"},
+	"qonto_fee/debit":     {"ACMT", "MDOP", "CHRG"},
+}
+
+func bankTransactionCode(t goqonto.Transaction) BankTransactionCode {
+	code := BankTransactionCode{
+		Proprietary: &Proprietary{
+			Code:   strings.ToUpper(t.OperationType),
+			Issuer: proprietaryCodeIssuer,
+		},
+	}
+
+	if c, ok := bankTransactionCodes[t.OperationType+"/"+t.Side]; ok {
+		code.Domain = &Domain{Code: c[0], FamilyCode: c[1], SubFamilyCode: c[2]}
+	}
+
+	return code
+}
+
+// servicerReference returns the Qonto ID of the transaction, without dashes to fit in 35 characters
+func servicerReference(t goqonto.Transaction) string {
+	return truncate(strings.Replace(t.ID, "-", "", -1), max35)
+}
+
+func entryDate(t goqonto.Transaction) time.Time {
+	if t.Status == goqonto.TransactionStatusCompleted {
+		return t.SettledAt
+	}
+	return t.EmittedAt
+}
+
+// within reports whether t is in [from, to)
+func within(t, from, to time.Time) bool {
+	return !t.IsZero() && !t.Before(from) && t.Before(to)
+}
+
+// between reports whether t is in [from, to]
+func between(t, from, to time.Time) bool {
+	return !t.IsZero() && !t.Before(from) && !t.After(to)
+}
+
+func startOfDay(t time.Time, loc *time.Location) time.Time {
+	y, m, d := t.In(loc).Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, loc)
+}
+
+func formatCents(cents int) string {
+	s := strconv.Itoa(cents)
+	if len(s) < 3 {
+		s = strings.Repeat("0", 3-len(s)) + s
+	}
+	return s[:len(s)-2] + "." + s[len(s)-2:]
+}
+
+// truncate shortens s to at most n runes
+func truncate(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n])
+}
+
+// splitText splits s in chunks of at most n runes
+func splitText(s string, n int) []string {
+	var chunks []string
+	r := []rune(s)
+	for len(r) > n {
+		chunks = append(chunks, string(r[:n]))
+		r = r[n:]
+	}
+	return append(chunks, string(r))
+}
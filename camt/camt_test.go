@@ -0,0 +1,307 @@
+package camt
+
+import (
+	"bytes"
+	"flag"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pixelfactoryio/goqonto/v2"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+func testGolden(t *testing.T, name string, got []byte) {
+	t.Helper()
+
+	path := filepath.Join("testdata", name)
+	if *update {
+		if err := ioutil.WriteFile(path, got, 0644); err != nil {
+			t.Fatalf("Unable to update golden file %s: %v", path, err)
+		}
+	}
+
+	want, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Unable to read golden file %s: %v", path, err)
+	}
+
+	if string(got) != string(want) {
+		t.Errorf("%s \n got %s\n want %s\n", name, got, want)
+	}
+}
+
+func mustParse(value string) time.Time {
+	v, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+var (
+	organization = goqonto.Organization{Slug: "croissant-9134"}
+
+	bankAccount = goqonto.BankAccount{
+		Slug:                   "croissant-bank-account-1",
+		IBAN:                   "FR7616798000010000004321396",
+		BIC:                    "TRZOFR21XXX",
+		Currency:               "EUR",
+		BalanceCents:           100000,
+		AuthorizedBalanceCents: 90000,
+		Name:                   "Main account",
+		UpdatedAt:              mustParse("2021-03-10T12:00:00Z"),
+	}
+
+	transactions = []goqonto.Transaction{
+		{
+			ID:            "6ea8271c-87b1-49d0-a66f-1e29a2fe43ba",
+			TransactionID: "croissant-bank-account-1-transaction-1",
+			AmountCents:   20000,
+			Currency:      "EUR",
+			Side:          goqonto.TransactionSideCredit,
+			OperationType: goqonto.TransactionOperationTypeIncome,
+			Label:         "ACME Corp",
+			Reference:     "Invoice 2021-042 " + strings.Repeat("x", 140),
+			Status:        goqonto.TransactionStatusCompleted,
+			EmittedAt:     mustParse("2021-03-08T09:00:00Z"),
+			SettledAt:     mustParse("2021-03-08T10:00:00Z"),
+		},
+		{
+			ID:            "7fb9382d-98c2-4ae1-b770-2f3ab3ff54cb",
+			TransactionID: "croissant-bank-account-1-transaction-2",
+			AmountCents:   5000,
+			Currency:      "EUR",
+			Side:          goqonto.TransactionSideDebit,
+			OperationType: goqonto.TransactionOperationTypeTransfer,
+			Label:         "Landlord",
+			Reference:     "Rent March",
+			Status:        goqonto.TransactionStatusCompleted,
+			EmittedAt:     mustParse("2021-03-09T23:30:00Z"),
+			SettledAt:     mustParse("2021-03-10T08:00:00Z"),
+		},
+		{
+			ID:            "80ca493e-a9d3-4bf2-8881-3a4bc4006ddc",
+			TransactionID: "croissant-bank-account-1-transaction-3",
+			AmountCents:   10000,
+			Currency:      "EUR",
+			Side:          goqonto.TransactionSideDebit,
+			OperationType: goqonto.TransactionOperationTypeCard,
+			Label:         "Hotel",
+			Status:        goqonto.TransactionStatusPending,
+			EmittedAt:     mustParse("2021-03-10T09:00:00Z"),
+		},
+		{
+			ID:            "91db5a4f-bae4-4c03-9992-4b5cd5117eed",
+			TransactionID: "croissant-bank-account-1-transaction-4",
+			AmountCents:   3000,
+			Currency:      "EUR",
+			Side:          goqonto.TransactionSideDebit,
+			OperationType: goqonto.TransactionOperationTypeCard,
+			Label:         "Taxi",
+			Status:        goqonto.TransactionStatusReversed,
+			EmittedAt:     mustParse("2021-03-08T12:00:00Z"),
+			UpdatedAt:     mustParse("2021-03-09T12:00:00Z"),
+		},
+		{
+			ID:            "a2ec6b50-cbf5-4d14-aaa3-5c6de6228ffe",
+			TransactionID: "croissant-bank-account-1-transaction-5",
+			AmountCents:   3000,
+			Currency:      "EUR",
+			Side:          goqonto.TransactionSideDebit,
+			OperationType: goqonto.TransactionOperationTypeCard,
+			Label:         "Restaurant",
+			Status:        goqonto.TransactionStatusCompleted,
+			EmittedAt:     mustParse("2021-03-09T13:00:00Z"),
+			SettledAt:     mustParse("2021-03-09T14:00:00Z"),
+		},
+	}
+
+	statementOptions = &Options{
+		MessageID: "MSG-20210310",
+		CreatedAt: mustParse("2021-03-10T12:30:00Z"),
+		From:      mustParse("2021-03-08T00:00:00Z"),
+		To:        mustParse("2021-03-09T00:00:00Z"),
+		OwnerName: "Croissant SAS",
+	}
+
+	reportOptions = &Options{
+		MessageID: "MSG-20210310-RPT",
+		CreatedAt: mustParse("2021-03-10T12:30:00Z"),
+		From:      mustParse("2021-03-10T00:00:00Z"),
+		To:        mustParse("2021-03-10T12:00:00Z"),
+	}
+)
+
+func TestNewStatement(t *testing.T) {
+	doc, err := NewStatement(organization, bankAccount, transactions, statementOptions)
+	if err != nil {
+		t.Fatalf("NewStatement returned error: %v", err)
+	}
+
+	stmt := doc.Statement.Statements[0]
+
+	wantBalances := []Balance{
+		{Type: BalanceOpeningBooked, Amount: Amount{"880.00", "EUR"}, CreditDebit: CreditDebitCredit, Date: "2021-03-07"},
+		{Type: BalanceClosingBooked, Amount: Amount{"1050.00", "EUR"}, CreditDebit: CreditDebitCredit, Date: "2021-03-09"},
+		{Type: BalanceClosingAvailable, Amount: Amount{"1000.00", "EUR"}, CreditDebit: CreditDebitCredit, Date: "2021-03-09"},
+	}
+	if !reflect.DeepEqual(stmt.Balances, wantBalances) {
+		t.Errorf("Statement balances \n got %v\n want %v\n", stmt.Balances, wantBalances)
+	}
+
+	var got []string
+	for _, e := range stmt.Entries {
+		got = append(got, e.ServicerReference+" "+e.CreditDebit+" "+e.Amount.Value+" "+e.BookingDate.DateTime)
+	}
+	want := []string{
+		"6ea8271c87b149d0a66f1e29a2fe43ba CRDT 200.00 2021-03-08T10:00:00Z",
+		"a2ec6b50cbf54d14aaa35c6de6228ffe DBIT 30.00 2021-03-09T14:00:00Z",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Statement entries \n got %v\n want %v\n", got, want)
+	}
+
+	wantSummary := &TransactionsSummary{
+		Total:   NumberAndSum{Count: 2, Sum: "230.00", NetAmount: "170.00", CreditDebit: CreditDebitCredit},
+		Credits: NumberAndSum{Count: 1, Sum: "200.00"},
+		Debits:  NumberAndSum{Count: 1, Sum: "30.00"},
+	}
+	if !reflect.DeepEqual(stmt.Summary, wantSummary) {
+		t.Errorf("Statement summary \n got %v\n want %v\n", stmt.Summary, wantSummary)
+	}
+
+	remittance := stmt.Entries[0].Details.Transactions[0].Remittance.Unstructured
+	if len(remittance) != 2 || len([]rune(remittance[0])) != max140 {
+		t.Errorf("Statement remittance information got %v, want 2 lines", remittance)
+	}
+}
+
+func TestNewStatement_schema(t *testing.T) {
+	doc, err := NewStatement(organization, bankAccount, transactions, statementOptions)
+	if err != nil {
+		t.Fatalf("NewStatement returned error: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := doc.Write(&buf); err != nil {
+		t.Fatalf("Write returned error: %v", err)
+	}
+
+	testSchema(t, buf.Bytes(), camt053, NamespaceCamt053)
+	testGolden(t, "camt053.xml.golden", buf.Bytes())
+}
+
+func TestNewStatement_empty(t *testing.T) {
+	opt := &Options{
+		CreatedAt: mustParse("2021-03-10T12:30:00Z"),
+		From:      mustParse("2021-03-01T00:00:00Z"),
+		To:        mustParse("2021-03-01T00:00:00Z"),
+	}
+
+	doc, err := NewStatement(organization, bankAccount, nil, opt)
+	if err != nil {
+		t.Fatalf("NewStatement returned error: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := doc.Write(&buf); err != nil {
+		t.Fatalf("Write returned error: %v", err)
+	}
+
+	testSchema(t, buf.Bytes(), camt053, NamespaceCamt053)
+
+	if got, want := doc.Statement.GroupHeader.MessageID, "croissant-9134-20210310123000"; got != want {
+		t.Errorf("Statement message ID got %s, want %s", got, want)
+	}
+
+	if stmt := doc.Statement.Statements[0]; stmt.Summary != nil || len(stmt.Entries) != 0 {
+		t.Errorf("Statement got entries %v and summary %v, want none", stmt.Entries, stmt.Summary)
+	}
+}
+
+func TestNewReport(t *testing.T) {
+	doc, err := NewReport(organization, bankAccount, transactions, reportOptions)
+	if err != nil {
+		t.Fatalf("NewReport returned error: %v", err)
+	}
+
+	rpt := doc.Report.Reports[0]
+
+	wantBalances := []Balance{
+		{Type: BalanceInterimBooked, Amount: Amount{"1000.00", "EUR"}, CreditDebit: CreditDebitCredit, Date: "2021-03-10"},
+		{Type: BalanceInterimAvailable, Amount: Amount{"900.00", "EUR"}, CreditDebit: CreditDebitCredit, Date: "2021-03-10"},
+	}
+	if !reflect.DeepEqual(rpt.Balances, wantBalances) {
+		t.Errorf("Report balances \n got %v\n want %v\n", rpt.Balances, wantBalances)
+	}
+
+	var got []string
+	for _, e := range rpt.Entries {
+		got = append(got, e.AdditionalInfo+" "+e.Status+" "+e.ValueDate.Date)
+	}
+	want := []string{
+		"croissant-bank-account-1-transaction-2 BOOK 2021-03-09",
+		"croissant-bank-account-1-transaction-3 PDNG 2021-03-10",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Report entries \n got %v\n want %v\n", got, want)
+	}
+
+	if rpt.Entries[1].BookingDate != nil {
+		t.Errorf("Pending entry booking date got %v, want none", rpt.Entries[1].BookingDate)
+	}
+
+	if got := rpt.Summary.Total; got.NetAmount != "150.00" || got.CreditDebit != CreditDebitDebit {
+		t.Errorf("Report net amount got %s %s, want 150.00 DBIT", got.NetAmount, got.CreditDebit)
+	}
+}
+
+func TestNewReport_schema(t *testing.T) {
+	doc, err := NewReport(organization, bankAccount, transactions, reportOptions)
+	if err != nil {
+		t.Fatalf("NewReport returned error: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := doc.Write(&buf); err != nil {
+		t.Fatalf("Write returned error: %v", err)
+	}
+
+	testSchema(t, buf.Bytes(), camt052, NamespaceCamt052)
+	testGolden(t, "camt052.xml.golden", buf.Bytes())
+}
+
+func TestNew_errors(t *testing.T) {
+	if _, err := NewStatement(organization, goqonto.BankAccount{}, nil, nil); err != ErrNoBankAccountCurrency {
+		t.Errorf("NewStatement error got %v, want %v", err, ErrNoBankAccountCurrency)
+	}
+
+	opt := &Options{
+		From: mustParse("2021-03-10T00:00:00Z"),
+		To:   mustParse("2021-03-09T00:00:00Z"),
+	}
+	if _, err := NewReport(organization, bankAccount, nil, opt); err == nil {
+		t.Errorf("Expected error to be returned")
+	}
+}
+
+func TestBankTransactionCode(t *testing.T) {
+	got := bankTransactionCode(goqonto.Transaction{OperationType: "card", Side: goqonto.TransactionSideDebit})
+	want := BankTransactionCode{
+		Domain:      &Domain{Code: "PMNT", FamilyCode: "CCRD", SubFamilyCode: "POSD"},
+		Proprietary: &Proprietary{Code: "CARD", Issuer: proprietaryCodeIssuer},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("bankTransactionCode \n got %v\n want %v\n", got, want)
+	}
+
+	got = bankTransactionCode(goqonto.Transaction{OperationType: "recall", Side: goqonto.TransactionSideCredit})
+	if got.Domain != nil {
+		t.Errorf("bankTransactionCode domain got %v, want none", got.Domain)
+	}
+}
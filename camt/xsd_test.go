@@ -0,0 +1,386 @@
+package camt
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// The types below describe the subset of the camt.052.001.02 and camt.053.001.02 XSD used by the
+// generator: element order, cardinality and simple types patterns. Elements the generator never
+// writes are listed so that ordering is checked against the full sequences.
+
+const unbounded = -1
+
+type xsdElement struct {
+	name     string
+	min, max int
+	typ      *xsdType
+	pattern  *regexp.Regexp
+	attrs    map[string]*regexp.Regexp
+}
+
+type xsdType struct {
+	sequence []xsdElement
+	choice   bool
+}
+
+var (
+	max35Text      = regexp.MustCompile(`^.{1,35}$`)
+	max70Text      = regexp.MustCompile(`^.{1,70}$`)
+	max140Text     = regexp.MustCompile(`^.{1,140}$`)
+	max500Text     = regexp.MustCompile(`^.{1,500}$`)
+	number         = regexp.MustCompile(`^[0-9]{1,18}$`)
+	decimalNumber  = regexp.MustCompile(`^[0-9]{1,18}(\.[0-9]{1,17})?$`)
+	amount         = regexp.MustCompile(`^[0-9]{1,18}(\.[0-9]{1,5})?$`)
+	currencyCode   = regexp.MustCompile(`^[A-Z]{3}$`)
+	isoDate        = regexp.MustCompile(`^[0-9]{4}-[0-9]{2}-[0-9]{2}$`)
+	isoDateTime    = regexp.MustCompile(`^[0-9]{4}-[0-9]{2}-[0-9]{2}T[0-9:]{8}(\.[0-9]+)?(Z|[+-][0-9]{2}:[0-9]{2})?$`)
+	iban           = regexp.MustCompile(`^[A-Z]{2}[0-9]{2}[a-zA-Z0-9]{1,30}$`)
+	bic            = regexp.MustCompile(`^[A-Z]{6}[A-Z2-9][A-NP-Z0-9]([A-Z0-9]{3})?$`)
+	creditDebit    = regexp.MustCompile(`^(CRDT|DBIT)$`)
+	entryStatus    = regexp.MustCompile(`^(BOOK|PDNG|INFO)$`)
+	balanceType    = regexp.MustCompile(`^(OPBD|CLBD|CLAV|ITBD|ITAV|PRCD|FWAV|INFO|OPAV|XPCD)$`)
+	externalCode   = regexp.MustCompile(`^.{1,4}$`)
+	anything       = regexp.MustCompile(`.*`)
+	currencyAttrib = map[string]*regexp.Regexp{"Ccy": currencyCode}
+)
+
+func el(name string, min, max int, typ *xsdType) xsdElement {
+	return xsdElement{name: name, min: min, max: max, typ: typ}
+}
+
+func simple(name string, min, max int, pattern *regexp.Regexp) xsdElement {
+	return xsdElement{name: name, min: min, max: max, pattern: pattern}
+}
+
+func amt(name string, min int) xsdElement {
+	return xsdElement{name: name, min: min, max: 1, pattern: amount, attrs: currencyAttrib}
+}
+
+// opaque is an element the generator never writes, its content is not checked
+func opaque(name string, max int) xsdElement {
+	return xsdElement{name: name, min: 0, max: max, pattern: anything}
+}
+
+func seq(elements ...xsdElement) *xsdType {
+	return &xsdType{sequence: elements}
+}
+
+func choice(elements ...xsdElement) *xsdType {
+	return &xsdType{sequence: elements, choice: true}
+}
+
+var (
+	groupHeaderType = seq(
+		simple("MsgId", 1, 1, max35Text),
+		simple("CreDtTm", 1, 1, isoDateTime),
+		opaque("MsgRcpt", 1),
+		opaque("MsgPgntn", 1),
+		opaque("AddtlInf", 1),
+	)
+
+	partyType = seq(
+		simple("Nm", 0, 1, max140Text),
+		opaque("PstlAdr", 1),
+		opaque("Id", 1),
+		opaque("CtryOfRes", 1),
+		opaque("CtctDtls", 1),
+	)
+
+	cashAccountType = seq(
+		el("Id", 1, 1, choice(simple("IBAN", 1, 1, iban), opaque("Othr", 1))),
+		opaque("Tp", 1),
+		simple("Ccy", 0, 1, currencyCode),
+		simple("Nm", 0, 1, max70Text),
+		el("Ownr", 0, 1, partyType),
+		el("Svcr", 0, 1, seq(
+			el("FinInstnId", 1, 1, seq(
+				simple("BIC", 0, 1, bic),
+				opaque("ClrSysMmbId", 1),
+				opaque("Nm", 1),
+				opaque("PstlAdr", 1),
+				opaque("Othr", 1),
+			)),
+			opaque("BrnchId", 1),
+		)),
+	)
+
+	dateAndDateTimeType = choice(
+		simple("Dt", 1, 1, isoDate),
+		simple("DtTm", 1, 1, isoDateTime),
+	)
+
+	cashBalanceType = seq(
+		el("Tp", 1, 1, seq(
+			el("CdOrPrtry", 1, 1, choice(simple("Cd", 1, 1, balanceType), opaque("Prtry", 1))),
+			opaque("SubTp", 1),
+		)),
+		opaque("CdtLine", unbounded),
+		amt("Amt", 1),
+		simple("CdtDbtInd", 1, 1, creditDebit),
+		el("Dt", 1, 1, dateAndDateTimeType),
+		opaque("Avlbty", unbounded),
+	)
+
+	numberAndSumType = seq(
+		simple("NbOfNtries", 0, 1, number),
+		simple("Sum", 0, 1, decimalNumber),
+	)
+
+	transactionsSummaryType = seq(
+		el("TtlNtries", 0, 1, seq(
+			simple("NbOfNtries", 0, 1, number),
+			simple("Sum", 0, 1, decimalNumber),
+			simple("TtlNetNtryAmt", 0, 1, decimalNumber),
+			simple("CdtDbtInd", 0, 1, creditDebit),
+		)),
+		el("TtlCdtNtries", 0, 1, numberAndSumType),
+		el("TtlDbtNtries", 0, 1, numberAndSumType),
+		opaque("TtlNtriesPerBkTxCd", unbounded),
+	)
+
+	bankTransactionCodeType = seq(
+		el("Domn", 0, 1, seq(
+			simple("Cd", 1, 1, externalCode),
+			el("Fmly", 1, 1, seq(
+				simple("Cd", 1, 1, externalCode),
+				simple("SubFmlyCd", 1, 1, externalCode),
+			)),
+		)),
+		el("Prtry", 0, 1, seq(
+			simple("Cd", 1, 1, max35Text),
+			simple("Issr", 0, 1, max35Text),
+		)),
+	)
+
+	transactionDetailsType = seq(
+		el("Refs", 0, 1, seq(
+			opaque("MsgId", 1),
+			simple("AcctSvcrRef", 0, 1, max35Text),
+			opaque("PmtInfId", 1),
+			opaque("InstrId", 1),
+			opaque("EndToEndId", 1),
+			opaque("TxId", 1),
+			opaque("MndtId", 1),
+			opaque("ChqNb", 1),
+			opaque("ClrSysRef", 1),
+			opaque("Prtry", 1),
+		)),
+		opaque("AmtDtls", 1),
+		opaque("Avlbty", unbounded),
+		opaque("BkTxCd", 1),
+		opaque("Chrgs", unbounded),
+		opaque("Intrst", unbounded),
+		el("RltdPties", 0, 1, seq(
+			opaque("InitgPty", 1),
+			el("Dbtr", 0, 1, partyType),
+			opaque("DbtrAcct", 1),
+			opaque("UltmtDbtr", 1),
+			el("Cdtr", 0, 1, partyType),
+			opaque("CdtrAcct", 1),
+			opaque("UltmtCdtr", 1),
+			opaque("TradgPty", 1),
+			opaque("Prtry", unbounded),
+		)),
+		opaque("RltdAgts", 1),
+		opaque("Purp", 1),
+		opaque("RltdRmtInf", 10),
+		el("RmtInf", 0, 1, seq(
+			simple("Ustrd", 0, unbounded, max140Text),
+			opaque("Strd", unbounded),
+		)),
+	)
+
+	entryType = seq(
+		opaque("NtryRef", 1),
+		amt("Amt", 1),
+		simple("CdtDbtInd", 1, 1, creditDebit),
+		opaque("RvslInd", 1),
+		simple("Sts", 1, 1, entryStatus),
+		el("BookgDt", 0, 1, dateAndDateTimeType),
+		el("ValDt", 0, 1, dateAndDateTimeType),
+		simple("AcctSvcrRef", 0, 1, max35Text),
+		opaque("Avlbty", unbounded),
+		el("BkTxCd", 1, 1, bankTransactionCodeType),
+		opaque("ComssnWvrInd", 1),
+		opaque("AddtlInfInd", 1),
+		opaque("AmtDtls", 1),
+		opaque("Chrgs", unbounded),
+		opaque("TechInptChanl", 1),
+		opaque("Intrst", unbounded),
+		el("NtryDtls", 0, unbounded, seq(
+			opaque("Btch", 1),
+			el("TxDtls", 0, unbounded, transactionDetailsType),
+		)),
+		simple("AddtlNtryInf", 0, 1, max500Text),
+	)
+
+	periodType = seq(
+		simple("FrDtTm", 1, 1, isoDateTime),
+		simple("ToDtTm", 1, 1, isoDateTime),
+	)
+
+	accountStatementType = func(minBalances int, additionalInfo string) *xsdType {
+		return seq(
+			simple("Id", 1, 1, max35Text),
+			simple("ElctrncSeqNb", 0, 1, number),
+			opaque("LglSeqNb", 1),
+			simple("CreDtTm", 1, 1, isoDateTime),
+			el("FrToDt", 0, 1, periodType),
+			opaque("CpyDplctInd", 1),
+			opaque("RptgSrc", 1),
+			el("Acct", 1, 1, cashAccountType),
+			opaque("RltdAcct", 1),
+			opaque("Intrst", unbounded),
+			el("Bal", minBalances, unbounded, cashBalanceType),
+			el("TxsSummry", 0, 1, transactionsSummaryType),
+			el("Ntry", 0, unbounded, entryType),
+			opaque(additionalInfo, 1),
+		)
+	}
+
+	camt053 = el("Document", 1, 1, seq(
+		el("BkToCstmrStmt", 1, 1, seq(
+			el("GrpHdr", 1, 1, groupHeaderType),
+			el("Stmt", 1, unbounded, accountStatementType(1, "AddtlStmtInf")),
+		)),
+	))
+
+	camt052 = el("Document", 1, 1, seq(
+		el("BkToCstmrAcctRpt", 1, 1, seq(
+			el("GrpHdr", 1, 1, groupHeaderType),
+			el("Rpt", 1, unbounded, accountStatementType(0, "AddtlRptInf")),
+		)),
+	))
+)
+
+// xmlNode generic XML element tree
+type xmlNode struct {
+	name     string
+	attrs    map[string]string
+	children []*xmlNode
+	text     string
+}
+
+func parseXML(t *testing.T, data []byte) *xmlNode {
+	t.Helper()
+
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	var stack []*xmlNode
+	var root *xmlNode
+
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			break
+		}
+
+		switch tok := tok.(type) {
+		case xml.StartElement:
+			n := &xmlNode{name: tok.Name.Local, attrs: map[string]string{}}
+			for _, a := range tok.Attr {
+				n.attrs[a.Name.Local] = a.Value
+			}
+			if len(stack) > 0 {
+				parent := stack[len(stack)-1]
+				parent.children = append(parent.children, n)
+			} else {
+				root = n
+			}
+			stack = append(stack, n)
+		case xml.EndElement:
+			stack = stack[:len(stack)-1]
+		case xml.CharData:
+			if len(stack) > 0 {
+				stack[len(stack)-1].text += string(tok)
+			}
+		}
+	}
+
+	if root == nil {
+		t.Fatalf("Unable to parse XML document")
+	}
+
+	return root
+}
+
+// validate checks n against the schema element and returns the list of violations
+func validate(n *xmlNode, schema xsdElement, path string) []string {
+	path = path + "/" + n.name
+
+	if schema.typ == nil {
+		var errs []string
+		if len(n.children) > 0 {
+			errs = append(errs, fmt.Sprintf("%s: unexpected child elements", path))
+		}
+		if !schema.pattern.MatchString(strings.TrimSpace(n.text)) {
+			errs = append(errs, fmt.Sprintf("%s: value %q does not match %s", path, n.text, schema.pattern))
+		}
+		for name, pattern := range schema.attrs {
+			if !pattern.MatchString(n.attrs[name]) {
+				errs = append(errs, fmt.Sprintf("%s: attribute %s %q does not match %s", path, name, n.attrs[name], pattern))
+			}
+		}
+		return errs
+	}
+
+	if schema.typ.choice {
+		if len(n.children) != 1 {
+			return []string{fmt.Sprintf("%s: choice expects exactly one element, got %d", path, len(n.children))}
+		}
+		for _, c := range schema.typ.sequence {
+			if c.name == n.children[0].name {
+				return validate(n.children[0], c, path)
+			}
+		}
+		return []string{fmt.Sprintf("%s: unexpected choice element %s", path, n.children[0].name)}
+	}
+
+	var errs []string
+	i := 0
+	for _, c := range schema.typ.sequence {
+		count := 0
+		for i < len(n.children) && n.children[i].name == c.name && (c.max == unbounded || count < c.max) {
+			errs = append(errs, validate(n.children[i], c, path)...)
+			count++
+			i++
+		}
+		if count < c.min {
+			errs = append(errs, fmt.Sprintf("%s: missing element %s", path, c.name))
+		}
+	}
+
+	for ; i < len(n.children); i++ {
+		errs = append(errs, fmt.Sprintf("%s: unexpected or misplaced element %s", path, n.children[i].name))
+	}
+
+	return errs
+}
+
+func testSchema(t *testing.T, data []byte, schema xsdElement, namespace string) {
+	t.Helper()
+
+	root := parseXML(t, data)
+	if got := root.attrs["xmlns"]; got != namespace {
+		t.Errorf("Document namespace got %s, want %s", got, namespace)
+	}
+
+	for _, err := range validate(root, schema, "") {
+		t.Error(err)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	invalid := []byte(`<Document><BkToCstmrStmt>
+		<Stmt><Id>1</Id></Stmt>
+		<GrpHdr><MsgId>1</MsgId><CreDtTm>today</CreDtTm></GrpHdr>
+	</BkToCstmrStmt></Document>`)
+
+	if errs := validate(parseXML(t, invalid), camt053, ""); len(errs) == 0 {
+		t.Errorf("Expected schema violations to be reported")
+	}
+}
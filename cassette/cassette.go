@@ -0,0 +1,292 @@
+// Package cassette records the HTTP interactions of a client with the Qonto API to fixture files and
+// replays them, for deterministic tests
+package cassette
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// Mode of a Recorder
+type Mode int
+
+// Recorder modes
+const (
+	// ModeReplay serves the recorded interactions and fails on unmatched requests.
+	ModeReplay Mode = iota
+
+	// ModeRecord sends the requests to the real transport and records the interactions.
+	ModeRecord
+)
+
+// Redacted replaces the values of redacted headers
+const Redacted = "REDACTED"
+
+// ibanPattern matches IBANs, which are redacted from URLs and bodies
+var ibanPattern = regexp.MustCompile(`\b[A-Z]{2}[0-9]{2}[A-Z0-9]{11,30}\b`)
+
+// Request recorded request
+type Request struct {
+	Method string      `json:"method"`
+	URL    string      `json:"url"`
+	Header http.Header `json:"header,omitempty"`
+	Body   string      `json:"body,omitempty"`
+}
+
+// Response recorded response
+type Response struct {
+	StatusCode int         `json:"status_code"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       string      `json:"body,omitempty"`
+}
+
+// Interaction recorded request and response
+type Interaction struct {
+	Request  Request  `json:"request"`
+	Response Response `json:"response"`
+}
+
+// Cassette fixture file content
+type Cassette struct {
+	Interactions []Interaction `json:"interactions"`
+}
+
+// UnmatchedError is returned in replay mode for requests matching no recorded interaction
+type UnmatchedError struct {
+	Method string
+	URL    string
+	Body   string
+}
+
+func (e *UnmatchedError) Error() string {
+	return fmt.Sprintf("cassette: no recorded interaction for %s %s %s", e.Method, e.URL, e.Body)
+}
+
+// Recorder http.RoundTripper recording or replaying interactions
+type Recorder struct {
+	path      string
+	mode      Mode
+	transport http.RoundTripper
+
+	// Headers whose values are redacted, Authorization by default.
+	RedactHeaders []string
+
+	mu       sync.Mutex
+	cassette Cassette
+	used     []bool
+}
+
+// New returns a Recorder of the cassette at path. In replay mode the cassette is loaded and transport
+// is not used. In record mode interactions are sent through transport, http.DefaultTransport if nil,
+// and saved by Stop.
+func New(path string, mode Mode, transport http.RoundTripper) (*Recorder, error) {
+	r := &Recorder{
+		path:          path,
+		mode:          mode,
+		transport:     transport,
+		RedactHeaders: []string{"Authorization"},
+	}
+
+	if r.transport == nil {
+		r.transport = http.DefaultTransport
+	}
+
+	if mode == ModeReplay {
+		b, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(b, &r.cassette); err != nil {
+			return nil, fmt.Errorf("cassette %s: %v", path, err)
+		}
+		r.used = make([]bool, len(r.cassette.Interactions))
+	}
+
+	return r, nil
+}
+
+// Stop saves the recorded interactions in record mode
+func (r *Recorder) Stop() error {
+	if r.mode != ModeRecord {
+		return nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	b, err := json.MarshalIndent(r.cassette, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(r.path), 0755); err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(r.path, append(b, '\n'), 0644)
+}
+
+// RoundTrip records or replays the interaction of req
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, err := readBody(req)
+	if err != nil {
+		return nil, err
+	}
+
+	recorded := Request{
+		Method: req.Method,
+		URL:    redact(requestURL(req.URL)),
+		Header: r.redactHeader(req.Header),
+		Body:   redact(body),
+	}
+
+	if r.mode == ModeReplay {
+		return r.replay(req, recorded)
+	}
+
+	resp, err := r.transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	respBody, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(respBody))
+
+	r.mu.Lock()
+	r.cassette.Interactions = append(r.cassette.Interactions, Interaction{
+		Request: recorded,
+		Response: Response{
+			StatusCode: resp.StatusCode,
+			Header:     r.redactHeader(resp.Header),
+			Body:       redact(string(respBody)),
+		},
+	})
+	r.mu.Unlock()
+
+	return resp, nil
+}
+
+// replay returns the response of the first unused interaction matching the request
+func (r *Recorder) replay(req *http.Request, recorded Request) (*http.Response, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for k, i := range r.cassette.Interactions {
+		if r.used[k] || !match(i.Request, recorded) {
+			continue
+		}
+		r.used[k] = true
+
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", i.Response.StatusCode, http.StatusText(i.Response.StatusCode)),
+			StatusCode:    i.Response.StatusCode,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        i.Response.Header.Clone(),
+			Body:          ioutil.NopCloser(strings.NewReader(i.Response.Body)),
+			ContentLength: int64(len(i.Response.Body)),
+			Request:       req,
+		}, nil
+	}
+
+	return nil, &UnmatchedError{Method: recorded.Method, URL: recorded.URL, Body: recorded.Body}
+}
+
+// Unused returns the recorded interactions not replayed yet
+func (r *Recorder) Unused() []Interaction {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var unused []Interaction
+	for k, i := range r.cassette.Interactions {
+		if !r.used[k] {
+			unused = append(unused, i)
+		}
+	}
+	return unused
+}
+
+// match compares method, path, query and body, JSON bodies being compared semantically
+func match(recorded, req Request) bool {
+	if recorded.Method != req.Method {
+		return false
+	}
+
+	u1, err1 := url.Parse(recorded.URL)
+	u2, err2 := url.Parse(req.URL)
+	if err1 != nil || err2 != nil || u1.Path != u2.Path || u1.Query().Encode() != u2.Query().Encode() {
+		return false
+	}
+
+	return equalBodies(recorded.Body, req.Body)
+}
+
+func equalBodies(a, b string) bool {
+	if a == b {
+		return true
+	}
+
+	var va, vb interface{}
+	if json.Unmarshal([]byte(a), &va) != nil || json.Unmarshal([]byte(b), &vb) != nil {
+		return false
+	}
+
+	ja, _ := json.Marshal(va)
+	jb, _ := json.Marshal(vb)
+	return bytes.Equal(ja, jb)
+}
+
+func (r *Recorder) redactHeader(h http.Header) http.Header {
+	h = h.Clone()
+	for _, name := range r.RedactHeaders {
+		if h.Get(name) != "" {
+			h.Set(name, Redacted)
+		}
+	}
+	return h
+}
+
+// redact replaces IBANs with a stable token derived from their hash, so that a request made with the
+// same IBAN still matches once redacted
+func redact(s string) string {
+	return ibanPattern.ReplaceAllStringFunc(s, func(iban string) string {
+		sum := sha256.Sum256([]byte(iban))
+		return "REDACTED-IBAN-" + strings.ToUpper(hex.EncodeToString(sum[:4]))
+	})
+}
+
+// requestURL returns the path and query of a URL
+func requestURL(u *url.URL) string {
+	return u.RequestURI()
+}
+
+func readBody(req *http.Request) (string, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return "", nil
+	}
+
+	b, err := ioutil.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return "", err
+	}
+
+	req.Body = ioutil.NopCloser(bytes.NewReader(b))
+	return string(b), nil
+}
@@ -0,0 +1,196 @@
+package cassette
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pixelfactoryio/goqonto/v2"
+	"github.com/pixelfactoryio/goqonto/v2/qontotest"
+)
+
+const iban = "FR7616798000010000005663951"
+
+type authTransport struct {
+	transport http.RoundTripper
+}
+
+func (t authTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	r = r.Clone(r.Context())
+	r.Header.Set("Authorization", "croissant:secret")
+	return t.transport.RoundTrip(r)
+}
+
+func newServer() *qontotest.Server {
+	s := qontotest.NewServer()
+	s.AddCredentials("croissant", "secret")
+	s.AddOrganization(goqonto.Organization{
+		Slug:         "croissant",
+		BankAccounts: []goqonto.BankAccount{{ID: "account-1", IBAN: iban, Currency: "EUR", Main: true}},
+	})
+	s.AddTransactions(iban, goqonto.Transaction{
+		ID:          "t1",
+		AmountCents: 1250,
+		Side:        goqonto.TransactionSideDebit,
+		Status:      goqonto.TransactionStatusCompleted,
+		SettledAt:   time.Date(2021, time.March, 1, 10, 0, 0, 0, time.UTC),
+	})
+	return s
+}
+
+func newClient(t *testing.T, rt http.RoundTripper, baseURL string) *goqonto.Client {
+	c, err := goqonto.New(&http.Client{Transport: rt}, goqonto.SetBaseURL(baseURL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func fetch(c *goqonto.Client) (*goqonto.Organization, []goqonto.Transaction, error) {
+	ctx := context.Background()
+
+	org, _, err := c.Organizations.Get(ctx, "croissant")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	tx, _, err := c.Transactions.List(ctx, &goqonto.TransactionsOptions{
+		Slug:   org.Slug,
+		IBAN:   org.BankAccounts[0].IBAN,
+		Status: []string{goqonto.TransactionStatusCompleted},
+	})
+	return org, tx, err
+}
+
+func TestRecorder(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cassette")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "cassette.json")
+
+	s := newServer()
+	defer s.Close()
+
+	rec, err := New(path, ModeRecord, authTransport{http.DefaultTransport})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	org, tx, err := fetch(newClient(t, rec, s.URL))
+	if err != nil {
+		t.Fatalf("recording returned error: %v", err)
+	}
+
+	if err := rec.Stop(); err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, secret := range []string{"croissant:secret", iban} {
+		if strings.Contains(string(b), secret) {
+			t.Errorf("cassette contains %q", secret)
+		}
+	}
+	if !strings.Contains(string(b), Redacted) {
+		t.Errorf("cassette does not redact the Authorization header")
+	}
+
+	rep, err := New(path, ModeReplay, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The server is not needed anymore, only paths are matched.
+	s.Close()
+
+	gotOrg, gotTx, err := fetch(newClient(t, rep, "http://qonto.invalid"))
+	if err != nil {
+		t.Fatalf("replay returned error: %v", err)
+	}
+
+	want := redact(iban)
+	if got := gotOrg.BankAccounts[0].IBAN; got != want {
+		t.Errorf("IBAN \n got %v\n want %v\n", got, want)
+	}
+
+	gotOrg.BankAccounts[0].IBAN = org.BankAccounts[0].IBAN
+	if !reflect.DeepEqual(gotOrg, org) {
+		t.Errorf("Organization \n got %v\n want %v\n", gotOrg, org)
+	}
+	if !reflect.DeepEqual(gotTx, tx) {
+		t.Errorf("Transactions \n got %v\n want %v\n", gotTx, tx)
+	}
+
+	if unused := rep.Unused(); len(unused) != 0 {
+		t.Errorf("Unused \n got %v\n want %v\n", len(unused), 0)
+	}
+}
+
+func TestRecorder_unmatched(t *testing.T) {
+	path := filepath.Join("testdata", "organization.json")
+
+	rec, err := New(path, ModeReplay, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := newClient(t, rec, "http://qonto.invalid")
+
+	if _, _, err := c.Organizations.Get(context.Background(), "croissant"); err != nil {
+		t.Fatalf("Organizations.Get returned error: %v", err)
+	}
+
+	// The interaction is replayed once only.
+	_, _, err = c.Organizations.Get(context.Background(), "croissant")
+
+	var unmatched *UnmatchedError
+	if !errors.As(err, &unmatched) {
+		t.Fatalf("error \n got %v\n want %T\n", err, unmatched)
+	}
+	if unmatched.Method != http.MethodGet || unmatched.URL != "/v2/organizations/croissant" {
+		t.Errorf("UnmatchedError \n got %v %v\n want GET /v2/organizations/croissant\n", unmatched.Method, unmatched.URL)
+	}
+}
+
+func TestMatch(t *testing.T) {
+	recorded := Request{
+		Method: http.MethodGet,
+		URL:    "/v2/transactions?a=1&b=2",
+		Body:   `{"iban":"X","status":["completed"]}`,
+	}
+
+	tests := []struct {
+		name string
+		req  Request
+		want bool
+	}{
+		{"same", recorded, true},
+		{"query order", Request{Method: http.MethodGet, URL: "/v2/transactions?b=2&a=1", Body: recorded.Body}, true},
+		{"JSON formatting", Request{
+			Method: http.MethodGet,
+			URL:    recorded.URL,
+			Body:   `{"status": ["completed"], "iban": "X"}` + "\n",
+		}, true},
+		{"method", Request{Method: http.MethodPost, URL: recorded.URL, Body: recorded.Body}, false},
+		{"path", Request{Method: http.MethodGet, URL: "/v2/labels?a=1&b=2", Body: recorded.Body}, false},
+		{"query", Request{Method: http.MethodGet, URL: "/v2/transactions?a=1", Body: recorded.Body}, false},
+		{"body", Request{Method: http.MethodGet, URL: recorded.URL, Body: `{"iban":"Y"}`}, false},
+	}
+
+	for _, tt := range tests {
+		if got := match(recorded, tt.req); got != tt.want {
+			t.Errorf("match %s \n got %v\n want %v\n", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,296 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/pixelfactoryio/goqonto/v2"
+	"github.com/pixelfactoryio/goqonto/v2/export"
+)
+
+// transactionColumns export columns of transactions in table and CSV outputs
+var transactionColumns = []string{
+	"id", "settled_at", "label", "signed_amount", "currency", "operation_type", "status", "labels",
+}
+
+// stringList flag.Value accumulating comma separated or repeated values
+type stringList []string
+
+func (l *stringList) String() string { return strings.Join(*l, ",") }
+
+func (l *stringList) Set(v string) error {
+	for _, s := range strings.Split(v, ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			*l = append(*l, s)
+		}
+	}
+	return nil
+}
+
+func (env *environment) flagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(env.stderr)
+	return fs
+}
+
+// organization returns the organization of the profile, or the one of the credentials
+func (env *environment) organization(ctx context.Context) (*goqonto.Organization, error) {
+	if env.profile.Organization == "" {
+		org, _, err := env.client.Organizations.Current(ctx)
+		return org, err
+	}
+
+	org, _, err := env.client.Organizations.Get(ctx, env.profile.Organization)
+	return org, err
+}
+
+func orgShow(ctx context.Context, env *environment, args []string) error {
+	if err := env.flagSet("org show").Parse(args); err != nil {
+		return err
+	}
+
+	org, err := env.organization(ctx)
+	if err != nil {
+		return err
+	}
+
+	header := []string{"slug", "name", "iban", "bic", "currency", "balance", "authorized_balance", "status", "main"}
+	var rows [][]string
+	for _, a := range org.BankAccounts {
+		rows = append(rows, []string{
+			a.Slug,
+			a.Name,
+			a.IBAN,
+			a.BIC,
+			a.Currency,
+			export.LocaleDefault.FormatCents(a.BalanceCents),
+			export.LocaleDefault.FormatCents(a.AuthorizedBalanceCents),
+			a.Status,
+			strconv.FormatBool(a.Main),
+		})
+	}
+
+	return env.out.print(org, header, rows)
+}
+
+func txList(ctx context.Context, env *environment, args []string) error {
+	fs := env.flagSet("tx list")
+
+	opt := &goqonto.TransactionsOptions{}
+	var status, operationTypes stringList
+	all := fs.Bool("all", false, "fetch all the pages")
+
+	fs.StringVar(&opt.IBAN, "iban", "", "bank account IBAN, defaults to the main bank account")
+	fs.Var(&status, "status", "statuses: pending, reversed, declined, completed")
+	fs.StringVar(&opt.Side, "side", "", "side: debit or credit")
+	fs.Var(&operationTypes, "operation-type", "operation types: transfer, card, direct_debit, income, ...")
+	fs.StringVar(&opt.UpdatedAtFrom, "updated-from", "", "minimum updated_at (ISO 8601)")
+	fs.StringVar(&opt.UpdatedAtTo, "updated-to", "", "maximum updated_at (ISO 8601)")
+	fs.StringVar(&opt.SettledAtFrom, "settled-from", "", "minimum settled_at (ISO 8601)")
+	fs.StringVar(&opt.SettledAtTo, "settled-to", "", "maximum settled_at (ISO 8601)")
+	fs.StringVar(&opt.SortBy, "sort-by", "", "sort: settled_at:desc, settled_at:asc, updated_at:desc, updated_at:asc")
+	fs.Int64Var(&opt.CurrentPage, "page", 0, "page number")
+	fs.Int64Var(&opt.PerPage, "per-page", 0, "page size")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	opt.Status = status
+	opt.OperationType = operationTypes
+
+	org, err := env.organization(ctx)
+	if err != nil {
+		return err
+	}
+	opt.Slug = org.Slug
+
+	if opt.IBAN == "" {
+		main := org.MainBankAccount()
+		if main == nil {
+			return errors.New("organization has no bank account")
+		}
+		opt.IBAN = main.IBAN
+	}
+
+	var transactions []goqonto.Transaction
+	if *all {
+		it := env.client.Transactions.Iterator(ctx, opt)
+		for it.Next() {
+			transactions = append(transactions, it.Transaction())
+		}
+		if err := it.Err(); err != nil {
+			return err
+		}
+	} else {
+		if transactions, _, err = env.client.Transactions.List(ctx, opt); err != nil {
+			return err
+		}
+	}
+
+	return printTransactions(env, transactions, transactions)
+}
+
+func txGet(ctx context.Context, env *environment, args []string) error {
+	fs := env.flagSet("tx get")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return errors.New("usage: qonto tx get <id>")
+	}
+
+	t, _, err := env.client.Transactions.Get(ctx, fs.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	return printTransactions(env, t, []goqonto.Transaction{*t})
+}
+
+func printTransactions(env *environment, v interface{}, transactions []goqonto.Transaction) error {
+	rows := make([][]string, 0, len(transactions))
+	for k := range transactions {
+		row := make([]string, len(transactionColumns))
+		for i, name := range transactionColumns {
+			row[i] = export.Columns[name].Value(&transactions[k], export.LocaleDefault)
+		}
+		rows = append(rows, row)
+	}
+
+	return env.out.print(v, transactionColumns, rows)
+}
+
+func labelsList(ctx context.Context, env *environment, args []string) error {
+	if err := env.flagSet("labels list").Parse(args); err != nil {
+		return err
+	}
+
+	var labels []goqonto.Label
+	opt := &goqonto.LabelsOptions{CurrentPage: 1}
+	for {
+		page, resp, err := env.client.Labels.List(ctx, opt)
+		if err != nil {
+			return err
+		}
+		labels = append(labels, page...)
+		if resp.Meta == nil || resp.Meta.NextPage == 0 || len(page) == 0 {
+			break
+		}
+		opt.CurrentPage = int64(resp.Meta.NextPage)
+	}
+
+	var rows [][]string
+	for _, l := range labels {
+		rows = append(rows, []string{l.ID, l.Name, l.ParentID})
+	}
+
+	return env.out.print(labels, []string{"id", "name", "parent_id"}, rows)
+}
+
+func membersList(ctx context.Context, env *environment, args []string) error {
+	if err := env.flagSet("members list").Parse(args); err != nil {
+		return err
+	}
+
+	var members []goqonto.Membership
+	opt := &goqonto.MembershipsOptions{CurrentPage: 1}
+	for {
+		page, resp, err := env.client.Memberships.List(ctx, opt)
+		if err != nil {
+			return err
+		}
+		members = append(members, page...)
+		if resp.Meta == nil || resp.Meta.NextPage == 0 || len(page) == 0 {
+			break
+		}
+		opt.CurrentPage = int64(resp.Meta.NextPage)
+	}
+
+	var rows [][]string
+	for _, m := range members {
+		rows = append(rows, []string{m.ID, m.FistName, m.LastName})
+	}
+
+	return env.out.print(members, []string{"id", "first_name", "last_name"}, rows)
+}
+
+func attachmentsDownload(ctx context.Context, env *environment, args []string) error {
+	fs := env.flagSet("attachments download")
+	dir := fs.String("dir", ".", "destination directory")
+	transactionID := fs.String("transaction", "", "download the attachments of this transaction")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	ids := fs.Args()
+	if *transactionID != "" {
+		t, _, err := env.client.Transactions.Get(ctx, *transactionID)
+		if err != nil {
+			return err
+		}
+		ids = append(ids, t.AttachmentIds...)
+	}
+
+	if len(ids) == 0 {
+		return errors.New("usage: qonto attachments download [-dir dir] [-transaction id] [attachment-id...]")
+	}
+
+	var attachments []goqonto.Attachment
+	var rows [][]string
+	for _, id := range ids {
+		a, _, err := env.client.Attachments.Get(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		path := filepath.Join(*dir, a.ID+"-"+filepath.Base(a.FileName))
+		if err := download(ctx, env.download, a.URL, path); err != nil {
+			return fmt.Errorf("attachment %s: %v", id, err)
+		}
+
+		attachments = append(attachments, *a)
+		rows = append(rows, []string{a.ID, a.FileName, a.FileContentType, path})
+	}
+
+	return env.out.print(attachments, []string{"id", "file_name", "content_type", "path"}, rows)
+}
+
+// download writes the content of url to path
+func download(ctx context.Context, client *http.Client, url, path string) error {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := client.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("download failed: %s", resp.Status)
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(f, resp.Body); err != nil {
+		f.Close()
+		return err
+	}
+
+	return f.Close()
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// Profile credentials of an organization
+type Profile struct {
+	Organization string `json:"organization"`
+	Login        string `json:"login"`
+	SecretKey    string `json:"secret_key"`
+
+	// Base URL of the API, defaults to the public Qonto API.
+	BaseURL string `json:"base_url,omitempty"`
+}
+
+// Config configuration file
+type Config struct {
+	DefaultProfile string             `json:"default_profile"`
+	Profiles       map[string]Profile `json:"profiles"`
+}
+
+// defaultConfigPath returns the path of the configuration file in the user configuration directory
+func defaultConfigPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "qonto", "config.json")
+}
+
+// loadProfile returns the profile name of the configuration file at path, overridden by the
+// environment. The default configuration file may be missing, an explicit one may not.
+func loadProfile(path, name string, getenv func(string) string) (Profile, error) {
+	explicit := path != "" || getenv("QONTO_CONFIG") != ""
+	if path == "" {
+		path = getenv("QONTO_CONFIG")
+	}
+	if path == "" {
+		path = defaultConfigPath()
+	}
+	if name == "" {
+		name = getenv("QONTO_PROFILE")
+	}
+
+	var cfg Config
+	if path != "" {
+		b, err := ioutil.ReadFile(path)
+		switch {
+		case err == nil:
+			if err := json.Unmarshal(b, &cfg); err != nil {
+				return Profile{}, fmt.Errorf("config %s: %v", path, err)
+			}
+		case explicit || !os.IsNotExist(err):
+			return Profile{}, err
+		}
+	}
+
+	if name == "" {
+		name = cfg.DefaultProfile
+	}
+
+	var p Profile
+	if name != "" {
+		var ok bool
+		if p, ok = cfg.Profiles[name]; !ok {
+			return Profile{}, fmt.Errorf("profile %q not found in %s", name, path)
+		}
+	}
+
+	for env, field := range map[string]*string{
+		"QONTO_ORG_ID":     &p.Organization,
+		"QONTO_USER_LOGIN": &p.Login,
+		"QONTO_SECRET_KEY": &p.SecretKey,
+		"QONTO_BASE_URL":   &p.BaseURL,
+	} {
+		if v := getenv(env); v != "" {
+			*field = v
+		}
+	}
+
+	return p, nil
+}
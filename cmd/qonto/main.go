@@ -0,0 +1,190 @@
+// Command qonto is a command-line client of the Qonto API.
+//
+// Usage:
+//
+//	qonto [-config file] [-profile name] [-o table|json|csv] <command> <subcommand> [flags] [args]
+//
+// Commands:
+//
+//	org show
+//	tx list [-iban iban] [-status s] [-side s] [-operation-type t] [-updated-from d] [-updated-to d]
+//	        [-settled-from d] [-settled-to d] [-sort-by s] [-page n] [-per-page n] [-all]
+//	tx get <id>
+//	labels list
+//	members list
+//	attachments download [-dir dir] [-transaction id] [attachment-id...]
+//
+// Credentials are read from the profile of a JSON configuration file, by default
+// $XDG_CONFIG_HOME/qonto/config.json:
+//
+//	{
+//	  "default_profile": "croissant",
+//	  "profiles": {
+//	    "croissant": {"organization": "croissant-1234", "login": "croissant-1234", "secret_key": "..."}
+//	  }
+//	}
+//
+// The QONTO_CONFIG, QONTO_PROFILE, QONTO_ORG_ID, QONTO_USER_LOGIN, QONTO_SECRET_KEY and QONTO_BASE_URL
+// environment variables override the configuration file.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/signal"
+	"sort"
+
+	"github.com/pixelfactoryio/goqonto/v2"
+)
+
+// environment state shared by the commands
+type environment struct {
+	client  *goqonto.Client
+	profile Profile
+	out     *output
+	stderr  io.Writer
+
+	// HTTP client used to download attachments from their pre-signed URLs.
+	download *http.Client
+}
+
+// command subcommand of the CLI
+type command struct {
+	usage string
+	run   func(ctx context.Context, env *environment, args []string) error
+}
+
+// commands by "<command> <subcommand>"
+var commands = map[string]command{
+	"org show":             {"show the organization and its bank accounts", orgShow},
+	"tx list":              {"list transactions", txList},
+	"tx get":               {"show a transaction", txGet},
+	"labels list":          {"list labels", labelsList},
+	"members list":         {"list memberships", membersList},
+	"attachments download": {"download attachments", attachmentsDownload},
+}
+
+func main() {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+	go func() {
+		<-interrupt
+		cancel()
+	}()
+
+	code := run(ctx, os.Args[1:], os.Stdout, os.Stderr, os.Getenv)
+	cancel()
+	os.Exit(code)
+}
+
+// run executes the command line args and returns the exit code
+func run(ctx context.Context, args []string, stdout, stderr io.Writer, getenv func(string) string) int {
+	fs := flag.NewFlagSet("qonto", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() { usage(fs) }
+
+	configPath := fs.String("config", "", "configuration file")
+	profileName := fs.String("profile", "", "configuration profile")
+	format := fs.String("o", formatTable, "output format: table, json or csv")
+
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	if fs.NArg() < 2 {
+		usage(fs)
+		return 2
+	}
+
+	cmd, ok := commands[fs.Arg(0)+" "+fs.Arg(1)]
+	if !ok {
+		fmt.Fprintf(stderr, "qonto: unknown command %q\n", fs.Arg(0)+" "+fs.Arg(1))
+		usage(fs)
+		return 2
+	}
+
+	out, err := newOutput(stdout, *format)
+	if err != nil {
+		fmt.Fprintf(stderr, "qonto: %v\n", err)
+		return 2
+	}
+
+	profile, err := loadProfile(*configPath, *profileName, getenv)
+	if err != nil {
+		fmt.Fprintf(stderr, "qonto: %v\n", err)
+		return 1
+	}
+
+	client, err := newClient(profile)
+	if err != nil {
+		fmt.Fprintf(stderr, "qonto: %v\n", err)
+		return 1
+	}
+
+	env := &environment{client: client, profile: profile, out: out, stderr: stderr, download: http.DefaultClient}
+	if err := cmd.run(ctx, env, fs.Args()[2:]); err != nil {
+		if err == flag.ErrHelp {
+			return 2
+		}
+		fmt.Fprintf(stderr, "qonto: %v\n", err)
+		return 1
+	}
+
+	return 0
+}
+
+func usage(fs *flag.FlagSet) {
+	w := fs.Output()
+	fmt.Fprintln(w, "Usage: qonto [flags] <command> <subcommand> [flags] [args]")
+	fmt.Fprintln(w, "\nCommands:")
+
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		fmt.Fprintf(w, "  %-22s %s\n", name, commands[name].usage)
+	}
+
+	fmt.Fprintln(w, "\nFlags:")
+	fs.PrintDefaults()
+}
+
+// authTransport sets the Authorization header of the requests
+type authTransport struct {
+	http.RoundTripper
+	login, secretKey string
+}
+
+// RoundTrip set "Authorization" header
+func (t authTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	r = r.Clone(r.Context())
+	r.Header.Set("Authorization", fmt.Sprintf("%s:%s", t.login, t.secretKey))
+	return t.RoundTripper.RoundTrip(r)
+}
+
+func newClient(p Profile) (*goqonto.Client, error) {
+	if p.Login == "" || p.SecretKey == "" {
+		return nil, fmt.Errorf("missing credentials: set login and secret_key in the profile or " +
+			"QONTO_USER_LOGIN and QONTO_SECRET_KEY")
+	}
+
+	httpClient := &http.Client{
+		Transport: authTransport{http.DefaultTransport, p.Login, p.SecretKey},
+	}
+
+	var opts []goqonto.ClientOpt
+	if p.BaseURL != "" {
+		opts = append(opts, goqonto.SetBaseURL(p.BaseURL))
+	}
+
+	return goqonto.New(httpClient, opts...)
+}
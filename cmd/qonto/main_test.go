@@ -0,0 +1,187 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const (
+	organizationFixture = `{
+		"organization": {
+			"slug": "croissant",
+			"bank_accounts": [
+				{
+					"slug": "croissant-bank-account-1",
+					"iban": "FR7616798000010000005663951",
+					"bic": "TRZOFR21XXX",
+					"currency": "EUR",
+					"balance_cents": 123456,
+					"authorized_balance_cents": 120000,
+					"status": "active",
+					"main": true
+				}
+			]
+		}
+	}`
+
+	transactionsFixture = `{
+		"transactions": [
+			{
+				"id": "t1",
+				"amount_cents": 12600,
+				"side": "debit",
+				"operation_type": "card",
+				"currency": "EUR",
+				"label": "Boulangerie",
+				"settled_at": "2021-03-01T08:00:00Z",
+				"status": "completed"
+			}
+		],
+		"meta": {"current_page": 1, "next_page": null}
+	}`
+)
+
+func newTestServer(t *testing.T) *httptest.Server {
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+
+	mux.HandleFunc("/v2/organizations/croissant", func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "croissant:secret" {
+			t.Errorf("Authorization \n got %s\n want croissant:secret\n", got)
+		}
+		fmt.Fprint(w, organizationFixture)
+	})
+
+	mux.HandleFunc("/v2/transactions", func(w http.ResponseWriter, r *http.Request) {
+		var opt map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&opt); err != nil {
+			t.Errorf("Unable to decode body: %v", err)
+		}
+		if opt["iban"] != "FR7616798000010000005663951" || fmt.Sprint(opt["status"]) != "[completed pending]" {
+			t.Errorf("Transactions options \n got %v\n", opt)
+		}
+		fmt.Fprint(w, transactionsFixture)
+	})
+
+	mux.HandleFunc("/v2/transactions/t1", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"transaction": {"id": "t1", "attachment_ids": ["a1"]}}`)
+	})
+
+	mux.HandleFunc("/v2/attachments/a1", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"attachment": {"id": "a1", "file_name": "receipt.pdf", "url": "%s/files/a1"}}`, server.URL)
+	})
+
+	mux.HandleFunc("/files/a1", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "%PDF-1.4")
+	})
+
+	return server
+}
+
+func runTest(t *testing.T, server *httptest.Server, args ...string) (string, string, int) {
+	t.Helper()
+
+	env := map[string]string{
+		"QONTO_CONFIG":     filepath.Join("testdata", "config.json"),
+		"QONTO_SECRET_KEY": "secret",
+		"QONTO_BASE_URL":   server.URL,
+	}
+
+	var stdout, stderr bytes.Buffer
+	code := run(context.Background(), args, &stdout, &stderr, func(k string) string { return env[k] })
+	return stdout.String(), stderr.String(), code
+}
+
+func TestRun_orgShow(t *testing.T) {
+	server := newTestServer(t)
+	defer server.Close()
+
+	stdout, stderr, code := runTest(t, server, "org", "show")
+	if code != 0 {
+		t.Fatalf("run exited with %d: %s", code, stderr)
+	}
+
+	want := "SLUG                      NAME  IBAN                         BIC          CURRENCY  BALANCE  " +
+		"AUTHORIZED_BALANCE  STATUS  MAIN\n" +
+		"croissant-bank-account-1        FR7616798000010000005663951  TRZOFR21XXX  EUR       1234.56  " +
+		"1200.00             active  true\n"
+	if stdout != want {
+		t.Errorf("org show \n got %q\n want %q\n", stdout, want)
+	}
+}
+
+func TestRun_txList(t *testing.T) {
+	server := newTestServer(t)
+	defer server.Close()
+
+	stdout, stderr, code := runTest(t, server, "-o", "csv", "tx", "list", "-status", "completed,pending")
+	if code != 0 {
+		t.Fatalf("run exited with %d: %s", code, stderr)
+	}
+
+	want := "id,settled_at,label,signed_amount,currency,operation_type,status,labels\n" +
+		"t1,2021-03-01T08:00:00Z,Boulangerie,-126.00,EUR,card,completed,\n"
+	if stdout != want {
+		t.Errorf("tx list \n got %q\n want %q\n", stdout, want)
+	}
+
+	stdout, _, _ = runTest(t, server, "-o", "json", "tx", "list", "-status", "completed", "-status", "pending")
+	var transactions []map[string]interface{}
+	if err := json.Unmarshal([]byte(stdout), &transactions); err != nil || len(transactions) != 1 {
+		t.Errorf("tx list json \n got %s\n want 1 transaction\n", stdout)
+	}
+}
+
+func TestRun_attachmentsDownload(t *testing.T) {
+	server := newTestServer(t)
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "qonto")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	_, stderr, code := runTest(t, server, "attachments", "download", "-dir", dir, "-transaction", "t1")
+	if code != 0 {
+		t.Fatalf("run exited with %d: %s", code, stderr)
+	}
+
+	b, err := ioutil.ReadFile(filepath.Join(dir, "a1-receipt.pdf"))
+	if err != nil || string(b) != "%PDF-1.4" {
+		t.Errorf("attachments download \n got %q, %v\n want %%PDF-1.4\n", b, err)
+	}
+}
+
+func TestRun_errors(t *testing.T) {
+	server := newTestServer(t)
+	defer server.Close()
+
+	tests := []struct {
+		args []string
+		code int
+		err  string
+	}{
+		{[]string{"tx"}, 2, "Usage"},
+		{[]string{"tx", "delete"}, 2, `unknown command "tx delete"`},
+		{[]string{"-o", "xml", "tx", "list"}, 2, `unknown output format "xml"`},
+		{[]string{"-profile", "unknown", "org", "show"}, 1, `profile "unknown" not found`},
+		{[]string{"tx", "get"}, 1, "usage: qonto tx get <id>"},
+	}
+
+	for _, tt := range tests {
+		_, stderr, code := runTest(t, server, tt.args...)
+		if code != tt.code || !strings.Contains(stderr, tt.err) {
+			t.Errorf("run %v \n got %d, %s\n want %d, %s\n", tt.args, code, stderr, tt.code, tt.err)
+		}
+	}
+}
@@ -0,0 +1,272 @@
+package export
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pixelfactoryio/goqonto/v2"
+	"github.com/pixelfactoryio/goqonto/v2/fx"
+)
+
+// Column a CSV column, rendering one field of a transaction
+type Column struct {
+	// Name written in the header row.
+	Name string
+
+	// Value returns the formatted field of the transaction.
+	Value func(t *goqonto.Transaction, l Locale) string
+}
+
+// Columns available by name in CSVOptions.Columns.
+// The labels column joins the names of the transaction labels with "|".
+var Columns = map[string]Column{
+	"id":                  text("id", func(t *goqonto.Transaction) string { return t.ID }),
+	"transaction_id":      text("transaction_id", func(t *goqonto.Transaction) string { return t.TransactionID }),
+	"amount":              {"amount", amount},
+	"amount_cents":        {"amount_cents", amountCents},
+	"signed_amount":       {"signed_amount", signedAmount},
+	"currency":            text("currency", func(t *goqonto.Transaction) string { return t.Currency }),
+	"local_amount":        {"local_amount", localAmount},
+	"local_amount_cents":  {"local_amount_cents", localAmountCents},
+	"local_currency":      text("local_currency", func(t *goqonto.Transaction) string { return t.LocalCurrency }),
+	"side":                text("side", func(t *goqonto.Transaction) string { return t.Side }),
+	"operation_type":      text("operation_type", func(t *goqonto.Transaction) string { return t.OperationType }),
+	"label":               text("label", func(t *goqonto.Transaction) string { return t.Label }),
+	"settled_at":          date("settled_at", func(t *goqonto.Transaction) time.Time { return t.SettledAt }),
+	"emitted_at":          date("emitted_at", func(t *goqonto.Transaction) time.Time { return t.EmittedAt }),
+	"updated_at":          date("updated_at", func(t *goqonto.Transaction) time.Time { return t.UpdatedAt }),
+	"status":              text("status", func(t *goqonto.Transaction) string { return t.Status }),
+	"note":                text("note", func(t *goqonto.Transaction) string { return t.Note }),
+	"reference":           text("reference", func(t *goqonto.Transaction) string { return t.Reference }),
+	"vat_amount":          {"vat_amount", vatAmount},
+	"vat_rate":            {"vat_rate", vatRate},
+	"initiator_id":        text("initiator_id", func(t *goqonto.Transaction) string { return t.InitiatorID }),
+	"labels":              {"labels", labelNames},
+	"card_last_digits":    text("card_last_digits", func(t *goqonto.Transaction) string { return t.CardLastDigits }),
+	"category":            text("category", func(t *goqonto.Transaction) string { return t.Category }),
+	"attachments_count":   {"attachments_count", attachmentsCount},
+	"attachment_lost":     {"attachment_lost", attachmentLost},
+	"attachment_required": {"attachment_required", attachmentRequired},
+}
+
+// text returns a Column rendering a field that does not depend on the locale
+func text(name string, value func(t *goqonto.Transaction) string) Column {
+	return Column{name, func(t *goqonto.Transaction, _ Locale) string { return value(t) }}
+}
+
+// date returns a Column rendering a date formatted according to the locale
+func date(name string, value func(t *goqonto.Transaction) time.Time) Column {
+	return Column{name, func(t *goqonto.Transaction, l Locale) string { return l.FormatTime(value(t)) }}
+}
+
+// DefaultColumns columns written when CSVOptions.Columns is empty
+var DefaultColumns = []string{
+	"settled_at",
+	"label",
+	"side",
+	"amount",
+	"currency",
+	"operation_type",
+	"status",
+	"reference",
+	"labels",
+	"attachments_count",
+}
+
+// CSVOptions CSV export options
+type CSVOptions struct {
+	// Ordered names of the columns to write, see Columns. Defaults to DefaultColumns.
+	Columns []string
+
+	// Number and date formatting. Defaults to LocaleDefault.
+	Locale *Locale
+
+	// Do not write the header row.
+	NoHeader bool
+}
+
+// CSVWriter writes transactions as CSV records
+type CSVWriter struct {
+	w       *csv.Writer
+	locale  Locale
+	columns []Column
+	header  bool
+}
+
+// NewCSVWriter returns a CSVWriter writing to w. It returns an error if an unknown column is requested.
+func NewCSVWriter(w io.Writer, opt *CSVOptions) (*CSVWriter, error) {
+	if opt == nil {
+		opt = &CSVOptions{}
+	}
+
+	names := opt.Columns
+	if len(names) == 0 {
+		names = DefaultColumns
+	}
+
+	columns := make([]Column, 0, len(names))
+	for _, name := range names {
+		c, ok := Columns[name]
+		if !ok {
+			return nil, fmt.Errorf("export: unknown CSV column %q", name)
+		}
+		columns = append(columns, c)
+	}
+
+	locale := LocaleDefault
+	if opt.Locale != nil {
+		locale = *opt.Locale
+	}
+
+	cw := csv.NewWriter(w)
+	if locale.Comma != 0 {
+		cw.Comma = locale.Comma
+	}
+
+	return &CSVWriter{
+		w:       cw,
+		locale:  locale,
+		columns: columns,
+		header:  !opt.NoHeader,
+	}, nil
+}
+
+// Write writes a transaction, preceded by the header row on the first call
+func (w *CSVWriter) Write(t goqonto.Transaction) error {
+	if w.header {
+		if err := w.writeHeader(); err != nil {
+			return err
+		}
+	}
+
+	record := make([]string, len(w.columns))
+	for i, c := range w.columns {
+		record[i] = c.Value(&t, w.locale)
+	}
+
+	return w.w.Write(record)
+}
+
+// Flush writes any buffered data to the underlying io.Writer, including the header row if
+// no transaction has been written
+func (w *CSVWriter) Flush() error {
+	if w.header {
+		if err := w.writeHeader(); err != nil {
+			return err
+		}
+	}
+
+	w.w.Flush()
+	return w.w.Error()
+}
+
+func (w *CSVWriter) writeHeader() error {
+	w.header = false
+
+	header := make([]string, len(w.columns))
+	for i, c := range w.columns {
+		header[i] = c.Name
+	}
+
+	return w.w.Write(header)
+}
+
+// WriteCSV writes transactions as CSV to w
+func WriteCSV(w io.Writer, transactions []goqonto.Transaction, opt *CSVOptions) error {
+	return StreamCSV(w, NewSliceIterator(transactions), opt)
+}
+
+// StreamCSV writes the transactions of an Iterator as CSV to w
+func StreamCSV(w io.Writer, it Iterator, opt *CSVOptions) error {
+	cw, err := NewCSVWriter(w, opt)
+	if err != nil {
+		return err
+	}
+
+	for it.Next() {
+		if err := cw.Write(it.Transaction()); err != nil {
+			return err
+		}
+	}
+
+	if err := it.Err(); err != nil {
+		return err
+	}
+
+	return cw.Flush()
+}
+
+func amount(t *goqonto.Transaction, l Locale) string {
+	return l.FormatCents(t.AmountCents)
+}
+
+func amountCents(t *goqonto.Transaction, _ Locale) string {
+	return strconv.Itoa(t.AmountCents)
+}
+
+func signedAmount(t *goqonto.Transaction, l Locale) string {
+	if t.Side == goqonto.TransactionSideDebit {
+		return l.FormatCents(-t.AmountCents)
+	}
+	return l.FormatCents(t.AmountCents)
+}
+
+func localAmount(t *goqonto.Transaction, l Locale) string {
+	if t.LocalCurrency == "" {
+		return ""
+	}
+	return formatCents(t.LocalAmountCents, fx.Decimals(t.LocalCurrency), l.DecimalSeparator)
+}
+
+func localAmountCents(t *goqonto.Transaction, _ Locale) string {
+	if t.LocalCurrency == "" {
+		return ""
+	}
+	return strconv.Itoa(t.LocalAmountCents)
+}
+
+func vatAmount(t *goqonto.Transaction, l Locale) string {
+	if t.VatAmountCents == 0 && t.VatRate == 0 {
+		return ""
+	}
+	return l.FormatCents(t.VatAmountCents)
+}
+
+func vatRate(t *goqonto.Transaction, l Locale) string {
+	if t.VatAmountCents == 0 && t.VatRate == 0 {
+		return ""
+	}
+	return l.FormatFloat(t.VatRate)
+}
+
+func labelNames(t *goqonto.Transaction, _ Locale) string {
+	names := make([]string, len(t.Labels))
+	for i, label := range t.Labels {
+		names[i] = label.Name
+	}
+	return strings.Join(names, "|")
+}
+
+func attachmentsCount(t *goqonto.Transaction, _ Locale) string {
+	count := len(t.AttachmentIds)
+	if len(t.Attachments) > count {
+		count = len(t.Attachments)
+	}
+	return strconv.Itoa(count)
+}
+
+func attachmentLost(t *goqonto.Transaction, _ Locale) string {
+	return formatBool(t.AttachmentLost)
+}
+
+func attachmentRequired(t *goqonto.Transaction, _ Locale) string {
+	return formatBool(t.AttachmentRequired)
+}
+
+func formatBool(b bool) string {
+	return strconv.FormatBool(b)
+}
@@ -0,0 +1,121 @@
+package export
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/pixelfactoryio/goqonto/v2"
+)
+
+func TestWriteCSV(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteCSV(&buf, transactions, nil); err != nil {
+		t.Fatalf("WriteCSV returned error: %v", err)
+	}
+
+	testGolden(t, "transactions.csv.golden", buf.Bytes())
+}
+
+func TestWriteCSV_french(t *testing.T) {
+	opt := &CSVOptions{
+		Columns: []string{
+			"emitted_at",
+			"label",
+			"signed_amount",
+			"local_amount",
+			"local_currency",
+			"vat_rate",
+			"vat_amount",
+			"labels",
+			"attachments_count",
+			"note",
+		},
+		Locale: &LocaleFrench,
+	}
+
+	var buf bytes.Buffer
+	if err := WriteCSV(&buf, transactions, opt); err != nil {
+		t.Fatalf("WriteCSV returned error: %v", err)
+	}
+
+	testGolden(t, "transactions_fr.csv.golden", buf.Bytes())
+}
+
+func TestWriteCSV_noHeader(t *testing.T) {
+	opt := &CSVOptions{
+		Columns:  []string{"id", "amount_cents"},
+		NoHeader: true,
+	}
+
+	var buf bytes.Buffer
+	if err := WriteCSV(&buf, transactions[:1], opt); err != nil {
+		t.Fatalf("WriteCSV returned error: %v", err)
+	}
+
+	want := "6ea8271c-87b1-49d0-a66f-1e29a2fe43ba,12600\n"
+	if got := buf.String(); got != want {
+		t.Errorf("WriteCSV \n got %s\n want %s\n", got, want)
+	}
+}
+
+func TestWriteCSV_localCurrencyDecimals(t *testing.T) {
+	jpy, kwd := trx3, trx3
+	jpy.LocalCurrency, jpy.LocalAmountCents = "JPY", 1234
+	kwd.LocalCurrency, kwd.LocalAmountCents = "KWD", 1234
+
+	opt := &CSVOptions{
+		Columns:  []string{"local_amount", "local_currency"},
+		NoHeader: true,
+	}
+
+	var buf bytes.Buffer
+	if err := WriteCSV(&buf, []goqonto.Transaction{jpy, kwd}, opt); err != nil {
+		t.Fatalf("WriteCSV returned error: %v", err)
+	}
+
+	want := "1234,JPY\n1.234,KWD\n"
+	if got := buf.String(); got != want {
+		t.Errorf("WriteCSV \n got %s\n want %s\n", got, want)
+	}
+}
+
+func TestWriteCSV_empty(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteCSV(&buf, nil, &CSVOptions{Columns: []string{"id", "amount"}}); err != nil {
+		t.Fatalf("WriteCSV returned error: %v", err)
+	}
+
+	want := "id,amount\n"
+	if got := buf.String(); got != want {
+		t.Errorf("WriteCSV \n got %s\n want %s\n", got, want)
+	}
+}
+
+func TestNewCSVWriter_unknownColumn(t *testing.T) {
+	_, err := NewCSVWriter(&bytes.Buffer{}, &CSVOptions{Columns: []string{"id", "foo"}})
+	if err == nil {
+		t.Errorf("Expected error to be returned")
+	}
+}
+
+type failingIterator struct {
+	sliceIterator
+	err error
+}
+
+func (it *failingIterator) Err() error {
+	return it.err
+}
+
+func TestStreamCSV_iteratorError(t *testing.T) {
+	want := errors.New("boom")
+	it := &failingIterator{
+		sliceIterator: sliceIterator{transactions: []goqonto.Transaction{trx1}},
+		err:           want,
+	}
+
+	if err := StreamCSV(&bytes.Buffer{}, it, nil); err != want {
+		t.Errorf("StreamCSV error got %v, want %v", err, want)
+	}
+}
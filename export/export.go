@@ -0,0 +1,134 @@
+// Package export writes Qonto transactions to file formats consumed by spreadsheets and accounting tools
+package export
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pixelfactoryio/goqonto/v2"
+)
+
+// Iterator is a stream of transactions, as returned by TransactionsService.Iterator
+type Iterator interface {
+	Next() bool
+	Transaction() goqonto.Transaction
+	Err() error
+}
+
+// sliceIterator Iterator over a slice of transactions
+type sliceIterator struct {
+	transactions []goqonto.Transaction
+	current      goqonto.Transaction
+}
+
+// NewSliceIterator returns an Iterator over a slice of transactions
+func NewSliceIterator(transactions []goqonto.Transaction) Iterator {
+	return &sliceIterator{transactions: transactions}
+}
+
+func (it *sliceIterator) Next() bool {
+	if len(it.transactions) == 0 {
+		return false
+	}
+	it.current, it.transactions = it.transactions[0], it.transactions[1:]
+	return true
+}
+
+func (it *sliceIterator) Transaction() goqonto.Transaction {
+	return it.current
+}
+
+func (it *sliceIterator) Err() error {
+	return nil
+}
+
+// Locale number and date formatting rules
+type Locale struct {
+	// Field separator.
+	Comma rune
+
+	// Decimal separator of amounts.
+	DecimalSeparator string
+
+	// Layout used to format dates.
+	DateLayout string
+
+	// Location in which dates are formatted. Defaults to UTC.
+	Location *time.Location
+}
+
+// LocaleDefault formats amounts with a decimal point, dates as RFC3339 and separates fields with commas
+var LocaleDefault = Locale{
+	Comma:            ',',
+	DecimalSeparator: ".",
+	DateLayout:       time.RFC3339,
+}
+
+// LocaleFrench formats amounts with a decimal comma, dates as dd/MM/yyyy HH:mm:ss and separates
+// fields with semicolons
+var LocaleFrench = Locale{
+	Comma:            ';',
+	DecimalSeparator: ",",
+	DateLayout:       "02/01/2006 15:04:05",
+}
+
+// FormatCents formats an amount in cents as a decimal number
+func (l Locale) FormatCents(cents int) string {
+	return formatCents(cents, 2, l.DecimalSeparator)
+}
+
+// FormatFloat formats a decimal number with the smallest number of digits necessary
+func (l Locale) FormatFloat(f float64) string {
+	return strings.Replace(strconv.FormatFloat(f, 'f', -1, 64), ".", l.decimalSeparator(), 1)
+}
+
+// FormatTime formats a date, returning an empty string for the zero time
+func (l Locale) FormatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+
+	loc := l.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+
+	layout := l.DateLayout
+	if layout == "" {
+		layout = time.RFC3339
+	}
+
+	return t.In(loc).Format(layout)
+}
+
+func (l Locale) decimalSeparator() string {
+	if l.DecimalSeparator == "" {
+		return "."
+	}
+	return l.DecimalSeparator
+}
+
+// formatCents formats an amount expressed in minor units with the given number of decimals
+func formatCents(amount, decimals int, sep string) string {
+	if sep == "" {
+		sep = "."
+	}
+
+	sign := ""
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+
+	s := strconv.Itoa(amount)
+	if decimals <= 0 {
+		return sign + s
+	}
+
+	if len(s) <= decimals {
+		s = strings.Repeat("0", decimals-len(s)+1) + s
+	}
+
+	return sign + s[:len(s)-decimals] + sep + s[len(s)-decimals:]
+}
@@ -0,0 +1,165 @@
+package export
+
+import (
+	"flag"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/pixelfactoryio/goqonto/v2"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+// testGolden compares got with the content of testdata/name, rewriting it when -update is set
+func testGolden(t *testing.T, name string, got []byte) {
+	t.Helper()
+
+	path := filepath.Join("testdata", name)
+	if *update {
+		if err := ioutil.WriteFile(path, got, 0644); err != nil {
+			t.Fatalf("Unable to update golden file %s: %v", path, err)
+		}
+	}
+
+	want, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Unable to read golden file %s: %v", path, err)
+	}
+
+	if string(got) != string(want) {
+		t.Errorf("%s \n got %s\n want %s\n", name, got, want)
+	}
+}
+
+func mustParse(value string) time.Time {
+	v, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+var (
+	trx1 = goqonto.Transaction{
+		ID:               "6ea8271c-87b1-49d0-a66f-1e29a2fe43ba",
+		TransactionID:    "croissant-bank-account-1-transaction-491",
+		Amount:           126.0,
+		AmountCents:      12600,
+		AttachmentIds:    []string{"1812345c-cf62-49a0-bbb0-f654321678"},
+		LocalAmount:      126.0,
+		LocalAmountCents: 12600,
+		Side:             goqonto.TransactionSideDebit,
+		OperationType:    goqonto.TransactionOperationTypeTransfer,
+		Currency:         "EUR",
+		LocalCurrency:    "EUR",
+		Label:            "Boulangerie; Durand",
+		SettledAt:        mustParse("2021-03-01T05:28:00Z"),
+		EmittedAt:        mustParse("2021-03-01T05:27:51Z"),
+		UpdatedAt:        mustParse("2021-03-01T05:29:38Z"),
+		Status:           goqonto.TransactionStatusCompleted,
+		Reference:        "Invoice 2021-042",
+		VatAmount:        21.0,
+		VatAmountCents:   2100,
+		VatRate:          20.0,
+		Labels: []goqonto.Label{
+			{ID: "l1", Name: "compta"},
+			{ID: "l2", Name: "lunch"},
+		},
+		AttachmentRequired: true,
+	}
+
+	trx2 = goqonto.Transaction{
+		ID:               "b2f0e8a4-1d1c-4b36-9d6a-0f1d2a3b4c5d",
+		TransactionID:    "croissant-bank-account-1-transaction-492",
+		Amount:           1500.05,
+		AmountCents:      150005,
+		LocalAmount:      1500.05,
+		LocalAmountCents: 150005,
+		Side:             goqonto.TransactionSideCredit,
+		OperationType:    goqonto.TransactionOperationTypeIncome,
+		Currency:         "EUR",
+		LocalCurrency:    "EUR",
+		Label:            "ACME Corp",
+		SettledAt:        mustParse("2021-03-02T23:30:00Z"),
+		EmittedAt:        mustParse("2021-03-02T23:30:00Z"),
+		UpdatedAt:        mustParse("2021-03-02T23:31:00Z"),
+		Status:           goqonto.TransactionStatusCompleted,
+		Note:             "Payment for \"project X\"",
+		VatAmount:        250.01,
+		VatAmountCents:   25001,
+		VatRate:          5.5,
+	}
+
+	trx3 = goqonto.Transaction{
+		ID:               "c3a1f9b5-2e2d-4c47-8e7b-1a2b3c4d5e6f",
+		TransactionID:    "croissant-bank-account-1-transaction-493",
+		Amount:           0.5,
+		AmountCents:      50,
+		LocalAmount:      0.62,
+		LocalAmountCents: 62,
+		Side:             goqonto.TransactionSideDebit,
+		OperationType:    goqonto.TransactionOperationTypeCard,
+		Currency:         "EUR",
+		LocalCurrency:    "USD",
+		Label:            "Coffee Shop",
+		EmittedAt:        mustParse("2021-03-03T08:00:00Z"),
+		UpdatedAt:        mustParse("2021-03-03T08:00:00Z"),
+		Status:           goqonto.TransactionStatusPending,
+		CardLastDigits:   "4242",
+		Attachments: []goqonto.Attachment{
+			{ID: "a1"},
+			{ID: "a2"},
+		},
+	}
+
+	transactions = []goqonto.Transaction{trx1, trx2, trx3}
+)
+
+func TestFormatCents(t *testing.T) {
+	tests := []struct {
+		amount   int
+		decimals int
+		sep      string
+		want     string
+	}{
+		{12600, 2, ".", "126.00"},
+		{5, 2, ",", "0,05"},
+		{-150005, 2, ",", "-1500,05"},
+		{0, 2, ".", "0.00"},
+		{1500, 0, ".", "1500"},
+		{1500, 3, ".", "1.500"},
+		{-7, 3, "", "-0.007"},
+	}
+
+	for _, tt := range tests {
+		if got := formatCents(tt.amount, tt.decimals, tt.sep); got != tt.want {
+			t.Errorf("formatCents(%d, %d, %q) got %s, want %s", tt.amount, tt.decimals, tt.sep, got, tt.want)
+		}
+	}
+}
+
+func TestLocale_FormatTime(t *testing.T) {
+	paris := time.FixedZone("CET", 3600)
+	l := LocaleFrench
+	l.Location = paris
+
+	if got, want := l.FormatTime(trx2.SettledAt), "03/03/2021 00:30:00"; got != want {
+		t.Errorf("FormatTime got %s, want %s", got, want)
+	}
+
+	if got := l.FormatTime(time.Time{}); got != "" {
+		t.Errorf("FormatTime of zero time got %s, want empty string", got)
+	}
+}
+
+func TestLocale_FormatFloat(t *testing.T) {
+	if got, want := LocaleFrench.FormatFloat(5.5), "5,5"; got != want {
+		t.Errorf("FormatFloat got %s, want %s", got, want)
+	}
+
+	if got, want := LocaleDefault.FormatFloat(20), "20"; got != want {
+		t.Errorf("FormatFloat got %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,278 @@
+package export
+
+import (
+	"encoding/xml"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/pixelfactoryio/goqonto/v2"
+)
+
+// ofxHeader OFX 2.2 XML declaration and processing instruction
+const ofxHeader = `<?xml version="1.0" encoding="UTF-8" standalone="no"?>` + "\n" +
+	`<?OFX OFXHEADER="200" VERSION="220" SECURITY="NONE" OLDFILEUID="NONE" NEWFILEUID="NONE"?>` + "\n"
+
+// ofxDateLayout OFX date layout, always written in UTC
+const ofxDateLayout = "20060102150405.000[+0:UTC]"
+
+// OFX transaction types
+const (
+	OFXTypeCredit      = "CREDIT"
+	OFXTypeDebit       = "DEBIT"
+	OFXTypePOS         = "POS"
+	OFXTypeTransfer    = "XFER"
+	OFXTypeDirectDebit = "DIRECTDEBIT"
+	OFXTypeDeposit     = "DEP"
+	OFXTypeCheck       = "CHECK"
+	OFXTypeServiceFee  = "SRVCHG"
+)
+
+// OFXOptions OFX export options
+type OFXOptions struct {
+	// Start and end of the statement. Default to the dates of the first and last transactions.
+	From time.Time
+	To   time.Time
+
+	// Server date of the statement and date of the balances. Defaults to BankAccount.UpdatedAt,
+	// or to the current time if it is not set.
+	GeneratedAt time.Time
+
+	// Account type. Defaults to CHECKING.
+	AccountType string
+
+	// Language of the statement in ISO 639-2/T. Defaults to FRA.
+	Language string
+}
+
+// OFX document, limited to the elements of a bank statement response
+type OFX struct {
+	XMLName xml.Name             `xml:"OFX"`
+	SignOn  OFXSignOn            `xml:"SIGNONMSGSRSV1>SONRS"`
+	Bank    OFXStatementResponse `xml:"BANKMSGSRSV1>STMTTRNRS"`
+}
+
+// OFXStatus OFX status aggregate
+type OFXStatus struct {
+	Code     int    `xml:"CODE"`
+	Severity string `xml:"SEVERITY"`
+}
+
+// OFXSignOn OFX signon response
+type OFXSignOn struct {
+	Status   OFXStatus `xml:"STATUS"`
+	DTServer string    `xml:"DTSERVER"`
+	Language string    `xml:"LANGUAGE"`
+}
+
+// OFXStatementResponse OFX statement transaction response
+type OFXStatementResponse struct {
+	TrnUID    string    `xml:"TRNUID"`
+	Status    OFXStatus `xml:"STATUS"`
+	Statement OFXStatement
+}
+
+// OFXStatement OFX bank statement
+type OFXStatement struct {
+	XMLName          xml.Name         `xml:"STMTRS"`
+	Currency         string           `xml:"CURDEF"`
+	BankID           string           `xml:"BANKACCTFROM>BANKID"`
+	AccountID        string           `xml:"BANKACCTFROM>ACCTID"`
+	AccountType      string           `xml:"BANKACCTFROM>ACCTTYPE"`
+	Start            string           `xml:"BANKTRANLIST>DTSTART"`
+	End              string           `xml:"BANKTRANLIST>DTEND"`
+	Transactions     []OFXTransaction `xml:"BANKTRANLIST>STMTTRN"`
+	LedgerBalance    OFXBalance       `xml:"LEDGERBAL"`
+	AvailableBalance OFXBalance       `xml:"AVAILBAL"`
+}
+
+// OFXTransaction OFX statement transaction
+type OFXTransaction struct {
+	Type   string `xml:"TRNTYPE"`
+	Posted string `xml:"DTPOSTED"`
+	User   string `xml:"DTUSER,omitempty"`
+	Amount string `xml:"TRNAMT"`
+	FITID  string `xml:"FITID"`
+	Name   string `xml:"NAME,omitempty"`
+	Memo   string `xml:"MEMO,omitempty"`
+}
+
+// OFXBalance OFX balance
+type OFXBalance struct {
+	Amount string `xml:"BALAMT"`
+	AsOf   string `xml:"DTASOF"`
+}
+
+// OFXType returns the OFX TRNTYPE of a transaction from its operation type and side
+func OFXType(t goqonto.Transaction) string {
+	debit := t.Side == goqonto.TransactionSideDebit
+
+	switch t.OperationType {
+	case goqonto.TransactionOperationTypeCard:
+		return OFXTypePOS
+	case goqonto.TransactionOperationTypeTransfer:
+		return OFXTypeTransfer
+	case "direct_debit":
+		return OFXTypeDirectDebit
+	case goqonto.TransactionOperationTypeIncome, "swift_income":
+		return OFXTypeCredit
+	case "qonto_fee":
+		return OFXTypeServiceFee
+	case "cheque":
+		if debit {
+			return OFXTypeCheck
+		}
+		return OFXTypeDeposit
+	}
+
+	if debit {
+		return OFXTypeDebit
+	}
+	return OFXTypeCredit
+}
+
+// NewOFX builds the OFX statement of a bank account. Only completed transactions are part of an OFX
+// statement, the other ones are ignored. Amounts are negative for debits.
+func NewOFX(account goqonto.BankAccount, transactions []goqonto.Transaction, opt *OFXOptions) *OFX {
+	if opt == nil {
+		opt = &OFXOptions{}
+	}
+
+	generatedAt := opt.GeneratedAt
+	if generatedAt.IsZero() {
+		generatedAt = account.UpdatedAt
+	}
+	if generatedAt.IsZero() {
+		generatedAt = time.Now()
+	}
+
+	accountType := opt.AccountType
+	if accountType == "" {
+		accountType = "CHECKING"
+	}
+
+	language := opt.Language
+	if language == "" {
+		language = "FRA"
+	}
+
+	completed := completedTransactions(transactions)
+
+	from, to := opt.From, opt.To
+	if len(completed) > 0 {
+		if from.IsZero() {
+			from = completed[0].SettledAt
+		}
+		if to.IsZero() {
+			to = completed[len(completed)-1].SettledAt
+		}
+	}
+	if from.IsZero() {
+		from = generatedAt
+	}
+	if to.IsZero() {
+		to = generatedAt
+	}
+
+	statement := OFXStatement{
+		Currency:    account.Currency,
+		BankID:      account.BIC,
+		AccountID:   account.IBAN,
+		AccountType: accountType,
+		Start:       formatOFXDate(from),
+		End:         formatOFXDate(to),
+		LedgerBalance: OFXBalance{
+			Amount: formatCents(account.BalanceCents, 2, "."),
+			AsOf:   formatOFXDate(generatedAt),
+		},
+		AvailableBalance: OFXBalance{
+			Amount: formatCents(account.AuthorizedBalanceCents, 2, "."),
+			AsOf:   formatOFXDate(generatedAt),
+		},
+	}
+
+	for _, t := range completed {
+		amount := t.AmountCents
+		if t.Side == goqonto.TransactionSideDebit {
+			amount = -amount
+		}
+
+		trn := OFXTransaction{
+			Type:   OFXType(t),
+			Posted: formatOFXDate(t.SettledAt),
+			Amount: formatCents(amount, 2, "."),
+			FITID:  t.TransactionID,
+			Name:   truncate(t.Label, 32),
+			Memo:   t.Reference,
+		}
+		if trn.FITID == "" {
+			trn.FITID = t.ID
+		}
+		if !t.EmittedAt.IsZero() {
+			trn.User = formatOFXDate(t.EmittedAt)
+		}
+		if t.Note != "" && trn.Memo == "" {
+			trn.Memo = t.Note
+		}
+
+		statement.Transactions = append(statement.Transactions, trn)
+	}
+
+	return &OFX{
+		SignOn: OFXSignOn{
+			Status:   OFXStatus{Code: 0, Severity: "INFO"},
+			DTServer: formatOFXDate(generatedAt),
+			Language: language,
+		},
+		Bank: OFXStatementResponse{
+			TrnUID:    "0",
+			Status:    OFXStatus{Code: 0, Severity: "INFO"},
+			Statement: statement,
+		},
+	}
+}
+
+// WriteOFX writes the OFX 2.2 statement of a bank account to w
+func WriteOFX(w io.Writer, account goqonto.BankAccount, transactions []goqonto.Transaction, opt *OFXOptions) error {
+	if _, err := io.WriteString(w, ofxHeader); err != nil {
+		return err
+	}
+
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(NewOFX(account, transactions, opt)); err != nil {
+		return err
+	}
+
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// completedTransactions returns the completed transactions sorted by settlement date
+func completedTransactions(transactions []goqonto.Transaction) []goqonto.Transaction {
+	var completed []goqonto.Transaction
+	for _, t := range transactions {
+		if t.Status == goqonto.TransactionStatusCompleted {
+			completed = append(completed, t)
+		}
+	}
+
+	sort.SliceStable(completed, func(i, j int) bool {
+		return completed[i].SettledAt.Before(completed[j].SettledAt)
+	})
+
+	return completed
+}
+
+func formatOFXDate(t time.Time) string {
+	return t.UTC().Format(ofxDateLayout)
+}
+
+// truncate shortens s to at most n runes
+func truncate(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n])
+}
@@ -0,0 +1,138 @@
+package export
+
+import (
+	"bytes"
+	"encoding/xml"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/pixelfactoryio/goqonto/v2"
+)
+
+var bankAccount = goqonto.BankAccount{
+	Slug:                   "croissant-bank-account-1",
+	IBAN:                   "FR7616798000010000004321396",
+	BIC:                    "TRZOFR21XXX",
+	Currency:               "EUR",
+	BalanceCents:           22530,
+	AuthorizedBalanceCents: 21320,
+	UpdatedAt:              mustParse("2021-03-04T10:00:00Z"),
+}
+
+func TestOFXType(t *testing.T) {
+	tests := []struct {
+		operationType string
+		side          string
+		want          string
+	}{
+		{"card", goqonto.TransactionSideDebit, OFXTypePOS},
+		{"transfer", goqonto.TransactionSideDebit, OFXTypeTransfer},
+		{"transfer", goqonto.TransactionSideCredit, OFXTypeTransfer},
+		{"direct_debit", goqonto.TransactionSideDebit, OFXTypeDirectDebit},
+		{"income", goqonto.TransactionSideCredit, OFXTypeCredit},
+		{"swift_income", goqonto.TransactionSideCredit, OFXTypeCredit},
+		{"qonto_fee", goqonto.TransactionSideDebit, OFXTypeServiceFee},
+		{"cheque", goqonto.TransactionSideDebit, OFXTypeCheck},
+		{"cheque", goqonto.TransactionSideCredit, OFXTypeDeposit},
+		{"recall", goqonto.TransactionSideDebit, OFXTypeDebit},
+		{"recall", goqonto.TransactionSideCredit, OFXTypeCredit},
+	}
+
+	for _, tt := range tests {
+		trx := goqonto.Transaction{OperationType: tt.operationType, Side: tt.side}
+		if got := OFXType(trx); got != tt.want {
+			t.Errorf("OFXType(%s, %s) got %s, want %s", tt.operationType, tt.side, got, tt.want)
+		}
+	}
+}
+
+func TestWriteOFX(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteOFX(&buf, bankAccount, transactions, nil); err != nil {
+		t.Fatalf("WriteOFX returned error: %v", err)
+	}
+
+	testGolden(t, "statement.ofx.golden", buf.Bytes())
+}
+
+func TestWriteOFX_roundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteOFX(&buf, bankAccount, transactions, nil); err != nil {
+		t.Fatalf("WriteOFX returned error: %v", err)
+	}
+
+	if !strings.HasPrefix(buf.String(), ofxHeader) {
+		t.Errorf("WriteOFX output does not start with the OFX header")
+	}
+
+	got := new(OFX)
+	if err := xml.Unmarshal(buf.Bytes(), got); err != nil {
+		t.Fatalf("xml.Unmarshal returned error: %v", err)
+	}
+
+	statement := got.Bank.Statement
+	if statement.AccountID != bankAccount.IBAN || statement.BankID != bankAccount.BIC {
+		t.Errorf("OFX account got %s/%s, want %s/%s",
+			statement.BankID, statement.AccountID, bankAccount.BIC, bankAccount.IBAN)
+	}
+
+	if statement.Start != "20210301052800.000[+0:UTC]" || statement.End != "20210302233000.000[+0:UTC]" {
+		t.Errorf("OFX period got %s - %s", statement.Start, statement.End)
+	}
+
+	wantBalances := []OFXBalance{
+		{Amount: "225.30", AsOf: "20210304100000.000[+0:UTC]"},
+		{Amount: "213.20", AsOf: "20210304100000.000[+0:UTC]"},
+	}
+	gotBalances := []OFXBalance{statement.LedgerBalance, statement.AvailableBalance}
+	if !reflect.DeepEqual(gotBalances, wantBalances) {
+		t.Errorf("OFX balances \n got %v\n want %v\n", gotBalances, wantBalances)
+	}
+
+	want := []OFXTransaction{
+		{
+			Type:   OFXTypeTransfer,
+			Posted: "20210301052800.000[+0:UTC]",
+			User:   "20210301052751.000[+0:UTC]",
+			Amount: "-126.00",
+			FITID:  trx1.TransactionID,
+			Name:   trx1.Label,
+			Memo:   trx1.Reference,
+		},
+		{
+			Type:   OFXTypeCredit,
+			Posted: "20210302233000.000[+0:UTC]",
+			User:   "20210302233000.000[+0:UTC]",
+			Amount: "1500.05",
+			FITID:  trx2.TransactionID,
+			Name:   trx2.Label,
+			Memo:   trx2.Note,
+		},
+	}
+
+	if !reflect.DeepEqual(statement.Transactions, want) {
+		t.Errorf("OFX transactions \n got %v\n want %v\n", statement.Transactions, want)
+	}
+}
+
+func TestNewOFX_empty(t *testing.T) {
+	opt := &OFXOptions{
+		GeneratedAt: mustParse("2021-03-04T10:00:00Z"),
+		AccountType: "SAVINGS",
+	}
+
+	got := NewOFX(bankAccount, nil, opt).Bank.Statement
+
+	if got.Start != "20210304100000.000[+0:UTC]" || got.End != got.Start {
+		t.Errorf("OFX period got %s - %s", got.Start, got.End)
+	}
+
+	if got.AccountType != "SAVINGS" {
+		t.Errorf("OFX account type got %s, want SAVINGS", got.AccountType)
+	}
+
+	if len(got.Transactions) != 0 {
+		t.Errorf("OFX transactions got %v, want none", got.Transactions)
+	}
+}
@@ -0,0 +1,98 @@
+package export
+
+import (
+	"bufio"
+	"io"
+	"strings"
+
+	"github.com/pixelfactoryio/goqonto/v2"
+)
+
+// QIFOptions QIF export options
+type QIFOptions struct {
+	// Name of the account written in the !Account header. The header is omitted when empty.
+	AccountName string
+
+	// Layout used to format dates. Defaults to MM/DD/YYYY.
+	DateLayout string
+
+	// Category returns the category (L field) of a transaction.
+	// Defaults to the transaction labels joined with ":".
+	Category func(t goqonto.Transaction) string
+}
+
+// WriteQIF writes the completed transactions of a bank account as a QIF bank register to w.
+// Amounts are negative for debits.
+func WriteQIF(w io.Writer, account goqonto.BankAccount, transactions []goqonto.Transaction, opt *QIFOptions) error {
+	if opt == nil {
+		opt = &QIFOptions{}
+	}
+
+	layout := opt.DateLayout
+	if layout == "" {
+		layout = "01/02/2006"
+	}
+
+	category := opt.Category
+	if category == nil {
+		category = qifCategory
+	}
+
+	bw := bufio.NewWriter(w)
+
+	if opt.AccountName != "" {
+		writeQIFField(bw, "!Account", "")
+		writeQIFField(bw, "N", opt.AccountName)
+		writeQIFField(bw, "T", "Bank")
+		if account.IBAN != "" {
+			writeQIFField(bw, "D", account.IBAN)
+		}
+		writeQIFField(bw, "^", "")
+	}
+
+	writeQIFField(bw, "!Type:Bank", "")
+
+	for _, t := range completedTransactions(transactions) {
+		amount := t.AmountCents
+		if t.Side == goqonto.TransactionSideDebit {
+			amount = -amount
+		}
+
+		writeQIFField(bw, "D", t.SettledAt.UTC().Format(layout))
+		writeQIFField(bw, "T", formatCents(amount, 2, "."))
+		writeQIFField(bw, "C", "X")
+		writeQIFField(bw, "N", t.TransactionID)
+		writeQIFField(bw, "P", t.Label)
+		if memo := qifMemo(t); memo != "" {
+			writeQIFField(bw, "M", memo)
+		}
+		if c := category(t); c != "" {
+			writeQIFField(bw, "L", c)
+		}
+		writeQIFField(bw, "^", "")
+	}
+
+	return bw.Flush()
+}
+
+// writeQIFField writes a QIF line, errors are reported by the final Flush of the bufio.Writer
+func writeQIFField(w *bufio.Writer, code, value string) {
+	// QIF is line oriented: values cannot span several lines.
+	value = strings.NewReplacer("\r\n", " ", "\n", " ", "\r", " ").Replace(value)
+	_, _ = w.WriteString(code + value + "\n")
+}
+
+func qifMemo(t goqonto.Transaction) string {
+	if t.Reference != "" {
+		return t.Reference
+	}
+	return t.Note
+}
+
+func qifCategory(t goqonto.Transaction) string {
+	names := make([]string, len(t.Labels))
+	for i, label := range t.Labels {
+		names[i] = label.Name
+	}
+	return strings.Join(names, ":")
+}
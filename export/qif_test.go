@@ -0,0 +1,98 @@
+package export
+
+import (
+	"bufio"
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/pixelfactoryio/goqonto/v2"
+)
+
+// parseQIF parses a QIF file into its header and records, each record mapping field codes to values
+func parseQIF(t *testing.T, data []byte) ([]string, []map[string]string) {
+	t.Helper()
+
+	var headers []string
+	var records []map[string]string
+	record := map[string]string{}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "!"):
+			headers = append(headers, line)
+		case line == "^":
+			records = append(records, record)
+			record = map[string]string{}
+		case line == "":
+			t.Errorf("Unexpected empty QIF line")
+		default:
+			record[line[:1]] = line[1:]
+		}
+	}
+
+	if len(record) != 0 {
+		t.Errorf("Unterminated QIF record: %v", record)
+	}
+
+	return headers, records
+}
+
+func TestWriteQIF(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteQIF(&buf, bankAccount, transactions, nil); err != nil {
+		t.Fatalf("WriteQIF returned error: %v", err)
+	}
+
+	testGolden(t, "register.qif.golden", buf.Bytes())
+}
+
+func TestWriteQIF_roundTrip(t *testing.T) {
+	note := trx2
+	note.Note = "multi\nline"
+
+	opt := &QIFOptions{
+		AccountName: "Qonto",
+		DateLayout:  "02/01/2006",
+	}
+
+	var buf bytes.Buffer
+	if err := WriteQIF(&buf, bankAccount, []goqonto.Transaction{trx3, note, trx1}, opt); err != nil {
+		t.Fatalf("WriteQIF returned error: %v", err)
+	}
+
+	headers, records := parseQIF(t, buf.Bytes())
+
+	wantHeaders := []string{"!Account", "!Type:Bank"}
+	if !reflect.DeepEqual(headers, wantHeaders) {
+		t.Errorf("QIF headers \n got %v\n want %v\n", headers, wantHeaders)
+	}
+
+	want := []map[string]string{
+		{"N": "Qonto", "T": "Bank", "D": bankAccount.IBAN},
+		{
+			"D": "01/03/2021",
+			"T": "-126.00",
+			"C": "X",
+			"N": trx1.TransactionID,
+			"P": trx1.Label,
+			"M": trx1.Reference,
+			"L": "compta:lunch",
+		},
+		{
+			"D": "02/03/2021",
+			"T": "1500.05",
+			"C": "X",
+			"N": trx2.TransactionID,
+			"P": trx2.Label,
+			"M": "multi line",
+		},
+	}
+
+	if !reflect.DeepEqual(records, want) {
+		t.Errorf("QIF records \n got %v\n want %v\n", records, want)
+	}
+}
@@ -0,0 +1,490 @@
+// Package fake generates plausible and reproducible Qonto data for demos and load tests
+package fake
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pixelfactoryio/goqonto/v2"
+	"github.com/pixelfactoryio/goqonto/v2/fx"
+	"github.com/pixelfactoryio/goqonto/v2/iban"
+	"github.com/pixelfactoryio/goqonto/v2/vat"
+)
+
+// bankCode and branchCode of the generated French IBANs
+const (
+	bankCode   = 16798
+	branchCode = 1
+)
+
+// BIC of the generated bank accounts
+const BIC = "QNTOFRP1XXX"
+
+// Rates allowed VAT rates
+var Rates = []float64{vat.RateMultiple, 0, 2.1, 5.5, 10, 20}
+
+// Options generator options
+type Options struct {
+	// Seed of the generator, the same seed and options generate the same data.
+	Seed int64
+
+	// Organization slug. Defaults to "croissant".
+	Slug string
+
+	// Number of bank accounts, the first one being the main account. Defaults to 1 when not positive.
+	Accounts int
+
+	// Number of memberships, each holding a card. Defaults to 5 when not positive.
+	Members int
+
+	// Date of the first transaction. Defaults to 2021-01-01 UTC.
+	Start time.Time
+
+	// Balance of each bank account before the first transaction. Defaults to 50,000.00 EUR.
+	OpeningBalanceCents int
+}
+
+// kind kind of generated transaction
+type kind int
+
+const (
+	kindCard kind = iota
+	kindTransfer
+	kindIncome
+)
+
+// counterparty template of the transactions of a counterparty
+type counterparty struct {
+	kind     kind
+	name     string
+	category string
+	label    string
+	minCents int
+	maxCents int
+	rate     float64
+	currency string
+}
+
+// counterparties of the generated transactions
+var counterparties = []counterparty{
+	{kindCard, "SNCF", "transport", "Train", 2500, 15000, 10, "EUR"},
+	{kindCard, "Uber", "transport", "Taxi", 1200, 6000, 10, "EUR"},
+	{kindCard, "Le Pain Quotidien", "restaurant_and_bar", "Meals", 800, 6000, 10, "EUR"},
+	{kindCard, "Ibis Hotels", "hotel_and_lodging", "Hotel", 7000, 18000, 10, "EUR"},
+	{kindCard, "Total Energies", "gas_station", "", 3000, 9000, 20, "EUR"},
+	{kindCard, "Amazon", "office_supply", "Supplies", 1000, 20000, 20, "EUR"},
+	{kindCard, "Librairie Gallimard", "office_supply", "Supplies", 900, 6000, 5.5, "EUR"},
+	{kindCard, "Slack", "online_service", "SaaS", 700, 12000, 20, "EUR"},
+	{kindCard, "Amazon Web Services", "online_service", "Hosting", 2000, 50000, 0, "USD"},
+	{kindCard, "GitHub", "online_service", "SaaS", 400, 2100, 0, "USD"},
+	{kindCard, "Pret A Manger", "restaurant_and_bar", "Meals", 600, 2500, 0, "GBP"},
+	{kindTransfer, "WeWork", "office_rental", "Rent", 250000, 400000, 20, "EUR"},
+	{kindTransfer, "URSSAF", "tax", "Taxes", 100000, 900000, 0, "EUR"},
+	{kindTransfer, "Cabinet Martin Expert-Comptable", "legal_and_accounting", "", 60000, 180000, 20, "EUR"},
+	{kindTransfer, "Le Monde Presse", "other_expense", "", 1000, 4000, 2.1, "EUR"},
+	{kindIncome, "ACME Corp", "sales", "Sales", 100000, 2000000, 20, "EUR"},
+	{kindIncome, "Globex SA", "sales", "Sales", 50000, 800000, 20, "EUR"},
+	{kindIncome, "Initech SAS", "sales", "Sales", 20000, 400000, 20, "EUR"},
+	{kindIncome, "Umbrella Holding", "sales", "Sales", 300000, 3000000, 20, "EUR"},
+}
+
+// labelTree names of the parent labels and of their children
+var labelTree = []struct {
+	name     string
+	children []string
+}{
+	{"Travel", []string{"Train", "Taxi", "Hotel"}},
+	{"Meals", nil},
+	{"Software", []string{"SaaS", "Hosting"}},
+	{"Office", []string{"Rent", "Supplies"}},
+	{"Sales", nil},
+	{"Taxes", nil},
+}
+
+// eurRates units of foreign currency per euro
+var eurRates = map[string]float64{"USD": 1.18, "GBP": 0.86}
+
+var (
+	firstNames = []string{"Alice", "Bruno", "Camille", "David", "Emma", "Farid", "Gabrielle", "Hugo", "Inès", "Jules"}
+	lastNames  = []string{"Martin", "Bernard", "Dubois", "Thomas", "Robert", "Richard", "Petit", "Durand", "Leroy"}
+)
+
+// member membership and card of a member
+type member struct {
+	membership goqonto.Membership
+	card       string
+}
+
+// pending card payment waiting for its completion or reversal
+type pending struct {
+	iban string
+	t    goqonto.Transaction
+	at   time.Time
+}
+
+// Generator generates an organization and a stream of transactions on its bank accounts. The balances
+// of the bank accounts follow the generated transactions.
+type Generator struct {
+	rnd     *rand.Rand
+	slug    string
+	now     time.Time
+	seq     int
+	org     goqonto.Organization
+	members []member
+	labels  []goqonto.Label
+	pending []pending
+}
+
+// New returns a Generator
+func New(opt *Options) *Generator {
+	o := Options{}
+	if opt != nil {
+		o = *opt
+	}
+	if o.Slug == "" {
+		o.Slug = "croissant"
+	}
+	if o.Accounts <= 0 {
+		o.Accounts = 1
+	}
+	if o.Members <= 0 {
+		o.Members = 5
+	}
+	if o.Start.IsZero() {
+		o.Start = time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC)
+	}
+	if o.OpeningBalanceCents == 0 {
+		o.OpeningBalanceCents = 5000000
+	}
+
+	g := &Generator{
+		rnd:  rand.New(rand.NewSource(o.Seed)),
+		slug: o.Slug,
+		now:  o.Start,
+		org:  goqonto.Organization{Slug: o.Slug},
+	}
+
+	for k := 0; k < o.Accounts; k++ {
+		name := "Main account"
+		if k > 0 {
+			name = fmt.Sprintf("Account %d", k+1)
+		}
+		g.org.BankAccounts = append(g.org.BankAccounts, goqonto.BankAccount{
+			ID:       g.uuid(),
+			Slug:     fmt.Sprintf("%s-bank-account-%d", o.Slug, k+1),
+			IBAN:     g.IBAN(),
+			BIC:      BIC,
+			Currency: "EUR",
+			Name:     name,
+			Status:   goqonto.BankAccountStatusActive,
+			Main:     k == 0,
+		})
+		g.credit(k, o.OpeningBalanceCents, true)
+		g.org.BankAccounts[k].UpdatedAt = o.Start
+	}
+
+	for k := 0; k < o.Members; k++ {
+		g.members = append(g.members, member{
+			membership: goqonto.Membership{
+				ID:       g.uuid(),
+				FistName: firstNames[g.rnd.Intn(len(firstNames))],
+				LastName: lastNames[g.rnd.Intn(len(lastNames))],
+			},
+			card: fmt.Sprintf("%04d", g.rnd.Intn(10000)),
+		})
+	}
+
+	for _, parent := range labelTree {
+		p := goqonto.Label{ID: g.uuid(), Name: parent.name}
+		g.labels = append(g.labels, p)
+		for _, child := range parent.children {
+			g.labels = append(g.labels, goqonto.Label{ID: g.uuid(), Name: child, ParentID: p.ID})
+		}
+	}
+
+	return g
+}
+
+// IBAN returns a valid French IBAN
+func (g *Generator) IBAN() string {
+	account := g.rnd.Int63n(100000000000)
+	key := 97 - (89*bankCode+15*branchCode+3*account)%97
+	bban := fmt.Sprintf("%05d%05d%011d%02d", bankCode, branchCode, account, key)
+	return "FR" + iban.CheckDigits("FR", bban) + bban
+}
+
+// Organization returns the organization with the current balances of its bank accounts
+func (g *Generator) Organization() goqonto.Organization {
+	org := g.org
+	org.BankAccounts = append([]goqonto.BankAccount(nil), g.org.BankAccounts...)
+	return org
+}
+
+// Memberships returns the memberships of the organization
+func (g *Generator) Memberships() []goqonto.Membership {
+	memberships := make([]goqonto.Membership, len(g.members))
+	for k, m := range g.members {
+		memberships[k] = m.membership
+	}
+	return memberships
+}
+
+// Labels returns the labels of the organization, each parent label being followed by its children
+func (g *Generator) Labels() []goqonto.Label {
+	return append([]goqonto.Label(nil), g.labels...)
+}
+
+// Next returns the next transaction and the IBAN of its bank account. Card payments are first
+// returned pending, then returned again with the same ID once completed or reversed.
+func (g *Generator) Next() (string, goqonto.Transaction) {
+	next := g.now.Add(time.Duration(g.rnd.Int63n(int64(6 * time.Hour))))
+
+	if len(g.pending) > 0 && !g.pending[0].at.After(next) {
+		p := g.pending[0]
+		g.pending = g.pending[1:]
+		g.now = p.at
+		return p.iban, g.settle(p)
+	}
+
+	g.now = next
+	return g.create()
+}
+
+// Transactions generates n transactions and returns the latest state of each transaction by IBAN,
+// in order of creation
+func (g *Generator) Transactions(n int) map[string][]goqonto.Transaction {
+	result := make(map[string][]goqonto.Transaction)
+	index := make(map[string]int)
+
+	for k := 0; k < n; k++ {
+		iban, t := g.Next()
+		if i, ok := index[t.ID]; ok {
+			result[iban][i] = t
+			continue
+		}
+		index[t.ID] = len(result[iban])
+		result[iban] = append(result[iban], t)
+	}
+
+	return result
+}
+
+// create returns a new transaction on a random bank account
+func (g *Generator) create() (string, goqonto.Transaction) {
+	account := 0
+	if len(g.org.BankAccounts) > 1 && g.rnd.Float64() < 0.15 {
+		account = 1 + g.rnd.Intn(len(g.org.BankAccounts)-1)
+	}
+	ba := &g.org.BankAccounts[account]
+
+	var k kind
+	switch r := g.rnd.Float64(); {
+	case r < 0.7:
+		k = kindCard
+	case r < 0.8:
+		k = kindTransfer
+	default:
+		k = kindIncome
+	}
+
+	c := g.counterparty(k)
+	amount := c.minCents + g.rnd.Intn(c.maxCents-c.minCents+1)
+	if k != kindIncome && amount > ba.AuthorizedBalanceCents {
+		c = g.counterparty(kindIncome)
+		amount = c.minCents + g.rnd.Intn(c.maxCents-c.minCents+1)
+	}
+
+	g.seq++
+	t := goqonto.Transaction{
+		ID:                 g.uuid(),
+		TransactionID:      fmt.Sprintf("%s-transaction-%d", g.slug, g.seq),
+		Currency:           "EUR",
+		LocalCurrency:      "EUR",
+		Label:              c.name,
+		Category:           c.category,
+		Side:               goqonto.TransactionSideDebit,
+		Status:             goqonto.TransactionStatusCompleted,
+		EmittedAt:          g.now,
+		SettledAt:          g.now,
+		UpdatedAt:          g.now,
+		AttachmentRequired: true,
+	}
+	g.setAmount(&t, amount, c)
+	g.setVAT(&t, c.rate)
+
+	if c.label != "" {
+		l := g.label(c.label)
+		t.LabelIds = []string{l.ID}
+		t.Labels = []goqonto.Label{l}
+	}
+
+	switch c.kind {
+	case kindCard:
+		m := g.members[g.rnd.Intn(len(g.members))]
+		t.OperationType = goqonto.TransactionOperationTypeCard
+		t.InitiatorID = m.membership.ID
+		t.CardLastDigits = m.card
+		t.SettledAt = time.Time{}
+
+		if g.rnd.Float64() < 0.01 {
+			t.Status = goqonto.TransactionStatusDeclined
+			return ba.IBAN, t
+		}
+
+		t.Status = goqonto.TransactionStatusPending
+		ba.AuthorizedBalanceCents -= t.AmountCents
+		ba.AuthorizedBalance = fx.ToMajor(ba.AuthorizedBalanceCents, ba.Currency)
+		ba.UpdatedAt = g.now
+
+		g.schedule(pending{
+			iban: ba.IBAN,
+			t:    t,
+			at:   g.now.Add(24*time.Hour + time.Duration(g.rnd.Int63n(int64(48*time.Hour)))),
+		})
+	case kindTransfer:
+		t.OperationType = goqonto.TransactionOperationTypeTransfer
+		t.InitiatorID = g.members[0].membership.ID
+		t.Reference = fmt.Sprintf("Invoice %s-%d", strings.ToUpper(c.name[:3]), 1000+g.rnd.Intn(9000))
+		g.attach(&t)
+		g.credit(account, -t.AmountCents, true)
+	case kindIncome:
+		t.OperationType = goqonto.TransactionOperationTypeIncome
+		t.Side = goqonto.TransactionSideCredit
+		t.Reference = fmt.Sprintf("%s invoice F-%d-%03d", strings.ToUpper(g.slug), g.now.Year(), g.seq%1000)
+		t.AttachmentRequired = false
+		g.credit(account, t.AmountCents, true)
+	}
+
+	return ba.IBAN, t
+}
+
+// settle completes or reverses a pending card payment
+func (g *Generator) settle(p pending) goqonto.Transaction {
+	t := p.t
+	t.UpdatedAt = p.at
+
+	account := 0
+	for k, ba := range g.org.BankAccounts {
+		if ba.IBAN == p.iban {
+			account = k
+		}
+	}
+
+	if g.rnd.Float64() < 0.03 {
+		t.Status = goqonto.TransactionStatusReversed
+		g.credit(account, t.AmountCents, false)
+		return t
+	}
+
+	t.Status = goqonto.TransactionStatusCompleted
+	t.SettledAt = p.at
+	if g.rnd.Float64() < 0.8 {
+		g.attach(&t)
+	}
+
+	// The authorized balance was debited when the payment was authorized.
+	ba := &g.org.BankAccounts[account]
+	ba.BalanceCents -= t.AmountCents
+	ba.Balance = fx.ToMajor(ba.BalanceCents, ba.Currency)
+	ba.UpdatedAt = g.now
+
+	return t
+}
+
+// credit adds cents to the authorized balance of an account, and to its balance when settled
+func (g *Generator) credit(account, cents int, settled bool) {
+	ba := &g.org.BankAccounts[account]
+
+	ba.AuthorizedBalanceCents += cents
+	ba.AuthorizedBalance = fx.ToMajor(ba.AuthorizedBalanceCents, ba.Currency)
+	if settled {
+		ba.BalanceCents += cents
+		ba.Balance = fx.ToMajor(ba.BalanceCents, ba.Currency)
+	}
+	ba.UpdatedAt = g.now
+}
+
+// schedule adds a pending card payment, keeping them sorted by settlement time
+func (g *Generator) schedule(p pending) {
+	i := sort.Search(len(g.pending), func(i int) bool { return g.pending[i].at.After(p.at) })
+	g.pending = append(g.pending, pending{})
+	copy(g.pending[i+1:], g.pending[i:])
+	g.pending[i] = p
+}
+
+// setAmount sets the euro and local amounts, foreign amounts being converted at a fixed rate
+func (g *Generator) setAmount(t *goqonto.Transaction, amount int, c counterparty) {
+	t.AmountCents = amount
+	t.LocalAmountCents = amount
+
+	if rate, ok := eurRates[c.currency]; ok {
+		t.LocalCurrency = c.currency
+		t.LocalAmountCents = fx.FromMajor(fx.ToMajor(amount, "EUR")*rate, c.currency)
+	}
+
+	t.Amount = fx.ToMajor(t.AmountCents, t.Currency)
+	t.LocalAmount = fx.ToMajor(t.LocalAmountCents, t.LocalCurrency)
+}
+
+// setVAT sets the VAT of a transaction. Some transactions have no VAT filled in, and some mix rates.
+func (g *Generator) setVAT(t *goqonto.Transaction, rate float64) {
+	switch r := g.rnd.Float64(); {
+	case r < 0.1:
+		return
+	case r < 0.15 && rate > 0:
+		t.VatRate = vat.RateMultiple
+		t.VatAmountCents = vat.ExpectedVATCents(t.AmountCents, rate) / 2
+	default:
+		t.VatRate = rate
+		t.VatAmountCents = vat.ExpectedVATCents(t.AmountCents, rate)
+	}
+	t.VatAmount = fx.ToMajor(t.VatAmountCents, t.Currency)
+}
+
+// attach adds a PDF receipt to a transaction
+func (g *Generator) attach(t *goqonto.Transaction) {
+	id := g.uuid()
+	name := strings.ToLower(strings.Replace(t.Label, " ", "-", -1))
+	t.AttachmentIds = append(t.AttachmentIds, id)
+	t.Attachments = append(t.Attachments, goqonto.Attachment{
+		ID:              id,
+		CreatedAt:       g.now,
+		FileName:        fmt.Sprintf("%s-%s.pdf", name, g.now.Format("20060102")),
+		FileSize:        fmt.Sprintf("%d", 20000+g.rnd.Intn(300000)),
+		FileContentType: "application/pdf",
+		URL:             "https://qonto.invalid/files/" + id,
+	})
+}
+
+// counterparty returns a random counterparty of a kind
+func (g *Generator) counterparty(k kind) counterparty {
+	var candidates []counterparty
+	for _, c := range counterparties {
+		if c.kind == k {
+			candidates = append(candidates, c)
+		}
+	}
+	return candidates[g.rnd.Intn(len(candidates))]
+}
+
+// label returns the label with a name
+func (g *Generator) label(name string) goqonto.Label {
+	for _, l := range g.labels {
+		if l.Name == name {
+			return l
+		}
+	}
+	return goqonto.Label{}
+}
+
+// uuid returns a random version 4 UUID
+func (g *Generator) uuid() string {
+	b := make([]byte, 16)
+	g.rnd.Read(b)
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
@@ -0,0 +1,170 @@
+package fake
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/pixelfactoryio/goqonto/v2"
+	"github.com/pixelfactoryio/goqonto/v2/iban"
+)
+
+func TestGenerator_reproducible(t *testing.T) {
+	opt := &Options{Seed: 42, Accounts: 2}
+
+	a, b := New(opt), New(opt)
+	if !reflect.DeepEqual(a.Transactions(200), b.Transactions(200)) {
+		t.Errorf("Transactions differ for the same seed")
+	}
+	if !reflect.DeepEqual(a.Organization(), b.Organization()) {
+		t.Errorf("Organization differs for the same seed")
+	}
+
+	c := New(&Options{Seed: 43, Accounts: 2})
+	if reflect.DeepEqual(a.Organization(), c.Organization()) {
+		t.Errorf("Organization is the same for different seeds")
+	}
+}
+
+func TestNew_negativeOptions(t *testing.T) {
+	g := New(&Options{Accounts: -1, Members: -3})
+
+	if got := len(g.Organization().BankAccounts); got != 1 {
+		t.Errorf("bank accounts \n got %v\n want %v\n", got, 1)
+	}
+	if got := len(g.Memberships()); got != 5 {
+		t.Errorf("memberships \n got %v\n want %v\n", got, 5)
+	}
+	if got := len(g.Transactions(50)[g.Organization().BankAccounts[0].IBAN]); got == 0 {
+		t.Errorf("transactions \n got %v\n want some\n", got)
+	}
+}
+
+func TestGenerator_IBAN(t *testing.T) {
+	g := New(nil)
+	for k := 0; k < 100; k++ {
+		s := g.IBAN()
+		if err := iban.Validate(s); err != nil {
+			t.Fatalf("IBAN %s \n got %v\n want %v\n", s, err, nil)
+		}
+	}
+}
+
+func TestGenerator_Labels(t *testing.T) {
+	g := New(nil)
+
+	ids := make(map[string]bool)
+	for _, l := range g.Labels() {
+		if l.ParentID != "" && !ids[l.ParentID] {
+			t.Errorf("label %s parent \n got %v\n want a previous label\n", l.Name, l.ParentID)
+		}
+		ids[l.ID] = true
+	}
+}
+
+func TestGenerator_Next(t *testing.T) {
+	g := New(&Options{Seed: 1, Accounts: 3})
+	org := g.Organization()
+
+	opening := make(map[string]int)
+	for _, ba := range org.BankAccounts {
+		opening[ba.IBAN] = ba.BalanceCents
+	}
+
+	members := make(map[string]bool)
+	for _, m := range g.Memberships() {
+		members[m.ID] = true
+	}
+
+	rates := make(map[float64]bool)
+	for _, r := range Rates {
+		rates[r] = true
+	}
+
+	type state struct {
+		iban string
+		t    goqonto.Transaction
+	}
+	latest := make(map[string]state)
+	var order []string
+
+	var last goqonto.Transaction
+	for k := 0; k < 2000; k++ {
+		iban, tx := g.Next()
+
+		if tx.UpdatedAt.Before(last.UpdatedAt) {
+			t.Fatalf("UpdatedAt \n got %v\n want after %v\n", tx.UpdatedAt, last.UpdatedAt)
+		}
+		last = tx
+
+		if prev, ok := latest[tx.ID]; ok {
+			if prev.t.Status != goqonto.TransactionStatusPending || prev.iban != iban {
+				t.Fatalf("transition of %s \n got %v -> %v\n want pending -> completed|reversed\n",
+					tx.ID, prev.t.Status, tx.Status)
+			}
+			if tx.Status != goqonto.TransactionStatusCompleted && tx.Status != goqonto.TransactionStatusReversed {
+				t.Fatalf("transition of %s \n got %v\n want completed or reversed\n", tx.ID, tx.Status)
+			}
+		} else {
+			order = append(order, tx.ID)
+		}
+		latest[tx.ID] = state{iban, tx}
+
+		if !rates[tx.VatRate] {
+			t.Errorf("VatRate \n got %v\n want one of %v\n", tx.VatRate, Rates)
+		}
+		if len(tx.AttachmentIds) != len(tx.Attachments) {
+			t.Errorf("AttachmentIds \n got %v\n want %v\n", len(tx.AttachmentIds), len(tx.Attachments))
+		}
+		if tx.OperationType == goqonto.TransactionOperationTypeCard && !members[tx.InitiatorID] {
+			t.Errorf("InitiatorID \n got %v\n want a membership ID\n", tx.InitiatorID)
+		}
+	}
+
+	balances := opening
+	authorized := make(map[string]int)
+	for iban, cents := range opening {
+		authorized[iban] = cents
+	}
+
+	statuses := make(map[string]int)
+	for _, id := range order {
+		s := latest[id]
+		statuses[s.t.Status]++
+
+		cents := s.t.AmountCents
+		if s.t.Side == goqonto.TransactionSideDebit {
+			cents = -cents
+		}
+
+		switch s.t.Status {
+		case goqonto.TransactionStatusCompleted:
+			balances[s.iban] += cents
+			authorized[s.iban] += cents
+		case goqonto.TransactionStatusPending:
+			authorized[s.iban] += cents
+		}
+	}
+
+	for _, ba := range g.Organization().BankAccounts {
+		if ba.BalanceCents != balances[ba.IBAN] {
+			t.Errorf("BalanceCents of %s \n got %v\n want %v\n", ba.Name, ba.BalanceCents, balances[ba.IBAN])
+		}
+		if ba.AuthorizedBalanceCents != authorized[ba.IBAN] {
+			t.Errorf("AuthorizedBalanceCents of %s \n got %v\n want %v\n",
+				ba.Name, ba.AuthorizedBalanceCents, authorized[ba.IBAN])
+		}
+		if ba.AuthorizedBalanceCents < 0 {
+			t.Errorf("AuthorizedBalanceCents of %s \n got %v\n want >= 0\n", ba.Name, ba.AuthorizedBalanceCents)
+		}
+	}
+
+	for _, status := range []string{
+		goqonto.TransactionStatusPending,
+		goqonto.TransactionStatusCompleted,
+		goqonto.TransactionStatusReversed,
+	} {
+		if statuses[status] == 0 {
+			t.Errorf("%s transactions \n got %v\n want > 0\n", status, 0)
+		}
+	}
+}
@@ -0,0 +1,478 @@
+// Package fec exports Qonto transactions as a French FEC (Fichier des Écritures Comptables),
+// the double-entry journal required by the tax administration (article A. 47 A-1 of the LPF)
+package fec
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pixelfactoryio/goqonto/v2"
+	"github.com/pixelfactoryio/goqonto/v2/fx"
+)
+
+// Field separators allowed by the FEC specification
+const (
+	SeparatorPipe = '|'
+	SeparatorTab  = '\t'
+)
+
+// dateLayout FEC date layout (AAAAMMJJ)
+const dateLayout = "20060102"
+
+// Header FEC column names, in order
+var Header = []string{
+	"JournalCode",
+	"JournalLib",
+	"EcritureNum",
+	"EcritureDate",
+	"CompteNum",
+	"CompteLib",
+	"CompAuxNum",
+	"CompAuxLib",
+	"PieceRef",
+	"PieceDate",
+	"EcritureLib",
+	"Debit",
+	"Credit",
+	"EcritureLet",
+	"DateLet",
+	"ValidDate",
+	"Montantdevise",
+	"Idevise",
+}
+
+// ErrNoAccount is returned when no account can be found for a transaction
+var ErrNoAccount = errors.New("fec: no account mapped for transaction")
+
+// Account general ledger account, with an optional auxiliary (customer or supplier) account
+type Account struct {
+	Number    string
+	Label     string
+	AuxNumber string
+	AuxLabel  string
+}
+
+// Mapping resolves the accounts used for the entries of a transaction. The counterpart account of
+// a transaction is looked up by label (name or ID), then by category, then by operation type, and
+// falls back to DefaultDebit or DefaultCredit depending on the transaction side.
+type Mapping struct {
+	// Bank account (512xxx) of the Qonto bank account.
+	Bank Account
+
+	// Deductible VAT account (44566x) used for debits.
+	DeductibleVAT Account
+
+	// Collected VAT account (44571x) used for credits.
+	CollectedVAT Account
+
+	Labels         map[string]Account
+	Categories     map[string]Account
+	OperationTypes map[string]Account
+
+	DefaultDebit  Account
+	DefaultCredit Account
+}
+
+// DefaultMapping French chart of accounts defaults: bank 512000, VAT 445660/445710,
+// suspense account 471000 for unmapped transactions
+var DefaultMapping = Mapping{
+	Bank:          Account{Number: "512000", Label: "Banque Qonto"},
+	DeductibleVAT: Account{Number: "445660", Label: "TVA déductible sur autres biens et services"},
+	CollectedVAT:  Account{Number: "445710", Label: "TVA collectée"},
+	OperationTypes: map[string]Account{
+		"qonto_fee": {Number: "627000", Label: "Services bancaires et assimilés"},
+	},
+	DefaultDebit:  Account{Number: "471000", Label: "Compte d'attente"},
+	DefaultCredit: Account{Number: "471000", Label: "Compte d'attente"},
+}
+
+// counterpart returns the counterpart account of a transaction
+func (m *Mapping) counterpart(t goqonto.Transaction) (Account, bool) {
+	for _, label := range t.Labels {
+		if a, ok := m.Labels[label.ID]; ok {
+			return a, true
+		}
+		if a, ok := m.Labels[label.Name]; ok {
+			return a, true
+		}
+	}
+
+	for _, id := range t.LabelIds {
+		if a, ok := m.Labels[id]; ok {
+			return a, true
+		}
+	}
+
+	if a, ok := m.Categories[t.Category]; ok && t.Category != "" {
+		return a, true
+	}
+
+	if a, ok := m.OperationTypes[t.OperationType]; ok {
+		return a, true
+	}
+
+	a := m.DefaultCredit
+	if t.Side == goqonto.TransactionSideDebit {
+		a = m.DefaultDebit
+	}
+
+	return a, a.Number != ""
+}
+
+// Options FEC export options
+type Options struct {
+	// Account mapping. Defaults to DefaultMapping.
+	Mapping *Mapping
+
+	// Journal code and label. Default to BQ and Banque.
+	JournalCode  string
+	JournalLabel string
+
+	// Number of the first entry. Defaults to 1.
+	FirstEntryNumber int
+
+	// Location in which dates are formatted. Defaults to UTC.
+	Location *time.Location
+}
+
+// Line FEC line. Amounts are in cents, CurrencyAmountCents is in the minor unit of Currency.
+type Line struct {
+	JournalCode         string
+	JournalLabel        string
+	EntryNumber         string
+	EntryDate           time.Time
+	AccountNumber       string
+	AccountLabel        string
+	AuxAccountNumber    string
+	AuxAccountLabel     string
+	PieceRef            string
+	PieceDate           time.Time
+	Label               string
+	DebitCents          int
+	CreditCents         int
+	Lettering           string
+	LetteringDate       time.Time
+	ValidationDate      time.Time
+	CurrencyAmountCents int
+	Currency            string
+}
+
+// Build converts the completed transactions into balanced FEC entries, in chronological order.
+// Each transaction produces a bank line, a counterpart line for the amount excluding VAT and,
+// when VatAmountCents is set, a VAT line. Zero amount transactions and lines are skipped.
+func Build(transactions []goqonto.Transaction, opt *Options) ([]Line, error) {
+	if opt == nil {
+		opt = &Options{}
+	}
+
+	mapping := opt.Mapping
+	if mapping == nil {
+		mapping = &DefaultMapping
+	}
+
+	journalCode := opt.JournalCode
+	if journalCode == "" {
+		journalCode = "BQ"
+	}
+
+	journalLabel := opt.JournalLabel
+	if journalLabel == "" {
+		journalLabel = "Banque"
+	}
+
+	number := opt.FirstEntryNumber
+	if number == 0 {
+		number = 1
+	}
+
+	loc := opt.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+
+	var completed []goqonto.Transaction
+	for _, t := range transactions {
+		if t.Status == goqonto.TransactionStatusCompleted && t.AmountCents != 0 {
+			completed = append(completed, t)
+		}
+	}
+
+	sort.SliceStable(completed, func(i, j int) bool {
+		return completed[i].SettledAt.Before(completed[j].SettledAt)
+	})
+
+	var lines []Line
+	for _, t := range completed {
+		counterpart, ok := mapping.counterpart(t)
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ErrNoAccount, t.ID)
+		}
+
+		vat := t.VatAmountCents
+		if vat < 0 || vat > t.AmountCents {
+			return nil, fmt.Errorf("fec: transaction %s VAT amount %d is not within its amount %d", t.ID, vat, t.AmountCents)
+		}
+
+		base := Line{
+			JournalCode:    journalCode,
+			JournalLabel:   journalLabel,
+			EntryNumber:    strconv.Itoa(number),
+			EntryDate:      t.SettledAt.In(loc),
+			PieceRef:       pieceRef(t),
+			PieceDate:      pieceDate(t).In(loc),
+			Label:          entryLabel(t),
+			ValidationDate: t.SettledAt.In(loc),
+		}
+		number++
+
+		debit := t.Side == goqonto.TransactionSideDebit
+
+		bank := base.with(mapping.Bank)
+		if t.LocalCurrency != "" && t.LocalCurrency != t.Currency {
+			bank.CurrencyAmountCents = t.LocalAmountCents
+			bank.Currency = t.LocalCurrency
+		}
+
+		other := base.with(counterpart)
+
+		if debit {
+			bank.CreditCents = t.AmountCents
+			other.DebitCents = t.AmountCents - vat
+		} else {
+			bank.DebitCents = t.AmountCents
+			other.CreditCents = t.AmountCents - vat
+		}
+
+		lines = append(lines, bank)
+		if vat != t.AmountCents {
+			lines = append(lines, other)
+		}
+
+		if vat == 0 {
+			continue
+		}
+
+		if debit {
+			line := base.with(mapping.DeductibleVAT)
+			line.DebitCents = vat
+			lines = append(lines, line)
+		} else {
+			line := base.with(mapping.CollectedVAT)
+			line.CreditCents = vat
+			lines = append(lines, line)
+		}
+	}
+
+	return lines, nil
+}
+
+// with returns a copy of the line booked on account a
+func (l Line) with(a Account) Line {
+	l.AccountNumber = a.Number
+	l.AccountLabel = a.Label
+	l.AuxAccountNumber = a.AuxNumber
+	l.AuxAccountLabel = a.AuxLabel
+	return l
+}
+
+// pieceRef returns the attachment IDs of the transaction, or its transaction ID if it has none
+func pieceRef(t goqonto.Transaction) string {
+	if len(t.AttachmentIds) > 0 {
+		return strings.Join(t.AttachmentIds, " ")
+	}
+
+	ids := make([]string, 0, len(t.Attachments))
+	for _, a := range t.Attachments {
+		ids = append(ids, a.ID)
+	}
+	if len(ids) > 0 {
+		return strings.Join(ids, " ")
+	}
+
+	if t.TransactionID != "" {
+		return t.TransactionID
+	}
+	return t.ID
+}
+
+func pieceDate(t goqonto.Transaction) time.Time {
+	if !t.EmittedAt.IsZero() {
+		return t.EmittedAt
+	}
+	return t.SettledAt
+}
+
+func entryLabel(t goqonto.Transaction) string {
+	if t.Reference != "" {
+		return t.Label + " - " + t.Reference
+	}
+	return t.Label
+}
+
+// ValidationError lists the problems found in FEC lines
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("fec: %d validation problem(s): %s", len(e.Problems), strings.Join(e.Problems, "; "))
+}
+
+// Validate checks that mandatory fields are filled, that each line is either a debit or a credit and
+// that every entry (journal code and entry number) is balanced. It returns a *ValidationError.
+func Validate(lines []Line) error {
+	var problems []string
+
+	type key struct{ journal, number string }
+	balances := make(map[key]int)
+	var keys []key
+
+	for i, l := range lines {
+		required := map[string]string{
+			"JournalCode": l.JournalCode,
+			"JournalLib":  l.JournalLabel,
+			"EcritureNum": l.EntryNumber,
+			"CompteNum":   l.AccountNumber,
+			"CompteLib":   l.AccountLabel,
+			"PieceRef":    l.PieceRef,
+			"EcritureLib": l.Label,
+		}
+		for _, name := range Header {
+			if v, ok := required[name]; ok && v == "" {
+				problems = append(problems, fmt.Sprintf("line %d: %s is empty", i+1, name))
+			}
+		}
+
+		dates := []struct {
+			name string
+			t    time.Time
+		}{
+			{"EcritureDate", l.EntryDate},
+			{"PieceDate", l.PieceDate},
+			{"ValidDate", l.ValidationDate},
+		}
+		for _, d := range dates {
+			if d.t.IsZero() {
+				problems = append(problems, fmt.Sprintf("line %d: %s is empty", i+1, d.name))
+			}
+		}
+
+		if l.DebitCents < 0 || l.CreditCents < 0 {
+			problems = append(problems, fmt.Sprintf("line %d: negative amount", i+1))
+		}
+		if (l.DebitCents == 0) == (l.CreditCents == 0) {
+			problems = append(problems, fmt.Sprintf("line %d: expected either a debit or a credit", i+1))
+		}
+		if (l.AuxAccountNumber == "") != (l.AuxAccountLabel == "") {
+			problems = append(problems, fmt.Sprintf("line %d: CompAuxNum and CompAuxLib must be both set or empty", i+1))
+		}
+
+		k := key{l.JournalCode, l.EntryNumber}
+		if _, ok := balances[k]; !ok {
+			keys = append(keys, k)
+		}
+		balances[k] += l.DebitCents - l.CreditCents
+	}
+
+	for _, k := range keys {
+		if b := balances[k]; b != 0 {
+			problems = append(problems, fmt.Sprintf("entry %s %s is not balanced (%s)", k.journal, k.number, formatAmount(b)))
+		}
+	}
+
+	if len(problems) > 0 {
+		return &ValidationError{Problems: problems}
+	}
+
+	return nil
+}
+
+// Write validates the lines and writes them to w with the FEC header, separated by sep
+// (SeparatorPipe or SeparatorTab), with CRLF line endings
+func Write(w io.Writer, lines []Line, sep rune) error {
+	if sep != SeparatorPipe && sep != SeparatorTab {
+		return fmt.Errorf("fec: invalid separator %q", sep)
+	}
+
+	if err := Validate(lines); err != nil {
+		return err
+	}
+
+	bw := bufio.NewWriter(w)
+	s := string(sep)
+
+	writeRecord(bw, Header, s)
+	for _, l := range lines {
+		writeRecord(bw, l.record(), s)
+	}
+
+	return bw.Flush()
+}
+
+// FileName returns the FEC file name for a company SIREN and a fiscal year closing date
+func FileName(siren string, closing time.Time) string {
+	return fmt.Sprintf("%sFEC%s.txt", siren, closing.Format(dateLayout))
+}
+
+func (l Line) record() []string {
+	currencyAmount := ""
+	if l.Currency != "" {
+		currencyAmount = strings.Replace(fx.Format(l.CurrencyAmountCents, l.Currency), ".", ",", 1)
+	}
+
+	return []string{
+		l.JournalCode,
+		l.JournalLabel,
+		l.EntryNumber,
+		formatDate(l.EntryDate),
+		l.AccountNumber,
+		l.AccountLabel,
+		l.AuxAccountNumber,
+		l.AuxAccountLabel,
+		l.PieceRef,
+		formatDate(l.PieceDate),
+		l.Label,
+		formatAmount(l.DebitCents),
+		formatAmount(l.CreditCents),
+		l.Lettering,
+		formatDate(l.LetteringDate),
+		formatDate(l.ValidationDate),
+		currencyAmount,
+		l.Currency,
+	}
+}
+
+// writeRecord writes a FEC line, errors are reported by the final Flush of the bufio.Writer
+func writeRecord(w *bufio.Writer, fields []string, sep string) {
+	// Fields cannot contain the separator nor line breaks.
+	r := strings.NewReplacer(sep, " ", "\r\n", " ", "\n", " ", "\r", " ")
+	cleaned := make([]string, len(fields))
+	for i, f := range fields {
+		cleaned[i] = r.Replace(f)
+	}
+
+	_, _ = w.WriteString(strings.Join(cleaned, sep) + "\r\n")
+}
+
+func formatDate(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(dateLayout)
+}
+
+// formatAmount formats cents with a decimal comma and no thousands separator
+func formatAmount(cents int) string {
+	sign := ""
+	if cents < 0 {
+		sign = "-"
+		cents = -cents
+	}
+	return fmt.Sprintf("%s%d,%02d", sign, cents/100, cents%100)
+}
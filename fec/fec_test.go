@@ -0,0 +1,302 @@
+package fec
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pixelfactoryio/goqonto/v2"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+func testGolden(t *testing.T, name string, got []byte) {
+	t.Helper()
+
+	path := filepath.Join("testdata", name)
+	if *update {
+		if err := ioutil.WriteFile(path, got, 0644); err != nil {
+			t.Fatalf("Unable to update golden file %s: %v", path, err)
+		}
+	}
+
+	want, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Unable to read golden file %s: %v", path, err)
+	}
+
+	if string(got) != string(want) {
+		t.Errorf("%s \n got %s\n want %s\n", name, got, want)
+	}
+}
+
+func mustParse(value string) time.Time {
+	v, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+var (
+	mapping = &Mapping{
+		Bank:          DefaultMapping.Bank,
+		DeductibleVAT: DefaultMapping.DeductibleVAT,
+		CollectedVAT:  DefaultMapping.CollectedVAT,
+		Labels: map[string]Account{
+			"restaurant": {Number: "625700", Label: "Réceptions"},
+		},
+		Categories: map[string]Account{
+			"hotel_and_lodging": {Number: "625600", Label: "Missions"},
+		},
+		OperationTypes: map[string]Account{
+			"income":    {Number: "411000", Label: "Clients", AuxNumber: "C0001", AuxLabel: "ACME Corp"},
+			"qonto_fee": {Number: "627000", Label: "Services bancaires"},
+		},
+		DefaultDebit: Account{Number: "471000", Label: "Compte d'attente"},
+	}
+
+	transactions = []goqonto.Transaction{
+		{
+			ID:             "t2",
+			TransactionID:  "croissant-bank-account-1-transaction-2",
+			AmountCents:    12000,
+			Currency:       "EUR",
+			LocalCurrency:  "EUR",
+			Side:           goqonto.TransactionSideCredit,
+			OperationType:  goqonto.TransactionOperationTypeIncome,
+			Label:          "ACME Corp",
+			Reference:      "Invoice|42",
+			Status:         goqonto.TransactionStatusCompleted,
+			EmittedAt:      mustParse("2021-03-02T09:00:00Z"),
+			SettledAt:      mustParse("2021-03-02T10:00:00Z"),
+			VatAmountCents: 2000,
+			VatRate:        20,
+		},
+		{
+			ID:               "t1",
+			TransactionID:    "croissant-bank-account-1-transaction-1",
+			AmountCents:      5500,
+			Currency:         "EUR",
+			LocalCurrency:    "USD",
+			LocalAmountCents: 6600,
+			Side:             goqonto.TransactionSideDebit,
+			OperationType:    goqonto.TransactionOperationTypeCard,
+			Label:            "Le Bistrot",
+			Status:           goqonto.TransactionStatusCompleted,
+			EmittedAt:        mustParse("2021-03-01T12:00:00Z"),
+			SettledAt:        mustParse("2021-03-01T13:00:00Z"),
+			VatAmountCents:   500,
+			VatRate:          10,
+			AttachmentIds:    []string{"a1", "a2"},
+			Labels:           []goqonto.Label{{ID: "l1", Name: "restaurant"}},
+		},
+		{
+			ID:            "t3",
+			TransactionID: "croissant-bank-account-1-transaction-3",
+			AmountCents:   15000,
+			Currency:      "EUR",
+			Side:          goqonto.TransactionSideDebit,
+			OperationType: goqonto.TransactionOperationTypeCard,
+			Category:      "hotel_and_lodging",
+			Label:         "Hotel",
+			Status:        goqonto.TransactionStatusCompleted,
+			EmittedAt:     mustParse("2021-03-03T12:00:00Z"),
+			SettledAt:     mustParse("2021-03-03T13:00:00Z"),
+			Attachments:   []goqonto.Attachment{{ID: "a3"}},
+		},
+		{
+			ID:            "t4",
+			TransactionID: "croissant-bank-account-1-transaction-4",
+			AmountCents:   900,
+			Currency:      "EUR",
+			Side:          goqonto.TransactionSideDebit,
+			OperationType: "qonto_fee",
+			Label:         "Qonto",
+			Status:        goqonto.TransactionStatusCompleted,
+			EmittedAt:     mustParse("2021-03-04T00:00:00Z"),
+			SettledAt:     mustParse("2021-03-04T00:00:00Z"),
+		},
+		{
+			ID:            "t5",
+			AmountCents:   1000,
+			Side:          goqonto.TransactionSideDebit,
+			OperationType: goqonto.TransactionOperationTypeCard,
+			Label:         "Pending",
+			Status:        goqonto.TransactionStatusPending,
+			EmittedAt:     mustParse("2021-03-05T00:00:00Z"),
+		},
+	}
+)
+
+func TestBuild(t *testing.T) {
+	lines, err := Build(transactions, &Options{Mapping: mapping})
+	if err != nil {
+		t.Fatalf("Build returned error: %v", err)
+	}
+
+	var got []string
+	for _, l := range lines {
+		got = append(got, strings.Join([]string{
+			l.EntryNumber, l.AccountNumber, l.AuxAccountNumber, formatAmount(l.DebitCents), formatAmount(l.CreditCents),
+		}, " "))
+	}
+
+	want := []string{
+		"1 512000  0,00 55,00",
+		"1 625700  50,00 0,00",
+		"1 445660  5,00 0,00",
+		"2 512000  120,00 0,00",
+		"2 411000 C0001 0,00 100,00",
+		"2 445710  0,00 20,00",
+		"3 512000  0,00 150,00",
+		"3 625600  150,00 0,00",
+		"4 512000  0,00 9,00",
+		"4 627000  9,00 0,00",
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Build \n got %v\n want %v\n", got, want)
+	}
+
+	if err := Validate(lines); err != nil {
+		t.Errorf("Validate returned error: %v", err)
+	}
+
+	if l := lines[0]; l.PieceRef != "a1 a2" || l.Currency != "USD" || l.CurrencyAmountCents != 6600 {
+		t.Errorf("Build bank line got piece %s and currency amount %d %s", l.PieceRef, l.CurrencyAmountCents, l.Currency)
+	}
+
+	if l := lines[6]; l.PieceRef != "a3" {
+		t.Errorf("Build piece reference got %s, want a3", l.PieceRef)
+	}
+
+	if l := lines[8]; l.PieceRef != "croissant-bank-account-1-transaction-4" {
+		t.Errorf("Build piece reference got %s, want the transaction ID", l.PieceRef)
+	}
+}
+
+func TestBuild_errors(t *testing.T) {
+	noDefault := &Mapping{Bank: DefaultMapping.Bank}
+	_, err := Build(transactions, &Options{Mapping: noDefault})
+	if !errors.Is(err, ErrNoAccount) {
+		t.Errorf("Build error got %v, want %v", err, ErrNoAccount)
+	}
+
+	invalidVAT := []goqonto.Transaction{transactions[0]}
+	invalidVAT[0].VatAmountCents = 50000
+	if _, err := Build(invalidVAT, nil); err == nil {
+		t.Errorf("Expected error to be returned")
+	}
+}
+
+func TestBuild_zeroAmounts(t *testing.T) {
+	zero := transactions[0]
+	zero.ID = "zero"
+	zero.AmountCents, zero.VatAmountCents = 0, 0
+
+	vatOnly := transactions[1]
+	vatOnly.ID = "vat-only"
+	vatOnly.AmountCents, vatOnly.VatAmountCents = 2000, 2000
+
+	lines, err := Build([]goqonto.Transaction{zero, vatOnly}, &Options{Mapping: mapping})
+	if err != nil {
+		t.Fatalf("Build returned error: %v", err)
+	}
+
+	var got []string
+	for _, l := range lines {
+		got = append(got, strings.Join([]string{
+			l.EntryNumber, l.AccountNumber, formatAmount(l.DebitCents), formatAmount(l.CreditCents),
+		}, " "))
+	}
+
+	want := []string{"1 512000 0,00 20,00", "1 445660 20,00 0,00"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Build \n got %v\n want %v\n", got, want)
+	}
+
+	if err := Validate(lines); err != nil {
+		t.Errorf("Validate returned error: %v", err)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	lines, err := Build(transactions[:2], &Options{Mapping: mapping})
+	if err != nil {
+		t.Fatalf("Build returned error: %v", err)
+	}
+
+	lines[1].DebitCents++
+	lines[2].AccountLabel = ""
+	lines[3].CreditCents = 100
+	lines[4].AuxAccountLabel = ""
+
+	err = Validate(lines)
+
+	verr, ok := err.(*ValidationError)
+	if !ok {
+		t.Fatalf("Validate error got %v, want *ValidationError", err)
+	}
+
+	want := []string{
+		"line 3: CompteLib is empty",
+		"line 4: expected either a debit or a credit",
+		"line 5: CompAuxNum and CompAuxLib must be both set or empty",
+		"entry BQ 1 is not balanced (0,01)",
+		"entry BQ 2 is not balanced (-1,00)",
+	}
+	if !reflect.DeepEqual(verr.Problems, want) {
+		t.Errorf("Validate \n got %v\n want %v\n", verr.Problems, want)
+	}
+}
+
+func TestWrite(t *testing.T) {
+	paris := time.FixedZone("CET", 3600)
+	lines, err := Build(transactions, &Options{Mapping: mapping, Location: paris})
+	if err != nil {
+		t.Fatalf("Build returned error: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := Write(&buf, lines, SeparatorPipe); err != nil {
+		t.Fatalf("Write returned error: %v", err)
+	}
+	testGolden(t, "fec_pipe.txt.golden", buf.Bytes())
+
+	buf.Reset()
+	if err := Write(&buf, lines, SeparatorTab); err != nil {
+		t.Fatalf("Write returned error: %v", err)
+	}
+	testGolden(t, "fec_tab.txt.golden", buf.Bytes())
+
+	for i, line := range strings.Split(strings.TrimSuffix(buf.String(), "\r\n"), "\r\n") {
+		if got := len(strings.Split(line, "\t")); got != len(Header) {
+			t.Errorf("line %d has %d fields, want %d", i+1, got, len(Header))
+		}
+	}
+}
+
+func TestWrite_errors(t *testing.T) {
+	if err := Write(&bytes.Buffer{}, nil, ';'); err == nil {
+		t.Errorf("Expected error to be returned for an invalid separator")
+	}
+
+	unbalanced := []Line{{JournalCode: "BQ", EntryNumber: "1", DebitCents: 100}}
+	if err := Write(&bytes.Buffer{}, unbalanced, SeparatorPipe); err == nil {
+		t.Errorf("Expected error to be returned for invalid lines")
+	}
+}
+
+func TestFileName(t *testing.T) {
+	got := FileName("123456789", time.Date(2021, 12, 31, 0, 0, 0, 0, time.UTC))
+	if want := "123456789FEC20211231.txt"; got != want {
+		t.Errorf("FileName got %s, want %s", got, want)
+	}
+}
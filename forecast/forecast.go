@@ -0,0 +1,330 @@
+// Package forecast projects the weekly cash position of an organization from its balances, its
+// transaction history and its scheduled transfers
+package forecast
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/pixelfactoryio/goqonto/v2"
+	"github.com/pixelfactoryio/goqonto/v2/recurring"
+)
+
+// dateLayout layout of transfers scheduled dates
+const dateLayout = "2006-01-02"
+
+// LabelScheduledTransfers is the breakdown label of scheduled transfers.
+const LabelScheduledTransfers = "scheduled transfers"
+
+// ErrNoBankAccount is returned when no open bank account holds the forecast currency
+var ErrNoBankAccount = errors.New("forecast: no open bank account in currency")
+
+// Options forecast options
+type Options struct {
+	// Currency of the forecast. Defaults to EUR.
+	Currency string
+
+	// Number of projected weeks. Defaults to 13 when not positive.
+	Weeks int
+
+	// Number of past weeks used to learn non recurring flows. Defaults to 13 when not positive.
+	LookbackWeeks int
+
+	// Z-score of the confidence range. Defaults to 1.28, an 80% range.
+	Z float64
+
+	// Location in which weeks start. Defaults to UTC.
+	Location *time.Location
+
+	// Now returns the current time, the first week starts at the beginning of its day.
+	// Defaults to time.Now.
+	Now func() time.Time
+
+	// Transfers scheduled in the future, projected at their scheduled date.
+	ScheduledTransfers []goqonto.Transfer
+}
+
+// Flow inflows and outflows of a label
+type Flow struct {
+	Label        string
+	InflowCents  int
+	OutflowCents int
+}
+
+// Week projected cash position at the end of a week
+type Week struct {
+	Start time.Time
+
+	InflowCents  int
+	OutflowCents int
+
+	// Projected balance and confidence range.
+	BalanceCents int
+	LowCents     int
+	HighCents    int
+
+	// Flows by label, sorted by label.
+	Breakdown []Flow
+}
+
+// Forecast weekly cash forecast
+type Forecast struct {
+	Currency          string
+	StartBalanceCents int
+	Weeks             []Week
+}
+
+// flows weekly flows of a label, indexed by week
+type flows struct {
+	in, out []float64
+}
+
+// New projects the balance of the open bank accounts in the forecast currency week by week. Week
+// flows are made of:
+//
+// - pending transactions, in the first week;
+// - pending and processing scheduled transfers, in the week of their scheduled date;
+// - recurring payments detected in history, at their expected dates;
+// - the average weekly flows of every label over the lookback weeks, excluding recurring payments.
+//
+// The confidence range widens with the variance of the average weekly flows.
+func New(accounts []goqonto.BankAccount, history []goqonto.Transaction, opt *Options) (*Forecast, error) {
+	o := Options{}
+	if opt != nil {
+		o = *opt
+	}
+	if o.Currency == "" {
+		o.Currency = "EUR"
+	}
+	if o.Weeks <= 0 {
+		o.Weeks = 13
+	}
+	if o.LookbackWeeks <= 0 {
+		o.LookbackWeeks = 13
+	}
+	if o.Z == 0 {
+		o.Z = 1.28
+	}
+	if o.Location == nil {
+		o.Location = time.UTC
+	}
+	if o.Now == nil {
+		o.Now = time.Now
+	}
+
+	f := &Forecast{Currency: o.Currency}
+
+	found := false
+	for _, a := range accounts {
+		if a.Currency == o.Currency && a.Status != goqonto.BankAccountStatusClosed {
+			f.StartBalanceCents += a.BalanceCents
+			found = true
+		}
+	}
+	if !found {
+		return nil, ErrNoBankAccount
+	}
+
+	now := o.Now().In(o.Location)
+	start := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, o.Location)
+
+	projected := make([]map[string]*Flow, o.Weeks)
+	for k := range projected {
+		projected[k] = make(map[string]*Flow)
+	}
+
+	week := func(t time.Time) int {
+		if t.Before(start) {
+			return 0
+		}
+		return weekIndex(start, t)
+	}
+
+	add := func(k int, label string, side string, cents int) {
+		if k < 0 || k >= o.Weeks {
+			return
+		}
+		flow, ok := projected[k][label]
+		if !ok {
+			flow = &Flow{Label: label}
+			projected[k][label] = flow
+		}
+		if side == goqonto.TransactionSideDebit {
+			flow.OutflowCents += cents
+		} else {
+			flow.InflowCents += cents
+		}
+	}
+
+	var past []goqonto.Transaction
+	for _, t := range history {
+		if t.Currency != o.Currency {
+			continue
+		}
+		switch t.Status {
+		case goqonto.TransactionStatusPending:
+			add(0, label(t), t.Side, t.AmountCents)
+		case goqonto.TransactionStatusCompleted:
+			past = append(past, t)
+		}
+	}
+
+	for _, tr := range o.ScheduledTransfers {
+		if tr.Currency != o.Currency || (tr.Status != goqonto.TransferStatusPending &&
+			tr.Status != goqonto.TransferStatusProcessing) {
+			continue
+		}
+		d, err := time.ParseInLocation(dateLayout, tr.ScheduledDate, o.Location)
+		if err != nil {
+			d = start
+		}
+		add(week(d), LabelScheduledTransfers, goqonto.TransactionSideDebit, tr.AmountCents)
+	}
+
+	end := start.AddDate(0, 0, 7*o.Weeks)
+	recurrent := make(map[string]bool)
+	for _, sub := range recurring.Detect(past, &recurring.Options{Now: o.Now}) {
+		for _, t := range sub.Transactions {
+			recurrent[key(t)] = true
+		}
+		if sub.Missed {
+			continue
+		}
+
+		last := sub.Transactions[len(sub.Transactions)-1]
+		for d := sub.NextDate; d.Before(end); d = sub.Period.Next(d) {
+			add(week(d), label(last), goqonto.TransactionSideDebit, sub.NextAmountCents)
+		}
+	}
+
+	averages, variance := averageFlows(past, recurrent, start, o.LookbackWeeks)
+	for k := range projected {
+		for l, avg := range averages {
+			add(k, l, goqonto.TransactionSideDebit, avg.OutflowCents)
+			add(k, l, goqonto.TransactionSideCredit, avg.InflowCents)
+		}
+	}
+
+	balance := f.StartBalanceCents
+	for k, labels := range projected {
+		w := Week{Start: start.AddDate(0, 0, 7*k)}
+
+		for _, flow := range labels {
+			if flow.InflowCents == 0 && flow.OutflowCents == 0 {
+				continue
+			}
+			w.InflowCents += flow.InflowCents
+			w.OutflowCents += flow.OutflowCents
+			w.Breakdown = append(w.Breakdown, *flow)
+		}
+
+		sort.Slice(w.Breakdown, func(i, j int) bool {
+			return w.Breakdown[i].Label < w.Breakdown[j].Label
+		})
+
+		balance += w.InflowCents - w.OutflowCents
+		spread := int(math.Round(o.Z * math.Sqrt(variance*float64(k+1))))
+
+		w.BalanceCents = balance
+		w.LowCents = balance - spread
+		w.HighCents = balance + spread
+
+		f.Weeks = append(f.Weeks, w)
+	}
+
+	return f, nil
+}
+
+// weekIndex returns the number of whole weeks between the days of from and t in the location of from.
+// Days are counted on the calendar, as weeks spanning a DST change are not 168 hours long.
+func weekIndex(from, t time.Time) int {
+	loc := from.Location()
+	t = t.In(loc)
+
+	d1 := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, time.UTC)
+	d2 := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+
+	return int(d2.Sub(d1).Hours()/24) / 7
+}
+
+// averageFlows returns the average weekly flows of every label over the weeks preceding start,
+// excluding recurrent transactions, and the variance of the weekly net flow
+func averageFlows(past []goqonto.Transaction, recurrent map[string]bool, start time.Time,
+	weeks int) (map[string]Flow, float64) {
+	from := start.AddDate(0, 0, -7*weeks)
+
+	byLabel := make(map[string]*flows)
+	for _, t := range past {
+		d := t.SettledAt
+		if recurrent[key(t)] || d.Before(from) || !d.Before(start) {
+			continue
+		}
+
+		l := label(t)
+		f, ok := byLabel[l]
+		if !ok {
+			f = &flows{in: make([]float64, weeks), out: make([]float64, weeks)}
+			byLabel[l] = f
+		}
+
+		k := weekIndex(from, d)
+		if t.Side == goqonto.TransactionSideDebit {
+			f.out[k] += float64(t.AmountCents)
+		} else {
+			f.in[k] += float64(t.AmountCents)
+		}
+	}
+
+	averages := make(map[string]Flow)
+	var variance float64
+	for l, f := range byLabel {
+		averages[l] = Flow{
+			Label:        l,
+			InflowCents:  int(math.Round(mean(f.in))),
+			OutflowCents: int(math.Round(mean(f.out))),
+		}
+
+		net := make([]float64, weeks)
+		for k := range net {
+			net[k] = f.in[k] - f.out[k]
+		}
+		variance += sampleVariance(net)
+	}
+
+	return averages, variance
+}
+
+// label returns the name of the first label of a transaction, or an empty string
+func label(t goqonto.Transaction) string {
+	if len(t.Labels) == 0 {
+		return ""
+	}
+	return t.Labels[0].Name
+}
+
+func key(t goqonto.Transaction) string {
+	return t.ID + "/" + t.TransactionID + "/" + t.SettledAt.String()
+}
+
+func mean(values []float64) float64 {
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	return sum / float64(len(values))
+}
+
+func sampleVariance(values []float64) float64 {
+	if len(values) < 2 {
+		return 0
+	}
+
+	m := mean(values)
+	var sum float64
+	for _, v := range values {
+		sum += (v - m) * (v - m)
+	}
+	return sum / float64(len(values)-1)
+}
@@ -0,0 +1,182 @@
+package forecast
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/pixelfactoryio/goqonto/v2"
+)
+
+var now = time.Date(2020, time.July, 1, 10, 0, 0, 0, time.UTC)
+
+func tx(label, side string, cents int, at time.Time) goqonto.Transaction {
+	return goqonto.Transaction{
+		TransactionID: label + at.Format("20060102"),
+		Label:         label,
+		Labels:        []goqonto.Label{{Name: label}},
+		Side:          side,
+		AmountCents:   cents,
+		Currency:      "EUR",
+		Status:        goqonto.TransactionStatusCompleted,
+		EmittedAt:     at,
+		SettledAt:     at,
+	}
+}
+
+func testHistory() []goqonto.Transaction {
+	var history []goqonto.Transaction
+
+	// Steady weekly sales over the lookback period.
+	for k := 1; k <= 13; k++ {
+		history = append(history, tx("sales", goqonto.TransactionSideCredit, 10000, now.AddDate(0, 0, -7*k)))
+	}
+
+	// Monthly subscription, next payment on July 15.
+	for m := 1; m <= 4; m++ {
+		history = append(history, tx("saas", goqonto.TransactionSideDebit, 5000,
+			time.Date(2020, time.Month(2+m), 15, 9, 0, 0, 0, time.UTC)))
+	}
+
+	// Irregular supplies, once every other week.
+	for k := 2; k <= 13; k += 2 {
+		history = append(history, tx("supplies", goqonto.TransactionSideDebit, 1300, now.AddDate(0, 0, -7*k)))
+	}
+
+	pending := tx("travel", goqonto.TransactionSideDebit, 2000, now.Add(-time.Hour))
+	pending.Status = goqonto.TransactionStatusPending
+	pending.SettledAt = time.Time{}
+
+	return append(history, pending)
+}
+
+func TestNew(t *testing.T) {
+	accounts := []goqonto.BankAccount{
+		{Currency: "EUR", BalanceCents: 100000, Status: goqonto.BankAccountStatusActive},
+		{Currency: "EUR", BalanceCents: 50000, Status: goqonto.BankAccountStatusClosed},
+		{Currency: "USD", BalanceCents: 70000, Status: goqonto.BankAccountStatusActive},
+	}
+
+	transfers := []goqonto.Transfer{
+		{Status: goqonto.TransferStatusPending, Currency: "EUR", AmountCents: 30000, ScheduledDate: "2020-07-16"},
+		{Status: goqonto.TransferStatusCanceled, Currency: "EUR", AmountCents: 99999, ScheduledDate: "2020-07-16"},
+	}
+
+	f, err := New(accounts, testHistory(), &Options{
+		Weeks:              3,
+		Now:                func() time.Time { return now },
+		ScheduledTransfers: transfers,
+	})
+	if err != nil {
+		t.Fatalf("New returned error: %v", err)
+	}
+
+	if f.StartBalanceCents != 100000 || len(f.Weeks) != 3 {
+		t.Fatalf("New \n got %d, %d weeks\n want 100000, 3 weeks\n", f.StartBalanceCents, len(f.Weeks))
+	}
+
+	want := []Flow{
+		{Label: "sales", InflowCents: 10000},
+		{Label: "supplies", OutflowCents: 600},
+		{Label: "travel", OutflowCents: 2000},
+	}
+	if !reflect.DeepEqual(f.Weeks[0].Breakdown, want) {
+		t.Errorf("New first week breakdown \n got %v\n want %v\n", f.Weeks[0].Breakdown, want)
+	}
+
+	want = []Flow{
+		{Label: "saas", OutflowCents: 5000},
+		{Label: "sales", InflowCents: 10000},
+		{Label: LabelScheduledTransfers, OutflowCents: 30000},
+		{Label: "supplies", OutflowCents: 600},
+	}
+	if !reflect.DeepEqual(f.Weeks[2].Breakdown, want) {
+		t.Errorf("New third week breakdown \n got %v\n want %v\n", f.Weeks[2].Breakdown, want)
+	}
+
+	balances := []int{f.Weeks[0].BalanceCents, f.Weeks[1].BalanceCents, f.Weeks[2].BalanceCents}
+	if !reflect.DeepEqual(balances, []int{107400, 116800, 91200}) {
+		t.Errorf("New balances \n got %v\n want [107400 116800 91200]\n", balances)
+	}
+
+	for k, w := range f.Weeks {
+		if w.LowCents >= w.BalanceCents || w.HighCents <= w.BalanceCents {
+			t.Errorf("New week %d range \n got [%d, %d]\n want around %d\n", k, w.LowCents, w.HighCents, w.BalanceCents)
+		}
+		if k > 0 && w.HighCents-w.LowCents <= f.Weeks[k-1].HighCents-f.Weeks[k-1].LowCents {
+			t.Errorf("New week %d range does not widen", k)
+		}
+	}
+}
+
+func TestNewNoBankAccount(t *testing.T) {
+	_, err := New([]goqonto.BankAccount{{Currency: "USD"}}, nil, nil)
+	if err != ErrNoBankAccount {
+		t.Errorf("New \n got %v\n want %v\n", err, ErrNoBankAccount)
+	}
+}
+
+func TestNewNegativeWeeks(t *testing.T) {
+	f, err := New([]goqonto.BankAccount{{Currency: "EUR"}}, testHistory(), &Options{
+		Weeks:         -1,
+		LookbackWeeks: -1,
+		Now:           func() time.Time { return now },
+	})
+	if err != nil {
+		t.Fatalf("New returned error: %v", err)
+	}
+
+	if len(f.Weeks) != 13 {
+		t.Errorf("Weeks \n got %v\n want %v\n", len(f.Weeks), 13)
+	}
+}
+
+func TestNewDST(t *testing.T) {
+	paris, err := time.LoadLocation("Europe/Paris")
+	if err != nil {
+		t.Skip(err)
+	}
+
+	// The lookback window spans the October 25 fall-back, its last week being 169 hours long.
+	now := time.Date(2020, time.December, 1, 12, 0, 0, 0, paris)
+	history := []goqonto.Transaction{
+		tx("supplies", goqonto.TransactionSideDebit, 1300, time.Date(2020, time.November, 30, 23, 30, 0, 0, paris)),
+	}
+
+	f, err := New([]goqonto.BankAccount{{Currency: "EUR", BalanceCents: 100000}}, history, &Options{
+		Location: paris,
+		Now:      func() time.Time { return now },
+	})
+	if err != nil {
+		t.Fatalf("New returned error: %v", err)
+	}
+
+	if got := f.Weeks[0].OutflowCents; got != 100 {
+		t.Errorf("OutflowCents \n got %v\n want %v\n", got, 100)
+	}
+}
+
+func TestWeekIndex(t *testing.T) {
+	paris, err := time.LoadLocation("Europe/Paris")
+	if err != nil {
+		t.Skip(err)
+	}
+
+	from := time.Date(2020, time.October, 19, 0, 0, 0, 0, paris)
+	tests := []struct {
+		t    time.Time
+		want int
+	}{
+		{time.Date(2020, time.October, 25, 23, 59, 0, 0, paris), 0},
+		{time.Date(2020, time.October, 26, 0, 0, 0, 0, paris), 1},
+		{time.Date(2020, time.November, 1, 23, 30, 0, 0, paris), 1},
+		// Midnight in Paris, still the previous day in UTC.
+		{time.Date(2020, time.November, 1, 23, 30, 0, 0, time.UTC), 2},
+	}
+
+	for _, tt := range tests {
+		if got := weekIndex(from, tt.t); got != tt.want {
+			t.Errorf("weekIndex(%v) \n got %v\n want %v\n", tt.t, got, tt.want)
+		}
+	}
+}
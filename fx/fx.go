@@ -0,0 +1,183 @@
+// Package fx interprets the local currency amounts of Qonto transactions: minor units, effective
+// exchange rates and FX fees
+package fx
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pixelfactoryio/goqonto/v2"
+)
+
+// decimals ISO 4217 minor units of the currencies not having 2 decimals
+var decimals = map[string]int{
+	"BIF": 0, "CLP": 0, "DJF": 0, "GNF": 0, "ISK": 0, "JPY": 0, "KMF": 0, "KRW": 0, "PYG": 0,
+	"RWF": 0, "UGX": 0, "UYI": 0, "VND": 0, "VUV": 0, "XAF": 0, "XOF": 0, "XPF": 0,
+	"BHD": 3, "IQD": 3, "JOD": 3, "KWD": 3, "LYD": 3, "OMR": 3, "TND": 3,
+	"CLF": 4, "UYW": 4,
+}
+
+// Decimals returns the number of decimals of the minor unit of an ISO 4217 currency, e.g. 0 for JPY,
+// 2 for EUR and 3 for KWD. Amounts in "cents" are expressed in this minor unit.
+func Decimals(currency string) int {
+	if d, ok := decimals[strings.ToUpper(currency)]; ok {
+		return d
+	}
+	return 2
+}
+
+// ToMajor converts an amount in minor units to major units
+func ToMajor(minor int, currency string) float64 {
+	return float64(minor) / math.Pow10(Decimals(currency))
+}
+
+// FromMajor converts an amount in major units to minor units, rounded to the nearest unit
+func FromMajor(major float64, currency string) int {
+	return int(math.Round(major * math.Pow10(Decimals(currency))))
+}
+
+// Format formats an amount in minor units as a decimal number with the currency decimals
+func Format(minor int, currency string) string {
+	d := Decimals(currency)
+
+	sign := ""
+	if minor < 0 {
+		sign = "-"
+		minor = -minor
+	}
+
+	s := strconv.Itoa(minor)
+	if d == 0 {
+		return sign + s
+	}
+	if len(s) <= d {
+		s = strings.Repeat("0", d-len(s)+1) + s
+	}
+	return sign + s[:len(s)-d] + "." + s[len(s)-d:]
+}
+
+// IsForeign reports whether the transaction was made in a currency other than the account currency
+func IsForeign(t goqonto.Transaction) bool {
+	return t.LocalCurrency != "" && t.LocalCurrency != t.Currency
+}
+
+// LocalAmount returns the transaction amount in local currency major units
+func LocalAmount(t goqonto.Transaction) float64 {
+	return ToMajor(t.LocalAmountCents, t.LocalCurrency)
+}
+
+// Rate returns the effective exchange rate of a foreign transaction, in local currency units per
+// account currency unit. It returns false for transactions which are not foreign or have no amount.
+func Rate(t goqonto.Transaction) (float64, bool) {
+	if !IsForeign(t) || t.AmountCents == 0 || t.LocalAmountCents == 0 {
+		return 0, false
+	}
+	return LocalAmount(t) / ToMajor(t.AmountCents, t.Currency), true
+}
+
+// RateSource provides reference exchange rates
+type RateSource interface {
+	// Rate returns the units of quote currency per unit of base currency on the given date.
+	Rate(base, quote string, date time.Time) (float64, error)
+}
+
+// StaticRates reference rates independent of the date, in units of currency per unit of any base
+// currency. It is mostly useful for tests and rough estimates.
+type StaticRates map[string]float64
+
+// Rate returns the rate of quote
+func (r StaticRates) Rate(base, quote string, date time.Time) (float64, error) {
+	rate, ok := r[quote]
+	if !ok {
+		return 0, fmt.Errorf("fx: no rate for %s/%s", base, quote)
+	}
+	return rate, nil
+}
+
+// Fee returns the FX fee of a foreign transaction in account currency minor units: the difference
+// between the account amount and the local amount converted at the reference rate. A negative fee
+// means the effective rate was better than the reference.
+func Fee(t goqonto.Transaction, reference float64) int {
+	if !IsForeign(t) || reference == 0 {
+		return 0
+	}
+
+	fair := FromMajor(LocalAmount(t)/reference, t.Currency)
+	if t.Side == goqonto.TransactionSideDebit {
+		return t.AmountCents - fair
+	}
+	return fair - t.AmountCents
+}
+
+// Summary foreign transactions of a local currency
+type Summary struct {
+	Currency      string
+	LocalCurrency string
+	Count         int
+
+	// Total amounts in local currency and in account currency minor units.
+	LocalAmountCents int
+	AmountCents      int
+
+	// Average effective rate, weighted by amount.
+	Rate float64
+
+	// Total FX fees in account currency minor units, 0 without rate source.
+	FeeCents int
+}
+
+// Summarize aggregates foreign transactions by account and local currency, sorted by currency.
+// FX fees are computed against the reference rates of source at the emission date of every
+// transaction when source is not nil. Declined and reversed transactions are ignored.
+func Summarize(transactions []goqonto.Transaction, source RateSource) ([]Summary, error) {
+	index := make(map[string]*Summary)
+	var summaries []*Summary
+
+	for _, t := range transactions {
+		if !IsForeign(t) ||
+			t.Status == goqonto.TransactionStatusDeclined || t.Status == goqonto.TransactionStatusReversed {
+			continue
+		}
+
+		key := t.Currency + "/" + t.LocalCurrency
+		s, ok := index[key]
+		if !ok {
+			s = &Summary{Currency: t.Currency, LocalCurrency: t.LocalCurrency}
+			index[key] = s
+			summaries = append(summaries, s)
+		}
+
+		s.Count++
+		s.LocalAmountCents += t.LocalAmountCents
+		s.AmountCents += t.AmountCents
+
+		if source != nil {
+			reference, err := source.Rate(t.Currency, t.LocalCurrency, t.EmittedAt)
+			if err != nil {
+				return nil, err
+			}
+			s.FeeCents += Fee(t, reference)
+		}
+	}
+
+	result := make([]Summary, 0, len(summaries))
+	for _, s := range summaries {
+		if s.AmountCents != 0 {
+			s.Rate = ToMajor(s.LocalAmountCents, s.LocalCurrency) / ToMajor(s.AmountCents, s.Currency)
+		}
+		result = append(result, *s)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Currency != result[j].Currency {
+			return result[i].Currency < result[j].Currency
+		}
+		return result[i].LocalCurrency < result[j].LocalCurrency
+	})
+
+	return result, nil
+}
@@ -0,0 +1,108 @@
+package fx
+
+import (
+	"math"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/pixelfactoryio/goqonto/v2"
+)
+
+func foreign(currency string, localMinor, cents int) goqonto.Transaction {
+	return goqonto.Transaction{
+		Side:             goqonto.TransactionSideDebit,
+		Status:           goqonto.TransactionStatusCompleted,
+		Currency:         "EUR",
+		AmountCents:      cents,
+		LocalCurrency:    currency,
+		LocalAmountCents: localMinor,
+		EmittedAt:        time.Date(2020, time.May, 4, 10, 0, 0, 0, time.UTC),
+	}
+}
+
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		minor    int
+		currency string
+		want     string
+	}{
+		{12345, "EUR", "123.45"},
+		{5, "usd", "0.05"},
+		{12345, "JPY", "12345"},
+		{12345, "KWD", "12.345"},
+		{-7, "BHD", "-0.007"},
+	}
+
+	for _, tt := range tests {
+		if got := Format(tt.minor, tt.currency); got != tt.want {
+			t.Errorf("Format(%d, %s) \n got %s\n want %s\n", tt.minor, tt.currency, got, tt.want)
+		}
+	}
+}
+
+func TestRate(t *testing.T) {
+	tests := []struct {
+		trx  goqonto.Transaction
+		want float64
+		ok   bool
+	}{
+		{foreign("USD", 11000, 10000), 1.1, true},
+		{foreign("JPY", 12000, 10000), 120, true},
+		{foreign("KWD", 33000, 10000), 0.33, true},
+		{foreign("EUR", 10000, 10000), 0, false},
+		{foreign("", 0, 10000), 0, false},
+	}
+
+	for _, tt := range tests {
+		got, ok := Rate(tt.trx)
+		if ok != tt.ok || math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("Rate(%s) \n got %v, %v\n want %v, %v\n", tt.trx.LocalCurrency, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestFee(t *testing.T) {
+	// 12000 JPY at a reference rate of 120 is 100 EUR, 102 EUR were debited.
+	if got := Fee(foreign("JPY", 12000, 10200), 120); got != 200 {
+		t.Errorf("Fee \n got %d\n want 200\n", got)
+	}
+
+	credit := foreign("USD", 11000, 9800)
+	credit.Side = goqonto.TransactionSideCredit
+	if got := Fee(credit, 1.1); got != 200 {
+		t.Errorf("Fee credit \n got %d\n want 200\n", got)
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	declined := foreign("USD", 99900, 90000)
+	declined.Status = goqonto.TransactionStatusDeclined
+
+	transactions := []goqonto.Transaction{
+		foreign("USD", 11000, 10100),
+		foreign("JPY", 12000, 10200),
+		foreign("USD", 22000, 20200),
+		foreign("EUR", 1000, 1000),
+		declined,
+	}
+
+	got, err := Summarize(transactions, StaticRates{"USD": 1.1, "JPY": 120})
+	if err != nil {
+		t.Fatalf("Summarize returned error: %v", err)
+	}
+
+	want := []Summary{
+		{Currency: "EUR", LocalCurrency: "JPY", Count: 1, LocalAmountCents: 12000, AmountCents: 10200,
+			Rate: 12000 / 102.0, FeeCents: 200},
+		{Currency: "EUR", LocalCurrency: "USD", Count: 2, LocalAmountCents: 33000, AmountCents: 30300,
+			Rate: 330 / 303.0, FeeCents: 300},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Summarize \n got %+v\n want %+v\n", got, want)
+	}
+
+	if _, err := Summarize(transactions, StaticRates{}); err == nil {
+		t.Errorf("Summarize without rates returned no error")
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"io/ioutil"
 	"net/http"
 	"net/url"
+	"time"
 )
 
 const (
@@ -41,9 +42,14 @@ type Client struct {
 	Memberships   *MembershipsService
 	Attachments   *AttachmentsService
 	Labels        *LabelsService
+	Transfers     *TransfersService
+	Webhooks      *WebhooksService
 
 	// Optional function callback
 	onRequestCompleted RequestCompletionCallback
+
+	// Optional request logger, see SetLogger
+	logger *requestLogger
 }
 
 type service struct {
@@ -104,6 +110,8 @@ func NewClient(httpClient *http.Client) *Client {
 	c.Memberships = (*MembershipsService)(&c.common)
 	c.Attachments = (*AttachmentsService)(&c.common)
 	c.Labels = (*LabelsService)(&c.common)
+	c.Transfers = (*TransfersService)(&c.common)
+	c.Webhooks = (*WebhooksService)(&c.common)
 
 	return c
 }
@@ -189,7 +197,11 @@ func newResponse(r *http.Response) *Response {
 // pointed to by v, or returned as an error if an API error has occurred. If v implements the io.Writer interface,
 // the raw response will be written to v, without attempting to decode it.
 func (c *Client) Do(ctx context.Context, req *http.Request, v interface{}) (*Response, error) {
+	start := time.Now()
 	resp, err := DoRequestWithClient(ctx, c.client, req)
+	if c.logger != nil {
+		c.logger.log(ctx, req, resp, err, time.Since(start))
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -91,6 +91,9 @@ func testClientServices(t *testing.T, c *Client) {
 		"Transactions",
 		"Memberships",
 		"Attachments",
+		"Labels",
+		"Transfers",
+		"Webhooks",
 	}
 
 	cp := reflect.ValueOf(c)
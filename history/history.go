@@ -0,0 +1,187 @@
+// Package history reconstructs the balance history of a Qonto bank account from its transactions
+package history
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/pixelfactoryio/goqonto/v2"
+)
+
+// dateLayout layout used to format the days of the series
+const dateLayout = "2006-01-02"
+
+// ErrInvalidPeriod is returned when the end of the period is before its start
+var ErrInvalidPeriod = errors.New("history: end of period is before its start")
+
+// Options Balance history reconstruction options
+type Options struct {
+	// Location in which days are computed. Defaults to UTC.
+	Location *time.Location
+
+	// Time at which the bank account balances were read.
+	// Defaults to BankAccount.UpdatedAt, or to the current time if it is not set.
+	AsOf time.Time
+}
+
+// DailyBalance end of day balances of a bank account
+type DailyBalance struct {
+	Date                   time.Time
+	BalanceCents           int
+	AuthorizedBalanceCents int
+}
+
+// MarshalJSON custom marshaler to format the date as yyyy-MM-dd
+func (d DailyBalance) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Date                   string `json:"date"`
+		BalanceCents           int    `json:"balance_cents"`
+		AuthorizedBalanceCents int    `json:"authorized_balance_cents"`
+	}{
+		Date:                   d.Date.Format(dateLayout),
+		BalanceCents:           d.BalanceCents,
+		AuthorizedBalanceCents: d.AuthorizedBalanceCents,
+	})
+}
+
+// Series end of day balances ordered by ascending date
+type Series []DailyBalance
+
+// WriteCSV writes the series as CSV with a date,balance_cents,authorized_balance_cents header
+func (s Series) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+
+	if err := cw.Write([]string{"date", "balance_cents", "authorized_balance_cents"}); err != nil {
+		return err
+	}
+
+	for _, d := range s {
+		record := []string{
+			d.Date.Format(dateLayout),
+			strconv.Itoa(d.BalanceCents),
+			strconv.Itoa(d.AuthorizedBalanceCents),
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// Reconstruct walks backwards from the current balances of the bank account to compute the end of day
+// balances of every day between from and to (inclusive).
+//
+// Completed transactions impacted the balance at SettledAt and the authorized balance at EmittedAt.
+// Pending transactions only impacted the authorized balance, at EmittedAt. Reversed transactions impacted
+// the authorized balance between EmittedAt and their reversal (UpdatedAt). Declined transactions are ignored.
+func Reconstruct(
+	account goqonto.BankAccount,
+	transactions []goqonto.Transaction,
+	from, to time.Time,
+	opt *Options) (Series, error) {
+
+	if opt == nil {
+		opt = &Options{}
+	}
+
+	loc := opt.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+
+	asOf := opt.AsOf
+	if asOf.IsZero() {
+		asOf = account.UpdatedAt
+	}
+	if asOf.IsZero() {
+		asOf = time.Now()
+	}
+
+	first := startOfDay(from, loc)
+	last := startOfDay(to, loc)
+	if last.Before(first) {
+		return nil, ErrInvalidPeriod
+	}
+
+	for _, t := range transactions {
+		if t.Currency != "" && account.Currency != "" && t.Currency != account.Currency {
+			return nil, fmt.Errorf("history: transaction %s currency %s does not match bank account currency %s",
+				t.ID, t.Currency, account.Currency)
+		}
+	}
+
+	var series Series
+	for day := first; !day.After(last); day = day.AddDate(0, 0, 1) {
+		endOfDay := day.AddDate(0, 0, 1)
+		balance, authorized := account.BalanceCents, account.AuthorizedBalanceCents
+
+		for _, t := range transactions {
+			amount := signedAmount(t)
+
+			switch t.Status {
+			case goqonto.TransactionStatusCompleted:
+				if impactedBetween(t.SettledAt, endOfDay, asOf) {
+					balance -= amount
+				}
+				if impactedBetween(t.EmittedAt, endOfDay, asOf) {
+					authorized -= amount
+				}
+			case goqonto.TransactionStatusPending:
+				if impactedBetween(t.EmittedAt, endOfDay, asOf) {
+					authorized -= amount
+				}
+			case goqonto.TransactionStatusReversed:
+				if t.UpdatedAt.After(asOf) {
+					// Reversed after the balances were read: it was still pending at that time.
+					if impactedBetween(t.EmittedAt, endOfDay, asOf) {
+						authorized -= amount
+					}
+					continue
+				}
+				// The reversal restored the authorized balance, so the transaction only has to be
+				// accounted for on the days it was still pending.
+				emitted := !t.EmittedAt.IsZero() && t.EmittedAt.Before(endOfDay)
+				if emitted && !t.UpdatedAt.Before(endOfDay) {
+					authorized += amount
+				}
+			}
+		}
+
+		series = append(series, DailyBalance{
+			Date:                   day,
+			BalanceCents:           balance,
+			AuthorizedBalanceCents: authorized,
+		})
+	}
+
+	return series, nil
+}
+
+// signedAmount returns the transaction amount in cents, negative for debits
+func signedAmount(t goqonto.Transaction) int {
+	if t.Side == goqonto.TransactionSideDebit {
+		return -t.AmountCents
+	}
+	return t.AmountCents
+}
+
+// impactedBetween reports whether at is at or after endOfDay and no later than asOf,
+// i.e. the transaction is included in the current balance but not in the balance at endOfDay
+func impactedBetween(at, endOfDay, asOf time.Time) bool {
+	if at.IsZero() {
+		return false
+	}
+	return !at.Before(endOfDay) && !at.After(asOf)
+}
+
+func startOfDay(t time.Time, loc *time.Location) time.Time {
+	y, m, d := t.In(loc).Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, loc)
+}
@@ -0,0 +1,201 @@
+package history
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/pixelfactoryio/goqonto/v2"
+)
+
+func mustParse(t *testing.T, value string) time.Time {
+	t.Helper()
+	v, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		t.Fatalf("time.Parse(%q): %v", value, err)
+	}
+	return v
+}
+
+func fixture(t *testing.T) (goqonto.BankAccount, []goqonto.Transaction) {
+	account := goqonto.BankAccount{
+		Slug:                   "croissant-bank-account-1",
+		Currency:               "EUR",
+		BalanceCents:           100000,
+		AuthorizedBalanceCents: 90000,
+		UpdatedAt:              mustParse(t, "2021-03-10T12:00:00Z"),
+	}
+
+	transactions := []goqonto.Transaction{
+		{
+			ID:          "t1",
+			AmountCents: 20000,
+			Currency:    "EUR",
+			Side:        goqonto.TransactionSideCredit,
+			Status:      goqonto.TransactionStatusCompleted,
+			EmittedAt:   mustParse(t, "2021-03-08T09:00:00Z"),
+			SettledAt:   mustParse(t, "2021-03-08T10:00:00Z"),
+		},
+		{
+			ID:          "t2",
+			AmountCents: 5000,
+			Currency:    "EUR",
+			Side:        goqonto.TransactionSideDebit,
+			Status:      goqonto.TransactionStatusCompleted,
+			EmittedAt:   mustParse(t, "2021-03-09T23:30:00Z"),
+			SettledAt:   mustParse(t, "2021-03-10T08:00:00Z"),
+		},
+		{
+			ID:          "t3",
+			AmountCents: 10000,
+			Currency:    "EUR",
+			Side:        goqonto.TransactionSideDebit,
+			Status:      goqonto.TransactionStatusPending,
+			EmittedAt:   mustParse(t, "2021-03-10T09:00:00Z"),
+		},
+		{
+			ID:          "t4",
+			AmountCents: 3000,
+			Currency:    "EUR",
+			Side:        goqonto.TransactionSideDebit,
+			Status:      goqonto.TransactionStatusReversed,
+			EmittedAt:   mustParse(t, "2021-03-08T12:00:00Z"),
+			UpdatedAt:   mustParse(t, "2021-03-09T12:00:00Z"),
+		},
+		{
+			ID:          "t5",
+			AmountCents: 99900,
+			Currency:    "EUR",
+			Side:        goqonto.TransactionSideDebit,
+			Status:      goqonto.TransactionStatusDeclined,
+			EmittedAt:   mustParse(t, "2021-03-09T15:00:00Z"),
+		},
+	}
+
+	return account, transactions
+}
+
+func day(t *testing.T, value string) time.Time {
+	return mustParse(t, value+"T00:00:00Z")
+}
+
+func TestReconstruct(t *testing.T) {
+	account, transactions := fixture(t)
+
+	got, err := Reconstruct(account, transactions, day(t, "2021-03-07"), day(t, "2021-03-10"), nil)
+	if err != nil {
+		t.Fatalf("Reconstruct returned error: %v", err)
+	}
+
+	want := Series{
+		{Date: day(t, "2021-03-07"), BalanceCents: 85000, AuthorizedBalanceCents: 85000},
+		{Date: day(t, "2021-03-08"), BalanceCents: 105000, AuthorizedBalanceCents: 102000},
+		{Date: day(t, "2021-03-09"), BalanceCents: 105000, AuthorizedBalanceCents: 100000},
+		{Date: day(t, "2021-03-10"), BalanceCents: 100000, AuthorizedBalanceCents: 90000},
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Reconstruct \n got %v\n want %v\n", got, want)
+	}
+}
+
+func TestReconstruct_asOf(t *testing.T) {
+	account, transactions := fixture(t)
+
+	// Balances read before t2 settled and before t3 was emitted.
+	account.BalanceCents = 105000
+	account.AuthorizedBalanceCents = 100000
+	opt := &Options{AsOf: mustParse(t, "2021-03-10T00:00:00Z")}
+
+	got, err := Reconstruct(account, transactions, day(t, "2021-03-09"), day(t, "2021-03-10"), opt)
+	if err != nil {
+		t.Fatalf("Reconstruct returned error: %v", err)
+	}
+
+	want := Series{
+		{Date: day(t, "2021-03-09"), BalanceCents: 105000, AuthorizedBalanceCents: 100000},
+		{Date: day(t, "2021-03-10"), BalanceCents: 105000, AuthorizedBalanceCents: 100000},
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Reconstruct \n got %v\n want %v\n", got, want)
+	}
+}
+
+func TestReconstruct_location(t *testing.T) {
+	account, transactions := fixture(t)
+
+	// t2 was emitted on 2021-03-09 in UTC but on 2021-03-10 in Paris (UTC+1).
+	paris := time.FixedZone("CET", 3600)
+	opt := &Options{Location: paris}
+
+	got, err := Reconstruct(account, transactions, day(t, "2021-03-09"), day(t, "2021-03-09"), opt)
+	if err != nil {
+		t.Fatalf("Reconstruct returned error: %v", err)
+	}
+
+	want := Series{
+		{Date: time.Date(2021, 3, 9, 0, 0, 0, 0, paris), BalanceCents: 105000, AuthorizedBalanceCents: 105000},
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Reconstruct \n got %v\n want %v\n", got, want)
+	}
+}
+
+func TestReconstruct_invalidPeriod(t *testing.T) {
+	account, transactions := fixture(t)
+
+	_, err := Reconstruct(account, transactions, day(t, "2021-03-10"), day(t, "2021-03-09"), nil)
+	if err != ErrInvalidPeriod {
+		t.Errorf("Reconstruct error got %v, want %v", err, ErrInvalidPeriod)
+	}
+}
+
+func TestReconstruct_currencyMismatch(t *testing.T) {
+	account, transactions := fixture(t)
+	transactions[0].Currency = "USD"
+
+	_, err := Reconstruct(account, transactions, day(t, "2021-03-09"), day(t, "2021-03-10"), nil)
+	if err == nil {
+		t.Errorf("Expected error to be returned")
+	}
+}
+
+func TestSeries_WriteCSV(t *testing.T) {
+	s := Series{
+		{Date: day(t, "2021-03-09"), BalanceCents: 105000, AuthorizedBalanceCents: 100000},
+		{Date: day(t, "2021-03-10"), BalanceCents: 100000, AuthorizedBalanceCents: -500},
+	}
+
+	var buf bytes.Buffer
+	if err := s.WriteCSV(&buf); err != nil {
+		t.Fatalf("WriteCSV returned error: %v", err)
+	}
+
+	want := "date,balance_cents,authorized_balance_cents\n" +
+		"2021-03-09,105000,100000\n" +
+		"2021-03-10,100000,-500\n"
+
+	if got := buf.String(); got != want {
+		t.Errorf("WriteCSV \n got %v\n want %v\n", got, want)
+	}
+}
+
+func TestSeries_marshall(t *testing.T) {
+	s := Series{
+		{Date: day(t, "2021-03-09"), BalanceCents: 105000, AuthorizedBalanceCents: 100000},
+	}
+
+	got, err := json.Marshal(s)
+	if err != nil {
+		t.Fatalf("json.Marshal returned error: %v", err)
+	}
+
+	want := `[{"date":"2021-03-09","balance_cents":105000,"authorized_balance_cents":100000}]`
+	if string(got) != want {
+		t.Errorf("json.Marshal \n got %s\n want %s\n", got, want)
+	}
+}
@@ -0,0 +1,76 @@
+// Package iban validates International Bank Account Numbers (ISO 13616)
+package iban
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Errors returned by Validate
+var (
+	ErrInvalidFormat   = errors.New("iban: invalid format")
+	ErrInvalidLength   = errors.New("iban: invalid length for country")
+	ErrInvalidChecksum = errors.New("iban: invalid checksum")
+)
+
+// lengths IBAN lengths of the SEPA countries
+var lengths = map[string]int{
+	"AD": 24, "AT": 20, "BE": 16, "BG": 22, "CH": 21, "CY": 28, "CZ": 24, "DE": 22, "DK": 18,
+	"EE": 20, "ES": 24, "FI": 18, "FR": 27, "GB": 22, "GI": 23, "GR": 27, "HR": 21, "HU": 28,
+	"IE": 22, "IS": 26, "IT": 27, "LI": 21, "LT": 20, "LU": 20, "LV": 21, "MC": 27, "MT": 31,
+	"NL": 18, "NO": 15, "PL": 28, "PT": 25, "RO": 24, "SE": 24, "SI": 19, "SK": 24, "SM": 27,
+	"VA": 22,
+}
+
+// Normalize removes spaces and upper cases an IBAN
+func Normalize(s string) string {
+	return strings.ToUpper(strings.Replace(s, " ", "", -1))
+}
+
+// Validate checks the format, the country length and the mod 97 checksum of a normalized IBAN
+func Validate(s string) error {
+	if len(s) < 15 || len(s) > 34 {
+		return ErrInvalidFormat
+	}
+
+	for i, r := range s {
+		switch {
+		case i < 2 && (r < 'A' || r > 'Z'):
+			return ErrInvalidFormat
+		case i >= 2 && i < 4 && (r < '0' || r > '9'):
+			return ErrInvalidFormat
+		case (r < '0' || r > '9') && (r < 'A' || r > 'Z'):
+			return ErrInvalidFormat
+		}
+	}
+
+	if l, ok := lengths[s[:2]]; ok && l != len(s) {
+		return fmt.Errorf("%w: %s expects %d characters", ErrInvalidLength, s[:2], l)
+	}
+
+	if mod97(s[4:]+s[:4]) != 1 {
+		return ErrInvalidChecksum
+	}
+
+	return nil
+}
+
+// CheckDigits computes the two check digits of an IBAN from its country code and BBAN
+func CheckDigits(country, bban string) string {
+	return fmt.Sprintf("%02d", 98-mod97(bban+country+"00"))
+}
+
+// mod97 computes the ISO 7064 mod 97-10 remainder, letters being converted to 10..35
+func mod97(s string) int {
+	remainder := 0
+	for _, r := range s {
+		switch {
+		case r >= '0' && r <= '9':
+			remainder = (remainder*10 + int(r-'0')) % 97
+		default:
+			remainder = (remainder*100 + int(r-'A') + 10) % 97
+		}
+	}
+	return remainder
+}
@@ -0,0 +1,45 @@
+package iban
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		iban string
+		want error
+	}{
+		{"FR7630006000011234567890189", nil},
+		{"FR1420041010050500013M02606", nil},
+		{"DE89370400440532013000", nil},
+		{"GB82WEST12345698765432", nil},
+		{"FR7630006000011234567890188", ErrInvalidChecksum},
+		{"FR763000600001123456789018", ErrInvalidLength},
+		{"FR76-3000600001123456789018", ErrInvalidFormat},
+		{"7630006000011234567890189FR", ErrInvalidFormat},
+		{"FR76", ErrInvalidFormat},
+	}
+
+	for _, tt := range tests {
+		if got := Validate(tt.iban); !errors.Is(got, tt.want) {
+			t.Errorf("Validate(%s) got %v, want %v", tt.iban, got, tt.want)
+		}
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	if got, want := Normalize("fr76 3000 6000 0112 3456 7890 189"), "FR7630006000011234567890189"; got != want {
+		t.Errorf("Normalize got %s, want %s", got, want)
+	}
+}
+
+func TestCheckDigits(t *testing.T) {
+	if got, want := CheckDigits("FR", "30006000011234567890189"), "76"; got != want {
+		t.Errorf("CheckDigits got %s, want %s", got, want)
+	}
+
+	if got, want := CheckDigits("DE", "370400440532013000"), "89"; got != want {
+		t.Errorf("CheckDigits got %s, want %s", got, want)
+	}
+}
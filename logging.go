@@ -0,0 +1,288 @@
+package goqonto
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// requestIDHeader header identifying a request in the Qonto API logs
+const requestIDHeader = "X-Request-Id"
+
+// redacted replaces redacted values in the logs
+const redacted = "[REDACTED]"
+
+// LogLevel severity of a log entry. The values are the ones of the log/slog levels, so that
+// slog.Level(level) converts them.
+type LogLevel int
+
+// LogLevelDebug is the level of debugging entries.
+const LogLevelDebug LogLevel = -4
+
+// LogLevelInfo is the level of informational entries.
+const LogLevelInfo LogLevel = 0
+
+// LogLevelWarn is the level of warning entries.
+const LogLevelWarn LogLevel = 4
+
+// LogLevelError is the level of error entries.
+const LogLevelError LogLevel = 8
+
+func (l LogLevel) String() string {
+	switch l {
+	case LogLevelDebug:
+		return "DEBUG"
+	case LogLevelInfo:
+		return "INFO"
+	case LogLevelWarn:
+		return "WARN"
+	case LogLevelError:
+		return "ERROR"
+	}
+	return fmt.Sprintf("LEVEL(%d)", int(l))
+}
+
+// Level returns a pointer to l, for the LogOptions levels defaulting to another level when nil
+func Level(l LogLevel) *LogLevel {
+	return &l
+}
+
+// Logger structured logger. The arguments are alternating keys and values, as with log/slog.
+type Logger interface {
+	Log(ctx context.Context, level LogLevel, msg string, args ...interface{})
+}
+
+// LoggerFunc adapts a function to the Logger interface
+type LoggerFunc func(ctx context.Context, level LogLevel, msg string, args ...interface{})
+
+// Log calls f
+func (f LoggerFunc) Log(ctx context.Context, level LogLevel, msg string, args ...interface{}) {
+	f(ctx, level, msg, args...)
+}
+
+// LogOptions options of SetLogger
+type LogOptions struct {
+	// Level of the successful requests. Defaults to LogLevelInfo.
+	Level LogLevel
+
+	// Level of the requests answered with a 4xx status. Defaults to LogLevelWarn when nil.
+	ClientErrorLevel *LogLevel
+
+	// Level of the failed requests and of the 5xx responses. Defaults to LogLevelError when nil.
+	ErrorLevel *LogLevel
+
+	// Log the headers and the JSON bodies of the requests and responses.
+	LogBodies bool
+
+	// Maximum number of bytes of a logged body. Defaults to 1024 when not positive.
+	MaxBodySize int
+
+	// JSON keys holding personal data, whose values are redacted from the logged bodies.
+	// Defaults to DefaultRedactedKeys.
+	RedactedKeys []string
+}
+
+// DefaultRedactedKeys JSON keys holding personal data
+var DefaultRedactedKeys = []string{"first_name", "last_name", "email", "phone_number", "birthdate", "address"}
+
+// ibanPattern matches IBANs, which are redacted from the logs
+var ibanPattern = regexp.MustCompile(`\b[A-Z]{2}[0-9]{2}[A-Z0-9]{11,30}\b`)
+
+// attemptKey context key of the attempt number
+type attemptKey struct{}
+
+// WithAttempt returns a context whose requests are logged with an attempt number, for callers
+// retrying requests. Requests are logged as the first attempt otherwise.
+func WithAttempt(ctx context.Context, attempt int) context.Context {
+	return context.WithValue(ctx, attemptKey{}, attempt)
+}
+
+// requestLogger logs the requests sent by Client.Do
+type requestLogger struct {
+	logger Logger
+	opt    LogOptions
+	keys   map[string]bool
+}
+
+// SetLogger is a client option logging every request with its method, path, status, duration,
+// attempt, request ID and page meta. Authorization headers, IBANs and personal data are redacted.
+func SetLogger(l Logger, opt *LogOptions) ClientOpt {
+	return func(c *Client) error {
+		o := LogOptions{}
+		if opt != nil {
+			o = *opt
+		}
+		if o.ClientErrorLevel == nil {
+			o.ClientErrorLevel = Level(LogLevelWarn)
+		}
+		if o.ErrorLevel == nil {
+			o.ErrorLevel = Level(LogLevelError)
+		}
+		if o.MaxBodySize <= 0 {
+			o.MaxBodySize = 1024
+		}
+		if o.RedactedKeys == nil {
+			o.RedactedKeys = DefaultRedactedKeys
+		}
+
+		keys := make(map[string]bool, len(o.RedactedKeys))
+		for _, k := range o.RedactedKeys {
+			keys[k] = true
+		}
+
+		c.logger = &requestLogger{logger: l, opt: o, keys: keys}
+		return nil
+	}
+}
+
+// log logs a request and its response, or the error that prevented it. The JSON body of the
+// response is read to log its page meta and restored.
+func (l *requestLogger) log(ctx context.Context, req *http.Request, resp *http.Response, err error, d time.Duration) {
+	attempt := 1
+	if a, ok := ctx.Value(attemptKey{}).(int); ok {
+		attempt = a
+	}
+
+	args := []interface{}{
+		"method", req.Method,
+		"path", l.redact(req.URL.Path),
+		"duration", d,
+		"attempt", attempt,
+	}
+	if q := req.URL.RawQuery; q != "" {
+		args = append(args, "query", l.redact(q))
+	}
+	if l.opt.LogBodies {
+		args = append(args, "request_headers", l.headers(req.Header))
+		if body := l.requestBody(req); body != "" {
+			args = append(args, "request_body", body)
+		}
+	}
+
+	if err != nil {
+		args = append(args, "error", l.redact(err.Error()))
+		l.logger.Log(ctx, *l.opt.ErrorLevel, "qonto request failed", args...)
+		return
+	}
+
+	args = append(args, "status", resp.StatusCode)
+
+	if id := resp.Header.Get(requestIDHeader); id != "" {
+		args = append(args, "request_id", id)
+	} else if id := req.Header.Get(requestIDHeader); id != "" {
+		args = append(args, "request_id", id)
+	}
+
+	if strings.HasPrefix(resp.Header.Get("Content-Type"), mediaType) {
+		data, rerr := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		resp.Body = ioutil.NopCloser(bytes.NewReader(data))
+
+		if rerr == nil {
+			var root metaRoot
+			if json.Unmarshal(data, &root) == nil && root.Meta != (ResponseMeta{}) {
+				args = append(args,
+					"current_page", root.Meta.CurrentPage,
+					"next_page", root.Meta.NextPage,
+					"total_pages", root.Meta.TotalPages,
+					"total_count", root.Meta.TotalCount,
+				)
+			}
+			if l.opt.LogBodies {
+				args = append(args, "response_headers", l.headers(resp.Header))
+				args = append(args, "response_body", l.body(data))
+			}
+		}
+	}
+
+	level := l.opt.Level
+	switch {
+	case resp.StatusCode >= 500:
+		level = *l.opt.ErrorLevel
+	case resp.StatusCode >= 400:
+		level = *l.opt.ClientErrorLevel
+	}
+
+	l.logger.Log(ctx, level, "qonto request", args...)
+}
+
+// requestBody returns the redacted body of a request, read from a copy
+func (l *requestLogger) requestBody(req *http.Request) string {
+	if req.GetBody == nil {
+		return ""
+	}
+
+	body, err := req.GetBody()
+	if err != nil {
+		return ""
+	}
+	defer body.Close()
+
+	data, err := ioutil.ReadAll(body)
+	if err != nil || len(data) == 0 {
+		return ""
+	}
+	return l.body(data)
+}
+
+// headers returns the headers with a redacted Authorization header
+func (l *requestLogger) headers(h http.Header) map[string]string {
+	headers := make(map[string]string, len(h))
+	for k := range h {
+		headers[k] = h.Get(k)
+	}
+	if _, ok := headers["Authorization"]; ok {
+		headers["Authorization"] = redacted
+	}
+	return headers
+}
+
+// body returns a redacted body, truncated on a rune boundary. Values of the redacted keys are replaced in JSON bodies.
+func (l *requestLogger) body(data []byte) string {
+	var v interface{}
+	if json.Unmarshal(data, &v) == nil {
+		if b, err := json.Marshal(l.redactJSON(v)); err == nil {
+			data = b
+		}
+	}
+
+	s := l.redact(string(bytes.TrimSpace(data)))
+	if len(s) > l.opt.MaxBodySize {
+		n := l.opt.MaxBodySize
+		for n > 0 && !utf8.RuneStart(s[n]) {
+			n--
+		}
+		s = s[:n] + "...(truncated)"
+	}
+	return s
+}
+
+func (l *requestLogger) redactJSON(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, value := range v {
+			if l.keys[k] {
+				v[k] = redacted
+				continue
+			}
+			v[k] = l.redactJSON(value)
+		}
+	case []interface{}:
+		for k, value := range v {
+			v[k] = l.redactJSON(value)
+		}
+	}
+	return v
+}
+
+// redact replaces the IBANs of s
+func (l *requestLogger) redact(s string) string {
+	return ibanPattern.ReplaceAllString(s, redacted)
+}
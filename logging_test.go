@@ -0,0 +1,232 @@
+package goqonto
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// logEntry entry recorded by a test logger
+type logEntry struct {
+	level LogLevel
+	msg   string
+	args  map[string]interface{}
+}
+
+func testLogger(t *testing.T, opt *LogOptions) *[]logEntry {
+	t.Helper()
+
+	entries := new([]logEntry)
+	l := LoggerFunc(func(ctx context.Context, level LogLevel, msg string, args ...interface{}) {
+		if len(args)%2 != 0 {
+			t.Fatalf("odd number of log arguments: %v", args)
+		}
+		e := logEntry{level: level, msg: msg, args: make(map[string]interface{})}
+		for k := 0; k < len(args); k += 2 {
+			e.args[args[k].(string)] = args[k+1]
+		}
+		*entries = append(*entries, e)
+	})
+
+	if err := SetLogger(l, opt)(client); err != nil {
+		t.Fatal(err)
+	}
+	return entries
+}
+
+func TestSetLogger(t *testing.T) {
+	setup()
+	defer teardown()
+
+	entries := testLogger(t, &LogOptions{Level: LogLevelDebug, LogBodies: true})
+
+	mux.HandleFunc(fmt.Sprintf("/%s", transactionsBasePath), func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", mediaType)
+		w.Header().Set(requestIDHeader, "req-42")
+		fmt.Fprint(w, `{"transactions":[{"id":"t1"}],"meta":{"current_page":2,"next_page":3,"total_pages":5,`+
+			`"total_count":420,"per_page":100}}`)
+	})
+
+	opt := &TransactionsOptions{Slug: "croissant", IBAN: "FR7616798000010000005663951"}
+	req, err := client.NewRequest(WithAttempt(ctx, 2), http.MethodGet, transactionsBasePath, opt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Authorization", "croissant:secret")
+
+	var root transactionsRoot
+	if _, err := client.Do(WithAttempt(ctx, 2), req, &root); err != nil {
+		t.Fatalf("Do returned error: %v", err)
+	}
+	if len(root.Transactions) != 1 {
+		t.Errorf("decoded transactions \n got %v\n want %v\n", len(root.Transactions), 1)
+	}
+
+	if len(*entries) != 1 {
+		t.Fatalf("log entries \n got %v\n want %v\n", len(*entries), 1)
+	}
+	e := (*entries)[0]
+
+	if e.level != LogLevelDebug {
+		t.Errorf("level \n got %v\n want %v\n", e.level, LogLevelDebug)
+	}
+
+	want := map[string]interface{}{
+		"method":       http.MethodGet,
+		"path":         "/" + transactionsBasePath,
+		"status":       http.StatusOK,
+		"attempt":      2,
+		"request_id":   "req-42",
+		"current_page": 2,
+		"next_page":    3,
+		"total_pages":  5,
+		"total_count":  420,
+		"request_body": `{"iban":"[REDACTED]","slug":"croissant"}`,
+	}
+	for k, v := range want {
+		if got := e.args[k]; !reflect.DeepEqual(got, v) {
+			t.Errorf("%s \n got %v\n want %v\n", k, got, v)
+		}
+	}
+
+	headers := e.args["request_headers"].(map[string]string)
+	if got := headers["Authorization"]; got != "[REDACTED]" {
+		t.Errorf("Authorization \n got %v\n want %v\n", got, "[REDACTED]")
+	}
+	if _, ok := e.args["duration"]; !ok {
+		t.Errorf("duration is not logged")
+	}
+}
+
+func TestSetLogger_levels(t *testing.T) {
+	setup()
+	defer teardown()
+
+	entries := testLogger(t, nil)
+
+	mux.HandleFunc("/ok", func(w http.ResponseWriter, r *http.Request) {})
+	mux.HandleFunc("/missing", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	})
+	mux.HandleFunc("/broken", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+
+	for _, path := range []string{"ok", "missing", "broken"} {
+		req, _ := client.NewRequest(ctx, http.MethodGet, path, nil)
+		_, _ = client.Do(ctx, req, nil)
+	}
+
+	var got []LogLevel
+	for _, e := range *entries {
+		got = append(got, e.level)
+	}
+
+	want := []LogLevel{LogLevelInfo, LogLevelWarn, LogLevelError}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("levels \n got %v\n want %v\n", got, want)
+	}
+	if got := (*entries)[0].args["attempt"]; got != 1 {
+		t.Errorf("attempt \n got %v\n want %v\n", got, 1)
+	}
+}
+
+func TestSetLogger_infoLevels(t *testing.T) {
+	setup()
+	defer teardown()
+
+	entries := testLogger(t, &LogOptions{ClientErrorLevel: Level(LogLevelInfo), ErrorLevel: Level(LogLevelInfo)})
+
+	mux.HandleFunc("/missing", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	})
+	mux.HandleFunc("/broken", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+
+	for _, path := range []string{"missing", "broken"} {
+		req, _ := client.NewRequest(ctx, http.MethodGet, path, nil)
+		_, _ = client.Do(ctx, req, nil)
+	}
+
+	for _, e := range *entries {
+		if e.level != LogLevelInfo {
+			t.Errorf("level \n got %v\n want %v\n", e.level, LogLevelInfo)
+		}
+	}
+	if len(*entries) != 2 {
+		t.Errorf("log entries \n got %v\n want %v\n", len(*entries), 2)
+	}
+}
+
+func TestSetLogger_redactedBody(t *testing.T) {
+	setup()
+	defer teardown()
+
+	entries := testLogger(t, &LogOptions{LogBodies: true, MaxBodySize: 120})
+
+	mux.HandleFunc(fmt.Sprintf("/%s", membershipsBasePath), func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", mediaType)
+		fmt.Fprint(w, `{"memberships":[{"id":"m1","first_name":"Alice","last_name":"Martin"},`+
+			`{"id":"m2","first_name":"Bruno","last_name":"Dubois"},{"id":"m3","first_name":"Emma","last_name":"Petit"}]}`)
+	})
+
+	memberships, _, err := client.Memberships.List(ctx, nil)
+	if err != nil {
+		t.Fatalf("Memberships.List returned error: %v", err)
+	}
+	if memberships[0].FistName != "Alice" {
+		t.Errorf("FistName \n got %v\n want %v\n", memberships[0].FistName, "Alice")
+	}
+
+	body := (*entries)[0].args["response_body"].(string)
+	for _, name := range []string{"Alice", "Martin", "Bruno"} {
+		if strings.Contains(body, name) {
+			t.Errorf("response_body contains %q: %s", name, body)
+		}
+	}
+	if !strings.HasSuffix(body, "...(truncated)") || len(body) != 120+len("...(truncated)") {
+		t.Errorf("response_body \n got %v\n want 120 bytes and a truncation mark\n", body)
+	}
+}
+
+func TestRequestLogger_body(t *testing.T) {
+	setup()
+	defer teardown()
+
+	testLogger(t, &LogOptions{MaxBodySize: -1})
+	l := client.logger
+
+	if l.opt.MaxBodySize != 1024 {
+		t.Errorf("MaxBodySize \n got %v\n want %v\n", l.opt.MaxBodySize, 1024)
+	}
+
+	// The limit falls in the middle of the second "é".
+	l.opt.MaxBodySize = 4
+	if got, want := l.body([]byte("aéé")), "aé...(truncated)"; got != want {
+		t.Errorf("body \n got %v\n want %v\n", got, want)
+	}
+}
+
+func TestSetLogger_error(t *testing.T) {
+	setup()
+	entries := testLogger(t, &LogOptions{ErrorLevel: Level(LogLevelWarn)})
+	teardown()
+
+	req, _ := client.NewRequest(ctx, http.MethodGet, "gone", nil)
+	if _, err := client.Do(ctx, req, nil); err == nil {
+		t.Fatalf("Expected error to be returned")
+	}
+
+	e := (*entries)[0]
+	if e.level != LogLevelWarn || e.msg != "qonto request failed" || e.args["error"] == nil {
+		t.Errorf("log entry \n got %v %v %v\n want %v %v with an error\n",
+			e.level, e.msg, e.args["error"], LogLevelWarn, "qonto request failed")
+	}
+	if _, ok := e.args["status"]; ok {
+		t.Errorf("status is logged for a failed request")
+	}
+}
@@ -0,0 +1,334 @@
+// Package multiorg manages the clients of several Qonto organizations, each with its own credentials,
+// and consolidates their data
+package multiorg
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pixelfactoryio/goqonto/v2"
+)
+
+// Credentials API credentials of an organization
+type Credentials struct {
+	// Organization slug, also used as the key of its client.
+	Slug      string
+	Login     string
+	SecretKey string
+
+	// Base URL of the API. Defaults to the public Qonto API.
+	BaseURL string
+}
+
+// Options manager options
+type Options struct {
+	// Maximum number of organizations queried at the same time. Defaults to 4.
+	Parallelism int
+
+	// Transport of the HTTP clients, the Authorization header being set on top of it.
+	// Defaults to http.DefaultTransport.
+	Transport http.RoundTripper
+}
+
+// Errors errors by organization slug
+type Errors map[string]error
+
+func (e Errors) Error() string {
+	slugs := make([]string, 0, len(e))
+	for slug := range e {
+		slugs = append(slugs, slug)
+	}
+	sort.Strings(slugs)
+
+	messages := make([]string, len(slugs))
+	for k, slug := range slugs {
+		messages[k] = fmt.Sprintf("%s: %v", slug, e[slug])
+	}
+	return fmt.Sprintf("multiorg: %d organizations failed: %s", len(e), strings.Join(messages, "; "))
+}
+
+// Transaction transaction of an organization bank account
+type Transaction struct {
+	Slug string
+	IBAN string
+	goqonto.Transaction
+}
+
+// Manager clients of several organizations keyed by organization slug
+type Manager struct {
+	parallelism int
+	transport   http.RoundTripper
+
+	mu      sync.RWMutex
+	clients map[string]*goqonto.Client
+}
+
+// New returns an empty Manager
+func New(opt *Options) *Manager {
+	o := Options{}
+	if opt != nil {
+		o = *opt
+	}
+	if o.Parallelism <= 0 {
+		o.Parallelism = 4
+	}
+	if o.Transport == nil {
+		o.Transport = http.DefaultTransport
+	}
+
+	return &Manager{
+		parallelism: o.Parallelism,
+		transport:   o.Transport,
+		clients:     make(map[string]*goqonto.Client),
+	}
+}
+
+// Add creates the client of an organization authenticated with its credentials
+func (m *Manager) Add(c Credentials) error {
+	httpClient := &http.Client{
+		Transport: authTransport{login: c.Login, secretKey: c.SecretKey, transport: m.transport},
+	}
+
+	var opts []goqonto.ClientOpt
+	if c.BaseURL != "" {
+		opts = append(opts, goqonto.SetBaseURL(c.BaseURL))
+	}
+
+	client, err := goqonto.New(httpClient, opts...)
+	if err != nil {
+		return fmt.Errorf("multiorg: %s: %v", c.Slug, err)
+	}
+
+	m.AddClient(c.Slug, client)
+	return nil
+}
+
+// AddClient adds an already configured client, replacing the client of the organization if any
+func (m *Manager) AddClient(slug string, client *goqonto.Client) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.clients[slug] = client
+}
+
+// Remove removes the client of an organization
+func (m *Manager) Remove(slug string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.clients, slug)
+}
+
+// Client returns the client of an organization
+func (m *Manager) Client(slug string) (*goqonto.Client, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	c, ok := m.clients[slug]
+	return c, ok
+}
+
+// Slugs returns the sorted slugs of the organizations
+func (m *Manager) Slugs() []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	slugs := make([]string, 0, len(m.clients))
+	for slug := range m.clients {
+		slugs = append(slugs, slug)
+	}
+	sort.Strings(slugs)
+	return slugs
+}
+
+// Each calls f for every organization, running at most Options.Parallelism calls at the same time.
+// It returns the errors of the failed organizations as Errors, or nil if all of them succeeded.
+// Organizations not started yet when ctx is done fail with the context error.
+func (m *Manager) Each(ctx context.Context, f func(ctx context.Context, slug string, c *goqonto.Client) error) error {
+	m.mu.RLock()
+	clients := make(map[string]*goqonto.Client, len(m.clients))
+	for slug, c := range m.clients {
+		clients[slug] = c
+	}
+	m.mu.RUnlock()
+
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		errs = make(Errors)
+		sem  = make(chan struct{}, m.parallelism)
+	)
+
+	fail := func(slug string, err error) {
+		mu.Lock()
+		errs[slug] = err
+		mu.Unlock()
+	}
+
+	for _, slug := range m.Slugs() {
+		c, ok := clients[slug]
+		if !ok {
+			continue
+		}
+
+		if ctx.Err() != nil {
+			fail(slug, ctx.Err())
+			continue
+		}
+
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			fail(slug, ctx.Err())
+			continue
+		}
+
+		wg.Add(1)
+		go func(slug string, c *goqonto.Client) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			if err := f(ctx, slug, c); err != nil {
+				fail(slug, err)
+			}
+		}(slug, c)
+	}
+
+	wg.Wait()
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// Organizations returns the organizations by slug. On failure the organizations that could be
+// retrieved are returned along with Errors.
+func (m *Manager) Organizations(ctx context.Context) (map[string]*goqonto.Organization, error) {
+	var mu sync.Mutex
+	orgs := make(map[string]*goqonto.Organization)
+
+	err := m.Each(ctx, func(ctx context.Context, slug string, c *goqonto.Client) error {
+		org, _, err := c.Organizations.Get(ctx, slug)
+		if err != nil {
+			return err
+		}
+
+		mu.Lock()
+		orgs[slug] = org
+		mu.Unlock()
+		return nil
+	})
+
+	return orgs, err
+}
+
+// Balances returns the balances per currency consolidated across the organizations, closed bank
+// accounts being ignored. On failure the balances of the other organizations are returned along
+// with Errors.
+func (m *Manager) Balances(ctx context.Context) ([]goqonto.CurrencyBalance, error) {
+	orgs, err := m.Organizations(ctx)
+
+	byCurrency := make(map[string]*goqonto.CurrencyBalance)
+	for _, org := range orgs {
+		for _, b := range org.BalanceSummary() {
+			total, ok := byCurrency[b.Currency]
+			if !ok {
+				total = &goqonto.CurrencyBalance{Currency: b.Currency}
+				byCurrency[b.Currency] = total
+			}
+			total.BalanceCents += b.BalanceCents
+			total.AuthorizedBalanceCents += b.AuthorizedBalanceCents
+			total.Accounts += b.Accounts
+		}
+	}
+
+	balances := make([]goqonto.CurrencyBalance, 0, len(byCurrency))
+	for _, b := range byCurrency {
+		balances = append(balances, *b)
+	}
+	sort.Slice(balances, func(i, j int) bool { return balances[i].Currency < balances[j].Currency })
+
+	return balances, err
+}
+
+// Transactions returns the transactions of all the open bank accounts of the organizations, sorted by
+// settlement date, or emission date for unsettled transactions. The slug and IBAN of opt are set for
+// each bank account, its other filters apply to all of them. On failure the transactions of the other
+// organizations are returned along with Errors.
+func (m *Manager) Transactions(ctx context.Context, opt *goqonto.TransactionsOptions) ([]Transaction, error) {
+	var mu sync.Mutex
+	var transactions []Transaction
+
+	err := m.Each(ctx, func(ctx context.Context, slug string, c *goqonto.Client) error {
+		org, _, err := c.Organizations.Get(ctx, slug)
+		if err != nil {
+			return err
+		}
+
+		var result []Transaction
+		for _, ba := range org.BankAccounts {
+			if ba.Status == goqonto.BankAccountStatusClosed {
+				continue
+			}
+
+			o := goqonto.TransactionsOptions{}
+			if opt != nil {
+				o = *opt
+			}
+			o.Slug = slug
+			o.IBAN = ba.IBAN
+
+			it := c.Transactions.Iterator(ctx, &o)
+			for it.Next() {
+				result = append(result, Transaction{Slug: slug, IBAN: ba.IBAN, Transaction: it.Transaction()})
+			}
+			if err := it.Err(); err != nil {
+				return err
+			}
+		}
+
+		mu.Lock()
+		transactions = append(transactions, result...)
+		mu.Unlock()
+		return nil
+	})
+
+	sort.SliceStable(transactions, func(i, j int) bool {
+		ti, tj := date(transactions[i]), date(transactions[j])
+		if !ti.Equal(tj) {
+			return ti.Before(tj)
+		}
+		if transactions[i].Slug != transactions[j].Slug {
+			return transactions[i].Slug < transactions[j].Slug
+		}
+		return transactions[i].ID < transactions[j].ID
+	})
+
+	return transactions, err
+}
+
+// date returns the settlement date of a transaction, or its emission date if it is not settled
+func date(t Transaction) time.Time {
+	if t.SettledAt.IsZero() {
+		return t.EmittedAt
+	}
+	return t.SettledAt
+}
+
+// authTransport sets the Authorization header of the requests
+type authTransport struct {
+	login, secretKey string
+	transport        http.RoundTripper
+}
+
+func (t authTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	r = r.Clone(r.Context())
+	r.Header.Set("Authorization", fmt.Sprintf("%s:%s", t.login, t.secretKey))
+	return t.transport.RoundTrip(r)
+}
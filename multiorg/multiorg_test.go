@@ -0,0 +1,192 @@
+package multiorg
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"reflect"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pixelfactoryio/goqonto/v2"
+	"github.com/pixelfactoryio/goqonto/v2/qontotest"
+)
+
+var start = time.Date(2021, time.March, 1, 10, 0, 0, 0, time.UTC)
+
+// newServer starts the fake API of an organization and adds its client to the manager
+func newServer(t *testing.T, m *Manager, slug string, accounts ...goqonto.BankAccount) *qontotest.Server {
+	s := qontotest.NewServer()
+	s.AddCredentials(slug, "secret-"+slug)
+	s.AddOrganization(goqonto.Organization{Slug: slug, BankAccounts: accounts})
+
+	err := m.Add(Credentials{Slug: slug, Login: slug, SecretKey: "secret-" + slug, BaseURL: s.URL})
+	if err != nil {
+		s.Close()
+		t.Fatal(err)
+	}
+	return s
+}
+
+// newManager returns a manager of three organizations and a function closing their servers
+func newManager(t *testing.T) (*Manager, func()) {
+	m := New(nil)
+
+	alpha := newServer(t, m, "alpha",
+		goqonto.BankAccount{IBAN: "FR-ALPHA-1", Currency: "EUR", BalanceCents: 1000, AuthorizedBalanceCents: 900},
+		goqonto.BankAccount{IBAN: "FR-ALPHA-2", Currency: "EUR", BalanceCents: 500, AuthorizedBalanceCents: 500,
+			Status: goqonto.BankAccountStatusClosed},
+	)
+	alpha.AddTransactions("FR-ALPHA-1",
+		goqonto.Transaction{ID: "a1", AmountCents: 100, SettledAt: start.Add(2 * time.Hour)},
+		goqonto.Transaction{ID: "a2", AmountCents: 200, EmittedAt: start},
+	)
+	alpha.AddTransactions("FR-ALPHA-2", goqonto.Transaction{ID: "closed", SettledAt: start})
+
+	beta := newServer(t, m, "beta",
+		goqonto.BankAccount{IBAN: "FR-BETA-1", Currency: "EUR", BalanceCents: 2000, AuthorizedBalanceCents: 2000},
+		goqonto.BankAccount{IBAN: "US-BETA-2", Currency: "USD", BalanceCents: 300, AuthorizedBalanceCents: 300},
+	)
+	beta.AddTransactions("FR-BETA-1", goqonto.Transaction{ID: "b1", AmountCents: 300, SettledAt: start.Add(time.Hour)})
+
+	gamma := newServer(t, m, "gamma",
+		goqonto.BankAccount{IBAN: "FR-GAMMA-1", Currency: "EUR", BalanceCents: 9000},
+	)
+	gamma.InjectFault(qontotest.Fault{Status: http.StatusInternalServerError})
+
+	return m, func() {
+		alpha.Close()
+		beta.Close()
+		gamma.Close()
+	}
+}
+
+func checkErrors(t *testing.T, err error, slugs ...string) {
+	t.Helper()
+
+	var errs Errors
+	if !errors.As(err, &errs) {
+		t.Fatalf("error \n got %v\n want %T\n", err, errs)
+	}
+
+	var got []string
+	for slug := range errs {
+		got = append(got, slug)
+	}
+	sort.Strings(got)
+	if !reflect.DeepEqual(got, slugs) {
+		t.Errorf("failed organizations \n got %v\n want %v\n", got, slugs)
+	}
+}
+
+func TestManager_Slugs(t *testing.T) {
+	m, teardown := newManager(t)
+	defer teardown()
+
+	want := []string{"alpha", "beta", "gamma"}
+	if got := m.Slugs(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Slugs \n got %v\n want %v\n", got, want)
+	}
+
+	m.Remove("beta")
+	if _, ok := m.Client("beta"); ok {
+		t.Errorf("Client(beta) \n got %v\n want %v\n", ok, false)
+	}
+}
+
+func TestManager_Balances(t *testing.T) {
+	m, teardown := newManager(t)
+	defer teardown()
+
+	got, err := m.Balances(context.Background())
+	checkErrors(t, err, "gamma")
+
+	want := []goqonto.CurrencyBalance{
+		{Currency: "EUR", BalanceCents: 3000, AuthorizedBalanceCents: 2900, Accounts: 2},
+		{Currency: "USD", BalanceCents: 300, AuthorizedBalanceCents: 300, Accounts: 1},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Balances \n got %v\n want %v\n", got, want)
+	}
+}
+
+func TestManager_Transactions(t *testing.T) {
+	m, teardown := newManager(t)
+	defer teardown()
+
+	got, err := m.Transactions(context.Background(), nil)
+	checkErrors(t, err, "gamma")
+
+	var ids []string
+	for _, tx := range got {
+		ids = append(ids, tx.Slug+"/"+tx.IBAN+"/"+tx.ID)
+	}
+
+	want := []string{"alpha/FR-ALPHA-1/a2", "beta/FR-BETA-1/b1", "alpha/FR-ALPHA-1/a1"}
+	if !reflect.DeepEqual(ids, want) {
+		t.Errorf("Transactions \n got %v\n want %v\n", ids, want)
+	}
+}
+
+func TestManager_Each(t *testing.T) {
+	m := New(&Options{Parallelism: 2})
+	for _, slug := range []string{"a", "b", "c", "d", "e", "f"} {
+		m.AddClient(slug, goqonto.NewClient(nil))
+	}
+
+	var (
+		mu        sync.Mutex
+		running   int
+		max       int
+		visited   = make(map[string]bool)
+		errFailed = errors.New("failed")
+	)
+
+	err := m.Each(context.Background(), func(ctx context.Context, slug string, c *goqonto.Client) error {
+		mu.Lock()
+		running++
+		if running > max {
+			max = running
+		}
+		visited[slug] = true
+		mu.Unlock()
+
+		time.Sleep(10 * time.Millisecond)
+
+		mu.Lock()
+		running--
+		mu.Unlock()
+
+		if slug == "b" || slug == "d" {
+			return errFailed
+		}
+		return nil
+	})
+
+	checkErrors(t, err, "b", "d")
+	if max != 2 {
+		t.Errorf("parallel calls \n got %v\n want %v\n", max, 2)
+	}
+	if len(visited) != 6 {
+		t.Errorf("visited organizations \n got %v\n want %v\n", len(visited), 6)
+	}
+}
+
+func TestManager_Each_canceled(t *testing.T) {
+	m := New(nil)
+	m.AddClient("alpha", goqonto.NewClient(nil))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := m.Each(ctx, func(ctx context.Context, slug string, c *goqonto.Client) error {
+		return nil
+	})
+
+	var errs Errors
+	if !errors.As(err, &errs) || errs["alpha"] != context.Canceled {
+		t.Errorf("Each \n got %v\n want %v\n", err, context.Canceled)
+	}
+}
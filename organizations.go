@@ -4,11 +4,22 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"sort"
+	"time"
 )
 
 // organizationsBasePath Qonto API Organizations Endpoint
 const organizationsBasePath = "v2/organizations"
 
+// organizationBasePath Qonto API Organization Endpoint (organization of the authenticated credentials)
+const organizationBasePath = "v2/organization"
+
+// BankAccountStatusActive is a bank account that is open.
+const BankAccountStatusActive = "active"
+
+// BankAccountStatusClosed is a bank account that has been closed.
+const BankAccountStatusClosed = "closed"
+
 // OrganizationsService provides access to the organizations in Qonto API
 type OrganizationsService service
 
@@ -22,14 +33,71 @@ type Organization struct {
 // BankAccount struct
 // https://api-doc.qonto.eu/2.0/organizations/show-organization-1
 type BankAccount struct {
-	Slug                   string  `json:"slug,omitempty"`
-	IBAN                   string  `json:"iban"`
-	BIC                    string  `json:"bic"`
-	Currency               string  `json:"currency"`
-	Balance                float32 `json:"balance"`
-	BalanceCents           int     `json:"balance_cents"`
-	AuthorizedBalance      float32 `json:"authorized_balance"`
-	AuthorizedBalanceCents int     `json:"authorized_balance_cents"`
+	ID                     string    `json:"id,omitempty"`
+	Slug                   string    `json:"slug,omitempty"`
+	IBAN                   string    `json:"iban"`
+	BIC                    string    `json:"bic"`
+	Currency               string    `json:"currency"`
+	Balance                float64   `json:"balance"`
+	BalanceCents           int       `json:"balance_cents"`
+	AuthorizedBalance      float64   `json:"authorized_balance"`
+	AuthorizedBalanceCents int       `json:"authorized_balance_cents"`
+	Name                   string    `json:"name,omitempty"`
+	Status                 string    `json:"status,omitempty"`
+	Main                   bool      `json:"main,omitempty"`
+	UpdatedAt              time.Time `json:"updated_at"`
+}
+
+// CurrencyBalance holds the balances of all the bank accounts sharing the same currency
+type CurrencyBalance struct {
+	Currency               string `json:"currency"`
+	BalanceCents           int    `json:"balance_cents"`
+	AuthorizedBalanceCents int    `json:"authorized_balance_cents"`
+	Accounts               int    `json:"accounts"`
+}
+
+// BalanceSummary returns the total and authorized balances per currency across all the
+// bank accounts of the organization, sorted by currency. Closed bank accounts are ignored.
+func (o *Organization) BalanceSummary() []CurrencyBalance {
+	byCurrency := make(map[string]*CurrencyBalance)
+
+	for _, ba := range o.BankAccounts {
+		if ba.Status == BankAccountStatusClosed {
+			continue
+		}
+
+		cb, ok := byCurrency[ba.Currency]
+		if !ok {
+			cb = &CurrencyBalance{Currency: ba.Currency}
+			byCurrency[ba.Currency] = cb
+		}
+
+		cb.BalanceCents += ba.BalanceCents
+		cb.AuthorizedBalanceCents += ba.AuthorizedBalanceCents
+		cb.Accounts++
+	}
+
+	summary := make([]CurrencyBalance, 0, len(byCurrency))
+	for _, cb := range byCurrency {
+		summary = append(summary, *cb)
+	}
+
+	sort.Slice(summary, func(i, j int) bool {
+		return summary[i].Currency < summary[j].Currency
+	})
+
+	return summary
+}
+
+// MainBankAccount returns the main bank account of the organization, or nil if there is none
+func (o *Organization) MainBankAccount() *BankAccount {
+	for i := range o.BankAccounts {
+		if o.BankAccounts[i].Main {
+			return &o.BankAccounts[i]
+		}
+	}
+
+	return nil
 }
 
 // organizationRoot root key in the JSON response for organizations
@@ -42,6 +110,17 @@ func (s *OrganizationsService) Get(ctx context.Context, id string) (*Organizatio
 
 	path := fmt.Sprintf("%s/%s", organizationsBasePath, id)
 
+	return s.get(ctx, path)
+}
+
+// Current returns the Organization of the authenticated credentials
+// https://api-doc.qonto.eu/2.0/organizations/show-organization-1
+func (s *OrganizationsService) Current(ctx context.Context) (*Organization, *Response, error) {
+	return s.get(ctx, organizationBasePath)
+}
+
+func (s *OrganizationsService) get(ctx context.Context, path string) (*Organization, *Response, error) {
+
 	req, err := s.client.NewRequest(ctx, http.MethodGet, path, nil)
 	if err != nil {
 		return nil, nil, err
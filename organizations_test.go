@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"reflect"
 	"testing"
+	"time"
 )
 
 var (
@@ -41,6 +42,131 @@ var (
 		Slug:         "croissant-9134",
 		BankAccounts: []BankAccount{bankAccount},
 	}
+
+	currentOrganizationFixture = `{
+		"organization": {
+			"slug": "croissant-9134",
+			"bank_accounts": [
+				{
+					"slug": "croissant-bank-account-1",
+					"iban": "FR7616798000010000004321396",
+					"bic": "TRZOFR21XXX",
+					"currency": "EUR",
+					"balance": 225.3,
+					"balance_cents": 22530,
+					"authorized_balance": 213.2,
+					"authorized_balance_cents": 21320,
+					"name": "Main account",
+					"status": "active",
+					"main": true,
+					"updated_at": "2021-03-12T10:15:02.123Z"
+				},
+				{
+					"slug": "croissant-bank-account-2",
+					"iban": "FR7616798000010000004321397",
+					"bic": "TRZOFR21XXX",
+					"currency": "EUR",
+					"balance": 1000.5,
+					"balance_cents": 100050,
+					"authorized_balance": 900.5,
+					"authorized_balance_cents": 90050,
+					"name": "Savings",
+					"status": "active",
+					"main": false,
+					"updated_at": "2021-03-12T10:15:02.123Z"
+				},
+				{
+					"slug": "croissant-bank-account-3",
+					"iban": "FR7616798000010000004321398",
+					"bic": "TRZOFR21XXX",
+					"currency": "USD",
+					"balance": 50,
+					"balance_cents": 5000,
+					"authorized_balance": 50,
+					"authorized_balance_cents": 5000,
+					"name": "US account",
+					"status": "active",
+					"main": false,
+					"updated_at": "2021-03-12T10:15:02.123Z"
+				},
+				{
+					"slug": "croissant-bank-account-4",
+					"iban": "FR7616798000010000004321399",
+					"bic": "TRZOFR21XXX",
+					"currency": "EUR",
+					"balance": 10,
+					"balance_cents": 1000,
+					"authorized_balance": 10,
+					"authorized_balance_cents": 1000,
+					"name": "Old account",
+					"status": "closed",
+					"main": false,
+					"updated_at": "2021-03-12T10:15:02.123Z"
+				}
+			]
+		}
+	}`
+
+	bankAccountsUpdatedAt, _ = time.Parse(time.RFC3339, "2021-03-12T10:15:02.123Z")
+
+	currentOrganization = Organization{
+		Slug: "croissant-9134",
+		BankAccounts: []BankAccount{
+			{
+				Slug:                   "croissant-bank-account-1",
+				IBAN:                   "FR7616798000010000004321396",
+				BIC:                    "TRZOFR21XXX",
+				Currency:               "EUR",
+				Balance:                225.3,
+				BalanceCents:           22530,
+				AuthorizedBalance:      213.2,
+				AuthorizedBalanceCents: 21320,
+				Name:                   "Main account",
+				Status:                 BankAccountStatusActive,
+				Main:                   true,
+				UpdatedAt:              bankAccountsUpdatedAt,
+			},
+			{
+				Slug:                   "croissant-bank-account-2",
+				IBAN:                   "FR7616798000010000004321397",
+				BIC:                    "TRZOFR21XXX",
+				Currency:               "EUR",
+				Balance:                1000.5,
+				BalanceCents:           100050,
+				AuthorizedBalance:      900.5,
+				AuthorizedBalanceCents: 90050,
+				Name:                   "Savings",
+				Status:                 BankAccountStatusActive,
+				UpdatedAt:              bankAccountsUpdatedAt,
+			},
+			{
+				Slug:                   "croissant-bank-account-3",
+				IBAN:                   "FR7616798000010000004321398",
+				BIC:                    "TRZOFR21XXX",
+				Currency:               "USD",
+				Balance:                50,
+				BalanceCents:           5000,
+				AuthorizedBalance:      50,
+				AuthorizedBalanceCents: 5000,
+				Name:                   "US account",
+				Status:                 BankAccountStatusActive,
+				UpdatedAt:              bankAccountsUpdatedAt,
+			},
+			{
+				Slug:                   "croissant-bank-account-4",
+				IBAN:                   "FR7616798000010000004321399",
+				BIC:                    "TRZOFR21XXX",
+				Currency:               "EUR",
+				Balance:                10,
+				BalanceCents:           1000,
+				AuthorizedBalance:      10,
+				AuthorizedBalanceCents: 1000,
+				Name:                   "Old account",
+				Status:                 BankAccountStatusClosed,
+				UpdatedAt:              bankAccountsUpdatedAt,
+			},
+		},
+	}
 )
 
 func TestOrganization_marshall(t *testing.T) {
@@ -115,3 +241,78 @@ func TestOrganizationsService_Get_Error(t *testing.T) {
 		t.Errorf("Expected empty body")
 	}
 }
+
+func TestOrganizationsService_Current(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc(fmt.Sprintf("/%s", organizationBasePath), func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, http.MethodGet)
+		testHeader(t, r, "Accept", mediaType)
+		testHeader(t, r, "Content-Type", mediaType)
+		fmt.Fprint(w, currentOrganizationFixture)
+	})
+
+	got, _, err := client.Organizations.Current(ctx)
+	if err != nil {
+		t.Errorf("Organizations.Current returned error: %v", err)
+	}
+
+	want := &currentOrganization
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Organizations.Current \n got %v\n want %v\n", got, want)
+	}
+}
+
+func TestOrganizationsService_Current_Error(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		fmt.Fprint(w, `{ "message": "Unauthorized" }`)
+	})
+
+	got, resp, err := client.Organizations.Current(ctx)
+
+	if err == nil {
+		t.Fatalf("Expected error to be returned")
+	}
+
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("Expected 401 Status")
+	}
+
+	if got != nil {
+		t.Errorf("Expected empty body")
+	}
+}
+
+func TestOrganization_BalanceSummary(t *testing.T) {
+	got := currentOrganization.BalanceSummary()
+
+	want := []CurrencyBalance{
+		{Currency: "EUR", BalanceCents: 122580, AuthorizedBalanceCents: 111370, Accounts: 2},
+		{Currency: "USD", BalanceCents: 5000, AuthorizedBalanceCents: 5000, Accounts: 1},
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Organization.BalanceSummary \n got %v\n want %v\n", got, want)
+	}
+
+	if got := (&Organization{}).BalanceSummary(); len(got) != 0 {
+		t.Errorf("Organization.BalanceSummary on empty organization got %v, want empty", got)
+	}
+}
+
+func TestOrganization_MainBankAccount(t *testing.T) {
+	got := currentOrganization.MainBankAccount()
+	if got == nil || got.Slug != "croissant-bank-account-1" {
+		t.Errorf("Organization.MainBankAccount got %v, want croissant-bank-account-1", got)
+	}
+
+	if got := organization.MainBankAccount(); got != nil {
+		t.Errorf("Organization.MainBankAccount got %v, want nil", got)
+	}
+}
@@ -0,0 +1,325 @@
+// Package pain parses ISO 20022 pain.001 customer credit transfer initiation files and submits
+// the transfer instructions they contain through the Qonto API
+package pain
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pixelfactoryio/goqonto/v2"
+	"github.com/pixelfactoryio/goqonto/v2/iban"
+)
+
+// Namespaces of the supported pain.001 versions
+const (
+	NamespacePain00100103 = "urn:iso:std:iso:20022:tech:xsd:pain.001.001.03"
+	NamespacePain00100109 = "urn:iso:std:iso:20022:tech:xsd:pain.001.001.09"
+)
+
+// dateLayout ISO date layout
+const dateLayout = "2006-01-02"
+
+// ErrUnsupportedVersion is returned when the document is not a pain.001.001.03 or pain.001.001.09 file
+var ErrUnsupportedVersion = errors.New("pain: unsupported document version")
+
+var (
+	bicPattern = regexp.MustCompile(`^[A-Z]{6}[A-Z2-9][A-NP-Z0-9]([A-Z0-9]{3})?$`)
+
+	// amountPattern amounts of at most 18 digits, whose cents fit in an int64
+	amountPattern = regexp.MustCompile(`^[0-9]{1,16}(\.[0-9]{1,2})?$`)
+
+	// controlSumPattern ISO 20022 DecimalNumber, with up to 17 fraction digits
+	controlSumPattern = regexp.MustCompile(`^[0-9]{1,18}(\.[0-9]{1,17})?$`)
+)
+
+// Instruction outgoing transfer instruction
+type Instruction struct {
+	// Identification of the message and of the payment information block.
+	MessageID            string
+	PaymentInformationID string
+
+	// Point to point and end to end references of the transfer.
+	InstructionID string
+	EndToEndID    string
+
+	DebtorName string
+	DebtorIBAN string
+	DebtorBIC  string
+
+	CreditorName string
+	CreditorIBAN string
+	CreditorBIC  string
+
+	AmountCents int
+	Currency    string
+
+	RequestedExecutionDate time.Time
+
+	// Unstructured remittance information.
+	RemittanceInformation string
+}
+
+// Amount returns the amount as a decimal string
+func (i Instruction) Amount() string {
+	return fmt.Sprintf("%d.%02d", i.AmountCents/100, i.AmountCents%100)
+}
+
+// ValidationError lists the problems found in an instruction
+type ValidationError struct {
+	EndToEndID string
+	Problems   []string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("pain: instruction %s: %s", e.EndToEndID, strings.Join(e.Problems, "; "))
+}
+
+// Validate checks the IBANs and BICs of the instruction and that its debtor IBAN is one of the
+// organization bank accounts. It returns a *ValidationError.
+func (i Instruction) Validate(org goqonto.Organization) error {
+	var problems []string
+
+	account := findBankAccount(org, i.DebtorIBAN)
+
+	switch err := iban.Validate(i.DebtorIBAN); {
+	case err != nil:
+		problems = append(problems, fmt.Sprintf("debtor IBAN %s: %v", i.DebtorIBAN, err))
+	case account == nil:
+		problems = append(problems, fmt.Sprintf("debtor IBAN %s is not a bank account of %s", i.DebtorIBAN, org.Slug))
+	case account.Currency != i.Currency:
+		problems = append(problems, fmt.Sprintf("currency %s does not match bank account currency %s",
+			i.Currency, account.Currency))
+	}
+
+	if err := iban.Validate(i.CreditorIBAN); err != nil {
+		problems = append(problems, fmt.Sprintf("creditor IBAN %s: %v", i.CreditorIBAN, err))
+	}
+
+	if i.CreditorBIC != "" && !bicPattern.MatchString(i.CreditorBIC) {
+		problems = append(problems, fmt.Sprintf("invalid creditor BIC %s", i.CreditorBIC))
+	}
+
+	if i.CreditorName == "" {
+		problems = append(problems, "missing creditor name")
+	}
+
+	if i.AmountCents <= 0 {
+		problems = append(problems, "amount must be positive")
+	}
+
+	if len(problems) > 0 {
+		return &ValidationError{EndToEndID: i.EndToEndID, Problems: problems}
+	}
+
+	return nil
+}
+
+// findBankAccount returns the bank account of the organization with the given IBAN
+func findBankAccount(org goqonto.Organization, accountIBAN string) *goqonto.BankAccount {
+	for k := range org.BankAccounts {
+		if iban.Normalize(org.BankAccounts[k].IBAN) == accountIBAN {
+			return &org.BankAccounts[k]
+		}
+	}
+	return nil
+}
+
+// document pain.001 document, common to the .03 and .09 versions
+type document struct {
+	XMLName    xml.Name `xml:"Document"`
+	Namespace  string   `xml:"xmlns,attr"`
+	Initiation struct {
+		GroupHeader struct {
+			MessageID            string `xml:"MsgId"`
+			NumberOfTransactions string `xml:"NbOfTxs"`
+			ControlSum           string `xml:"CtrlSum"`
+		} `xml:"GrpHdr"`
+		Payments []paymentInformation `xml:"PmtInf"`
+	} `xml:"CstmrCdtTrfInitn"`
+}
+
+type paymentInformation struct {
+	ID                     string `xml:"PmtInfId"`
+	NumberOfTransactions   string `xml:"NbOfTxs"`
+	ControlSum             string `xml:"CtrlSum"`
+	RequestedExecutionDate struct {
+		// pain.001.001.03 holds the date directly, pain.001.001.09 holds a Dt or DtTm choice.
+		Value    string `xml:",chardata"`
+		Date     string `xml:"Dt"`
+		DateTime string `xml:"DtTm"`
+	} `xml:"ReqdExctnDt"`
+	DebtorName  string               `xml:"Dbtr>Nm"`
+	DebtorIBAN  string               `xml:"DbtrAcct>Id>IBAN"`
+	DebtorBIC   string               `xml:"DbtrAgt>FinInstnId>BIC"`
+	DebtorBICFI string               `xml:"DbtrAgt>FinInstnId>BICFI"`
+	Transfers   []creditTransferInfo `xml:"CdtTrfTxInf"`
+}
+
+type creditTransferInfo struct {
+	InstructionID string `xml:"PmtId>InstrId"`
+	EndToEndID    string `xml:"PmtId>EndToEndId"`
+	Amount        struct {
+		Value    string `xml:",chardata"`
+		Currency string `xml:"Ccy,attr"`
+	} `xml:"Amt>InstdAmt"`
+	CreditorBIC   string   `xml:"CdtrAgt>FinInstnId>BIC"`
+	CreditorBICFI string   `xml:"CdtrAgt>FinInstnId>BICFI"`
+	CreditorName  string   `xml:"Cdtr>Nm"`
+	CreditorIBAN  string   `xml:"CdtrAcct>Id>IBAN"`
+	Unstructured  []string `xml:"RmtInf>Ustrd"`
+}
+
+// Parse reads a pain.001.001.03 or pain.001.001.09 document and returns its transfer instructions.
+// It checks the number of transactions and control sums declared in the group header and the
+// payment information blocks.
+func Parse(r io.Reader) ([]Instruction, error) {
+	doc := new(document)
+	if err := xml.NewDecoder(r).Decode(doc); err != nil {
+		return nil, fmt.Errorf("pain: %v", err)
+	}
+
+	if doc.Namespace != NamespacePain00100103 && doc.Namespace != NamespacePain00100109 {
+		return nil, fmt.Errorf("%w: %q", ErrUnsupportedVersion, doc.Namespace)
+	}
+
+	header := doc.Initiation.GroupHeader
+
+	var instructions []Instruction
+	for _, p := range doc.Initiation.Payments {
+		date, err := parseExecutionDate(p)
+		if err != nil {
+			return nil, fmt.Errorf("pain: payment information %s: %v", p.ID, err)
+		}
+
+		var sum int
+		for _, tx := range p.Transfers {
+			cents, err := parseAmount(tx.Amount.Value)
+			if err != nil {
+				return nil, fmt.Errorf("pain: transaction %s: %v", tx.EndToEndID, err)
+			}
+			sum += cents
+
+			instructions = append(instructions, Instruction{
+				MessageID:              header.MessageID,
+				PaymentInformationID:   p.ID,
+				InstructionID:          tx.InstructionID,
+				EndToEndID:             tx.EndToEndID,
+				DebtorName:             p.DebtorName,
+				DebtorIBAN:             iban.Normalize(p.DebtorIBAN),
+				DebtorBIC:              firstNonEmpty(p.DebtorBIC, p.DebtorBICFI),
+				CreditorName:           strings.TrimSpace(tx.CreditorName),
+				CreditorIBAN:           iban.Normalize(tx.CreditorIBAN),
+				CreditorBIC:            firstNonEmpty(tx.CreditorBIC, tx.CreditorBICFI),
+				AmountCents:            cents,
+				Currency:               tx.Amount.Currency,
+				RequestedExecutionDate: date,
+				RemittanceInformation:  strings.Join(tx.Unstructured, " "),
+			})
+		}
+
+		scope := "payment information " + p.ID
+		if err := checkTotals(scope, p.NumberOfTransactions, p.ControlSum, len(p.Transfers), sum); err != nil {
+			return nil, err
+		}
+	}
+
+	var total int
+	for _, i := range instructions {
+		total += i.AmountCents
+	}
+
+	err := checkTotals("group header", header.NumberOfTransactions, header.ControlSum, len(instructions), total)
+	if err != nil {
+		return nil, err
+	}
+
+	return instructions, nil
+}
+
+// checkTotals compares the declared number of transactions and control sum with the actual ones
+func checkTotals(scope, declaredCount, declaredSum string, count, sum int) error {
+	if declaredCount != "" {
+		n, err := strconv.Atoi(strings.TrimSpace(declaredCount))
+		if err != nil || n != count {
+			return fmt.Errorf("pain: %s declares %s transactions, found %d", scope, declaredCount, count)
+		}
+	}
+
+	if declaredSum != "" {
+		cents, err := parseControlSum(declaredSum)
+		if err != nil || cents != sum {
+			return fmt.Errorf("pain: %s declares a control sum of %s, found %d.%02d", scope, declaredSum, sum/100, sum%100)
+		}
+	}
+
+	return nil
+}
+
+func parseExecutionDate(p paymentInformation) (time.Time, error) {
+	d := p.RequestedExecutionDate
+
+	switch {
+	case d.Date != "":
+		return time.Parse(dateLayout, strings.TrimSpace(d.Date))
+	case d.DateTime != "":
+		return time.Parse(time.RFC3339, strings.TrimSpace(d.DateTime))
+	case strings.TrimSpace(d.Value) != "":
+		return time.Parse(dateLayout, strings.TrimSpace(d.Value))
+	}
+
+	return time.Time{}, errors.New("no requested execution date")
+}
+
+// parseAmount parses a decimal amount with at most 2 decimals into cents, without floating point rounding
+func parseAmount(s string) (int, error) {
+	s = strings.TrimSpace(s)
+	if !amountPattern.MatchString(s) {
+		return 0, fmt.Errorf("invalid amount %q", s)
+	}
+
+	parts := strings.SplitN(s, ".", 2)
+	units, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return 0, fmt.Errorf("invalid amount %q", s)
+	}
+
+	cents := 0
+	if len(parts) == 2 {
+		decimals := parts[1]
+		if len(decimals) == 1 {
+			decimals += "0"
+		}
+		cents, _ = strconv.Atoi(decimals)
+	}
+
+	return units*100 + cents, nil
+}
+
+// parseControlSum parses a control sum into cents. Control sums may have more than 2 decimals, the
+// trailing zeros are ignored.
+func parseControlSum(s string) (int, error) {
+	s = strings.TrimSpace(s)
+	if !controlSumPattern.MatchString(s) {
+		return 0, fmt.Errorf("invalid control sum %q", s)
+	}
+
+	if strings.Contains(s, ".") {
+		s = strings.TrimSuffix(strings.TrimRight(s, "0"), ".")
+	}
+	return parseAmount(s)
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v = strings.TrimSpace(v); v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,266 @@
+package pain
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pixelfactoryio/goqonto/v2"
+)
+
+var organization = goqonto.Organization{
+	Slug: "croissant-9134",
+	BankAccounts: []goqonto.BankAccount{
+		{
+			ID:       "0d4c2b5e-0ca0-4d38-9bb7-1a4c1e2bc0a9",
+			Slug:     "croissant-bank-account-1",
+			IBAN:     "FR6416798000010000004321396",
+			Currency: "EUR",
+		},
+		{
+			Slug:     "croissant-bank-account-2",
+			IBAN:     "FR3716798000010000004321397",
+			Currency: "EUR",
+		},
+	},
+}
+
+func parseFile(t *testing.T, name string) []Instruction {
+	t.Helper()
+
+	f, err := os.Open(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatalf("Unable to open %s: %v", name, err)
+	}
+	defer f.Close()
+
+	instructions, err := Parse(f)
+	if err != nil {
+		t.Fatalf("Parse(%s) returned error: %v", name, err)
+	}
+
+	return instructions
+}
+
+func date(value string) time.Time {
+	d, _ := time.Parse(dateLayout, value)
+	return d
+}
+
+func TestParse_v03(t *testing.T) {
+	got := parseFile(t, "pain.001.001.03.xml")
+
+	want := []Instruction{
+		{
+			MessageID:              "TREASURY-20210312-001",
+			PaymentInformationID:   "PMT-1",
+			InstructionID:          "INSTR-1",
+			EndToEndID:             "E2E-1",
+			DebtorName:             "Croissant SAS",
+			DebtorIBAN:             "FR6416798000010000004321396",
+			DebtorBIC:              "QNTOFRP1XXX",
+			CreditorName:           "ACME Corp",
+			CreditorIBAN:           "FR1420041010050500013M02606",
+			CreditorBIC:            "PSSTFRPPLIL",
+			AmountCents:            15050,
+			Currency:               "EUR",
+			RequestedExecutionDate: date("2021-03-15"),
+			RemittanceInformation:  "Invoice 42",
+		},
+		{
+			MessageID:              "TREASURY-20210312-001",
+			PaymentInformationID:   "PMT-1",
+			EndToEndID:             "E2E-2",
+			DebtorName:             "Croissant SAS",
+			DebtorIBAN:             "FR6416798000010000004321396",
+			DebtorBIC:              "QNTOFRP1XXX",
+			CreditorName:           "Bäckerei Müller",
+			CreditorIBAN:           "DE89370400440532013000",
+			AmountCents:            100000,
+			Currency:               "EUR",
+			RequestedExecutionDate: date("2021-03-15"),
+		},
+		{
+			MessageID:              "TREASURY-20210312-001",
+			PaymentInformationID:   "PMT-2",
+			EndToEndID:             "E2E-3",
+			DebtorName:             "Croissant SAS",
+			DebtorIBAN:             "FR3716798000010000004321397",
+			DebtorBIC:              "QNTOFRP1XXX",
+			CreditorName:           "Landlord",
+			CreditorIBAN:           "FR1420041010050500013M02607",
+			AmountCents:            50100,
+			Currency:               "EUR",
+			RequestedExecutionDate: date("2021-03-01"),
+			RemittanceInformation:  "Rent March",
+		},
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Parse \n got %+v\n want %+v\n", got, want)
+	}
+}
+
+func TestParse_v09(t *testing.T) {
+	got := parseFile(t, "pain.001.001.09.xml")
+
+	want := []Instruction{
+		{
+			MessageID:              "TREASURY-20210312-002",
+			PaymentInformationID:   "PMT-9",
+			InstructionID:          "INSTR-9",
+			EndToEndID:             "E2E-9",
+			DebtorName:             "Croissant SAS",
+			DebtorIBAN:             "FR6416798000010000004321396",
+			DebtorBIC:              "QNTOFRP1XXX",
+			CreditorName:           "ACME Corp",
+			CreditorIBAN:           "FR1420041010050500013M02606",
+			CreditorBIC:            "PSSTFRPPLIL",
+			AmountCents:            9990,
+			Currency:               "EUR",
+			RequestedExecutionDate: date("2021-03-20"),
+			RemittanceInformation:  "Invoice 43",
+		},
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Parse \n got %+v\n want %+v\n", got, want)
+	}
+}
+
+func TestParse_errors(t *testing.T) {
+	data, err := ioutil.ReadFile(filepath.Join("testdata", "pain.001.001.03.xml"))
+	if err != nil {
+		t.Fatalf("Unable to read test file: %v", err)
+	}
+	doc := string(data)
+
+	tests := map[string]string{
+		"unsupported version":  strings.Replace(doc, "pain.001.001.03", "pain.001.001.02", 1),
+		"group control sum":    strings.Replace(doc, "<CtrlSum>1651.5</CtrlSum>", "<CtrlSum>1651.6</CtrlSum>", 1),
+		"group count":          strings.Replace(doc, "<NbOfTxs>3</NbOfTxs>", "<NbOfTxs>4</NbOfTxs>", 1),
+		"payment control sum":  strings.Replace(doc, "<CtrlSum>1150.50</CtrlSum>", "<CtrlSum>1150.00</CtrlSum>", 1),
+		"invalid amount":       strings.Replace(doc, "150.50</InstdAmt>", "150.505</InstdAmt>", 1),
+		"missing date":         strings.Replace(doc, "<ReqdExctnDt>2021-03-01</ReqdExctnDt>", "", 1),
+		"invalid date":         strings.Replace(doc, "2021-03-01</ReqdExctnDt>", "01/03/2021</ReqdExctnDt>", 1),
+		"sub-cent control sum": strings.Replace(doc, "<CtrlSum>1150.50</CtrlSum>", "<CtrlSum>1150.505</CtrlSum>", 1),
+		"invalid XML":          doc[:100],
+	}
+
+	for name, doc := range tests {
+		_, err := Parse(strings.NewReader(doc))
+		if err == nil {
+			t.Errorf("%s: expected error to be returned", name)
+			continue
+		}
+		if !strings.HasPrefix(err.Error(), "pain: ") {
+			t.Errorf("%s: error %q has no pain prefix", name, err)
+		}
+	}
+
+	_, err = Parse(strings.NewReader(tests["unsupported version"]))
+	if !errors.Is(err, ErrUnsupportedVersion) {
+		t.Errorf("Parse error got %v, want %v", err, ErrUnsupportedVersion)
+	}
+}
+
+func TestParseAmount(t *testing.T) {
+	tests := map[string]int{
+		"0.01":     1,
+		"1":        100,
+		"1.5":      150,
+		"1651.50":  165150,
+		" 12.34 ":  1234,
+		"19.99":    1999,
+		"1000.10":  100010,
+		"99999.99": 9999999,
+
+		"9999999999999999.99": 999999999999999999,
+	}
+
+	for s, want := range tests {
+		got, err := parseAmount(s)
+		if err != nil || got != want {
+			t.Errorf("parseAmount(%q) got %d, %v, want %d", s, got, err, want)
+		}
+	}
+
+	for _, s := range []string{"", "1,5", "-1", "1.234", "abc", "10000000000000000", "999999999999999999"} {
+		if _, err := parseAmount(s); err == nil {
+			t.Errorf("parseAmount(%q) expected error to be returned", s)
+		}
+	}
+}
+
+func TestParse_controlSumDecimals(t *testing.T) {
+	data, err := ioutil.ReadFile(filepath.Join("testdata", "pain.001.001.03.xml"))
+	if err != nil {
+		t.Fatalf("Unable to read test file: %v", err)
+	}
+	doc := strings.Replace(string(data), "<CtrlSum>1651.5</CtrlSum>", "<CtrlSum>1651.500</CtrlSum>", 1)
+	doc = strings.Replace(doc, "<CtrlSum>1150.50</CtrlSum>", "<CtrlSum>1150.50000000000000000</CtrlSum>", 1)
+
+	if _, err := Parse(strings.NewReader(doc)); err != nil {
+		t.Errorf("Parse returned error: %v", err)
+	}
+}
+
+func TestParseControlSum(t *testing.T) {
+	tests := map[string]int{
+		"100":                 10000,
+		"100.5":               10050,
+		"100.500":             10050,
+		"100.000":             10000,
+		"0.01000000000000000": 1,
+	}
+
+	for s, want := range tests {
+		got, err := parseControlSum(s)
+		if err != nil || got != want {
+			t.Errorf("parseControlSum(%q) got %d, %v, want %d", s, got, err, want)
+		}
+	}
+
+	for _, s := range []string{"", "1.005", "1.000000000000000000", "-1", "abc"} {
+		if _, err := parseControlSum(s); err == nil {
+			t.Errorf("parseControlSum(%q) expected error to be returned", s)
+		}
+	}
+}
+
+func TestInstruction_Validate(t *testing.T) {
+	instructions := parseFile(t, "pain.001.001.03.xml")
+
+	if err := instructions[0].Validate(organization); err != nil {
+		t.Errorf("Validate returned error: %v", err)
+	}
+
+	// Invalid creditor IBAN checksum.
+	err := instructions[2].Validate(organization)
+	verr, ok := err.(*ValidationError)
+	if !ok || len(verr.Problems) != 1 || !strings.Contains(verr.Problems[0], "creditor IBAN") {
+		t.Errorf("Validate error got %v, want a creditor IBAN problem", err)
+	}
+
+	i := instructions[0]
+	i.DebtorIBAN = "DE89370400440532013000"
+	i.CreditorBIC = "not-a-bic"
+	i.CreditorName = ""
+	i.AmountCents = 0
+
+	verr, ok = i.Validate(organization).(*ValidationError)
+	if !ok || len(verr.Problems) != 4 {
+		t.Errorf("Validate error got %v, want 4 problems", verr)
+	}
+
+	i = instructions[0]
+	i.Currency = "USD"
+	if err := i.Validate(organization); err == nil {
+		t.Errorf("Expected currency mismatch to be reported")
+	}
+}
@@ -0,0 +1,139 @@
+package pain
+
+import (
+	"context"
+	"encoding/csv"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/pixelfactoryio/goqonto/v2"
+)
+
+// Result outcome of the submission of an instruction
+type Result struct {
+	Instruction Instruction
+
+	// Transfer created through the API, nil if the submission failed.
+	Transfer *goqonto.Transfer
+
+	// Validation or API error.
+	Err error
+}
+
+// Report results of the submission of instructions, in submission order
+type Report struct {
+	Results []Result
+}
+
+// Failed returns the results of the instructions that could not be submitted
+func (r *Report) Failed() []Result {
+	var failed []Result
+	for _, res := range r.Results {
+		if res.Err != nil {
+			failed = append(failed, res)
+		}
+	}
+	return failed
+}
+
+// WriteCSV writes the report as CSV with an end_to_end_id,amount,creditor_iban,status,transfer_id,error header
+func (r *Report) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+
+	header := []string{"end_to_end_id", "amount", "creditor_iban", "status", "transfer_id", "error"}
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+
+	for _, res := range r.Results {
+		record := []string{res.Instruction.EndToEndID, res.Instruction.Amount(), res.Instruction.CreditorIBAN, "", "", ""}
+		if res.Err != nil {
+			record[3] = "failed"
+			record[5] = res.Err.Error()
+		} else {
+			record[3] = res.Transfer.Status
+			record[4] = res.Transfer.ID
+		}
+
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// Submitter creates the transfers of pain.001 instructions through the Qonto API
+type Submitter struct {
+	Client       *goqonto.Client
+	Organization goqonto.Organization
+
+	// Now returns the current time, used to decide whether the requested execution date is in the
+	// future. Defaults to time.Now.
+	Now func() time.Time
+}
+
+// Submit validates and creates the transfer of every instruction. Failures do not stop the
+// submission of the following instructions, except for the cancellation of ctx. Each transfer is
+// created with an idempotency key derived from the instruction references, so that a file can be
+// submitted again after a partial failure without creating duplicates.
+func (s *Submitter) Submit(ctx context.Context, instructions []Instruction) *Report {
+	report := &Report{}
+
+	for _, i := range instructions {
+		res := Result{Instruction: i}
+
+		if err := ctx.Err(); err != nil {
+			res.Err = err
+		} else if err := i.Validate(s.Organization); err != nil {
+			res.Err = err
+		} else {
+			res.Transfer, _, res.Err = s.Client.Transfers.Create(ctx, s.transferRequest(i))
+		}
+
+		report.Results = append(report.Results, res)
+	}
+
+	return report
+}
+
+func (s *Submitter) transferRequest(i Instruction) *goqonto.TransferRequest {
+	account := findBankAccount(s.Organization, i.DebtorIBAN)
+
+	req := &goqonto.TransferRequest{
+		BankAccountID: account.ID,
+		Beneficiary: goqonto.TransferBeneficiary{
+			Name: i.CreditorName,
+			IBAN: i.CreditorIBAN,
+			BIC:  i.CreditorBIC,
+		},
+		Amount:    i.Amount(),
+		Currency:  i.Currency,
+		Reference: i.RemittanceInformation,
+		IdempotencyKey: strings.Join([]string{
+			i.MessageID, i.PaymentInformationID, i.InstructionID, i.EndToEndID,
+		}, "/"),
+	}
+
+	if req.BankAccountID == "" {
+		req.DebitIBAN = account.IBAN
+	}
+
+	if req.Reference == "" {
+		req.Reference = i.EndToEndID
+	}
+
+	now := time.Now
+	if s.Now != nil {
+		now = s.Now
+	}
+
+	// Past dates are executed as soon as possible.
+	if today := now().Format(dateLayout); i.RequestedExecutionDate.Format(dateLayout) > today {
+		req.ScheduledDate = i.RequestedExecutionDate.Format(dateLayout)
+	}
+
+	return req
+}
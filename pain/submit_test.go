@@ -0,0 +1,130 @@
+package pain
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pixelfactoryio/goqonto/v2"
+)
+
+func TestSubmitter_Submit(t *testing.T) {
+	var requests []map[string]interface{}
+	var keys []string
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v2/external_transfers", func(w http.ResponseWriter, r *http.Request) {
+		body := map[string]map[string]interface{}{}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Fatalf("Unable to decode request body: %v", err)
+		}
+		transfer := body["external_transfer"]
+		requests = append(requests, transfer)
+		keys = append(keys, r.Header.Get("X-Qonto-Idempotency-Key"))
+
+		if transfer["amount"] == "1000.00" {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			fmt.Fprint(w, `{"message":"Insufficient funds"}`)
+			return
+		}
+
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprintf(w, `{"external_transfer":{"id":"transfer-%d","status":"pending"}}`, len(requests))
+	})
+
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	client, err := goqonto.New(nil, goqonto.SetBaseURL(server.URL))
+	if err != nil {
+		t.Fatalf("New returned error: %v", err)
+	}
+
+	s := &Submitter{
+		Client:       client,
+		Organization: organization,
+		Now:          func() time.Time { return date("2021-03-12") },
+	}
+
+	instructions := append(parseFile(t, "pain.001.001.03.xml"), parseFile(t, "pain.001.001.09.xml")...)
+	report := s.Submit(context.Background(), instructions)
+
+	if got := len(report.Results); got != 4 {
+		t.Fatalf("Submit got %d results, want 4", got)
+	}
+
+	if got := len(report.Failed()); got != 2 {
+		t.Errorf("Submit got %d failures, want 2", got)
+	}
+
+	// E2E-3 has an invalid creditor IBAN and is not sent to the API.
+	if got := len(requests); got != 3 {
+		t.Fatalf("Submit sent %d requests, want 3", got)
+	}
+
+	first := requests[0]
+	if first["bank_account_id"] != "0d4c2b5e-0ca0-4d38-9bb7-1a4c1e2bc0a9" || first["scheduled_date"] != "2021-03-15" ||
+		first["reference"] != "Invoice 42" || first["amount"] != "150.50" {
+		t.Errorf("Submit first request got %v", first)
+	}
+
+	if keys[0] != "TREASURY-20210312-001/PMT-1/INSTR-1/E2E-1" {
+		t.Errorf("Submit idempotency key got %s", keys[0])
+	}
+
+	if second := requests[1]; second["reference"] != "E2E-2" {
+		t.Errorf("Submit second request reference got %v, want E2E-2", second["reference"])
+	}
+
+	var buf bytes.Buffer
+	if err := report.WriteCSV(&buf); err != nil {
+		t.Fatalf("WriteCSV returned error: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	want := []string{
+		"end_to_end_id,amount,creditor_iban,status,transfer_id,error",
+		"E2E-1,150.50,FR1420041010050500013M02606,pending,transfer-1,",
+		"E2E-2,1000.00,DE89370400440532013000,failed,,",
+		"E2E-3,501.00,FR1420041010050500013M02607,failed,,",
+		"E2E-9,99.90,FR1420041010050500013M02606,pending,transfer-3,",
+	}
+	for i, prefix := range want {
+		if !strings.HasPrefix(lines[i], prefix) {
+			t.Errorf("WriteCSV line %d got %s, want prefix %s", i, lines[i], prefix)
+		}
+	}
+}
+
+func TestSubmitter_Submit_debitIBAN(t *testing.T) {
+	s := &Submitter{Organization: organization}
+
+	i := parseFile(t, "pain.001.001.03.xml")[2]
+	req := s.transferRequest(i)
+
+	if req.BankAccountID != "" || req.DebitIBAN != "FR3716798000010000004321397" {
+		t.Errorf("transferRequest got bank account %q and debit IBAN %q", req.BankAccountID, req.DebitIBAN)
+	}
+
+	if req.ScheduledDate != "" {
+		t.Errorf("transferRequest scheduled date got %s, want none for a past date", req.ScheduledDate)
+	}
+}
+
+func TestSubmitter_Submit_canceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	s := &Submitter{Client: goqonto.NewClient(nil), Organization: organization}
+	report := s.Submit(ctx, parseFile(t, "pain.001.001.09.xml"))
+
+	if got := report.Results[0].Err; got != context.Canceled {
+		t.Errorf("Submit error got %v, want %v", got, context.Canceled)
+	}
+}
@@ -0,0 +1,466 @@
+package qontotest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pixelfactoryio/goqonto/v2"
+)
+
+// meta pagination metadata, with null previous and next pages on the first and last pages
+type meta struct {
+	CurrentPage int  `json:"current_page"`
+	NextPage    *int `json:"next_page"`
+	PrevPage    *int `json:"prev_page"`
+	TotalPages  int  `json:"total_pages"`
+	TotalCount  int  `json:"total_count"`
+	PerPage     int  `json:"per_page"`
+}
+
+// paginate returns the bounds of the requested page of count items and its metadata
+func paginate(count int, page, perPage int64) (int, int, meta) {
+	if page < 1 {
+		page = 1
+	}
+	if perPage < 1 {
+		perPage = defaultPerPage
+	}
+
+	m := meta{
+		CurrentPage: int(page),
+		TotalCount:  count,
+		PerPage:     int(perPage),
+		TotalPages:  (count + int(perPage) - 1) / int(perPage),
+	}
+
+	if m.CurrentPage > 1 {
+		prev := m.CurrentPage - 1
+		m.PrevPage = &prev
+	}
+	if m.CurrentPage < m.TotalPages {
+		next := m.CurrentPage + 1
+		m.NextPage = &next
+	}
+
+	start := (m.CurrentPage - 1) * m.PerPage
+	if start > count {
+		start = count
+	}
+	end := start + m.PerPage
+	if end > count {
+		end = count
+	}
+
+	return start, end, m
+}
+
+// decodeOptions decodes the JSON body of a list request, which may be empty
+func decodeOptions(r *http.Request, v interface{}) error {
+	if r.ContentLength == 0 {
+		return nil
+	}
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil && err != io.EOF {
+		return err
+	}
+	return nil
+}
+
+func (s *Server) getCurrentOrganization(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodGet) {
+		return
+	}
+
+	login := strings.SplitN(r.Header.Get("Authorization"), ":", 2)[0]
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.organizations) == 0 {
+		writeError(w, http.StatusNotFound, "Organization not found")
+		return
+	}
+
+	org := s.organizations[0]
+	for _, o := range s.organizations {
+		if o.Slug == login {
+			org = o
+		}
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{"organization": org})
+}
+
+func (s *Server) getOrganization(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodGet) {
+		return
+	}
+
+	slug := strings.TrimPrefix(r.URL.Path, "/v2/organizations/")
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, o := range s.organizations {
+		if o.Slug == slug {
+			writeJSON(w, http.StatusOK, map[string]interface{}{"organization": o})
+			return
+		}
+	}
+
+	writeError(w, http.StatusNotFound, "Organization not found")
+}
+
+func (s *Server) listTransactions(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodGet) {
+		return
+	}
+
+	opt := new(goqonto.TransactionsOptions)
+	if err := decodeOptions(r, opt); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if opt.Slug == "" || opt.IBAN == "" {
+		writeError(w, http.StatusUnprocessableEntity, "slug and iban are required")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.hasBankAccount(opt.Slug, opt.IBAN) {
+		writeError(w, http.StatusNotFound, "Bank account not found")
+		return
+	}
+
+	f, err := newFilter(opt)
+	if err != nil {
+		writeError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	var matching []goqonto.Transaction
+	for _, t := range s.transactions[opt.IBAN] {
+		if f.match(t) {
+			matching = append(matching, t)
+		}
+	}
+
+	if err := sortTransactions(matching, opt.SortBy); err != nil {
+		writeError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	start, end, m := paginate(len(matching), opt.CurrentPage, opt.PerPage)
+	page := matching[start:end]
+	if page == nil {
+		page = []goqonto.Transaction{}
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{"transactions": page, "meta": m})
+}
+
+func (s *Server) hasBankAccount(slug, iban string) bool {
+	for _, o := range s.organizations {
+		if o.Slug != slug {
+			continue
+		}
+		for _, a := range o.BankAccounts {
+			if a.IBAN == iban {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// filter transactions list filters
+type filter struct {
+	status, operationType map[string]bool
+	side                  string
+
+	updatedFrom, updatedTo, settledFrom, settledTo time.Time
+}
+
+func newFilter(opt *goqonto.TransactionsOptions) (*filter, error) {
+	f := &filter{side: opt.Side, status: set(opt.Status), operationType: set(opt.OperationType)}
+
+	for _, p := range []struct {
+		value string
+		t     *time.Time
+	}{
+		{opt.UpdatedAtFrom, &f.updatedFrom},
+		{opt.UpdatedAtTo, &f.updatedTo},
+		{opt.SettledAtFrom, &f.settledFrom},
+		{opt.SettledAtTo, &f.settledTo},
+	} {
+		if p.value == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339Nano, p.value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid date %q", p.value)
+		}
+		*p.t = t
+	}
+
+	return f, nil
+}
+
+func (f *filter) match(t goqonto.Transaction) bool {
+	switch {
+	case f.status != nil && !f.status[t.Status],
+		f.operationType != nil && !f.operationType[t.OperationType],
+		f.side != "" && t.Side != f.side,
+		!f.updatedFrom.IsZero() && t.UpdatedAt.Before(f.updatedFrom),
+		!f.updatedTo.IsZero() && t.UpdatedAt.After(f.updatedTo),
+		!f.settledFrom.IsZero() && (t.SettledAt.IsZero() || t.SettledAt.Before(f.settledFrom)),
+		!f.settledTo.IsZero() && (t.SettledAt.IsZero() || t.SettledAt.After(f.settledTo)):
+		return false
+	}
+	return true
+}
+
+func set(values []string) map[string]bool {
+	if len(values) == 0 {
+		return nil
+	}
+	m := make(map[string]bool, len(values))
+	for _, v := range values {
+		m[v] = true
+	}
+	return m
+}
+
+// sortTransactions sorts transactions according to sort_by, by descending settled_at by default
+func sortTransactions(transactions []goqonto.Transaction, sortBy string) error {
+	if sortBy == "" {
+		sortBy = goqonto.TransactionSortBySettledAtDesc
+	}
+
+	var key func(t goqonto.Transaction) time.Time
+	switch strings.SplitN(sortBy, ":", 2)[0] {
+	case "settled_at":
+		key = func(t goqonto.Transaction) time.Time { return t.SettledAt }
+	case "updated_at":
+		key = func(t goqonto.Transaction) time.Time { return t.UpdatedAt }
+	default:
+		return fmt.Errorf("invalid sort_by %q", sortBy)
+	}
+
+	desc := strings.HasSuffix(sortBy, ":desc")
+	sort.SliceStable(transactions, func(i, j int) bool {
+		if desc {
+			return key(transactions[i]).After(key(transactions[j]))
+		}
+		return key(transactions[i]).Before(key(transactions[j]))
+	})
+
+	return nil
+}
+
+func (s *Server) getTransaction(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodGet) {
+		return
+	}
+
+	id := strings.TrimPrefix(r.URL.Path, "/v2/transactions/")
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, transactions := range s.transactions {
+		for _, t := range transactions {
+			if t.ID == id || t.TransactionID == id {
+				writeJSON(w, http.StatusOK, map[string]interface{}{"transaction": t})
+				return
+			}
+		}
+	}
+
+	writeError(w, http.StatusNotFound, "Transaction not found")
+}
+
+func (s *Server) listLabels(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodGet) {
+		return
+	}
+
+	opt := new(goqonto.LabelsOptions)
+	if err := decodeOptions(r, opt); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	start, end, m := paginate(len(s.labels), opt.CurrentPage, opt.PerPage)
+	page := append([]goqonto.Label{}, s.labels[start:end]...)
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{"labels": page, "meta": m})
+}
+
+func (s *Server) listMemberships(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodGet) {
+		return
+	}
+
+	opt := new(goqonto.MembershipsOptions)
+	if err := decodeOptions(r, opt); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	start, end, m := paginate(len(s.memberships), opt.CurrentPage, opt.PerPage)
+	page := append([]goqonto.Membership{}, s.memberships[start:end]...)
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{"memberships": page, "meta": m})
+}
+
+func (s *Server) getAttachment(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodGet) {
+		return
+	}
+
+	id := strings.TrimPrefix(r.URL.Path, "/v2/attachments/")
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	a, ok := s.attachments[id]
+	if !ok {
+		writeError(w, http.StatusNotFound, "Attachment not found")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{"attachment": a.Attachment})
+}
+
+func (s *Server) downloadAttachment(w http.ResponseWriter, r *http.Request) {
+	id := strings.TrimPrefix(r.URL.Path, "/files/")
+
+	s.mu.Lock()
+	a, ok := s.attachments[id]
+	s.mu.Unlock()
+
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	if a.FileContentType != "" {
+		w.Header().Set("Content-Type", a.FileContentType)
+	} else {
+		w.Header().Set("Content-Type", "application/octet-stream")
+	}
+	_, _ = w.Write(a.content)
+}
+
+func (s *Server) createTransfer(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodPost) {
+		return
+	}
+
+	root := struct {
+		Transfer *goqonto.TransferRequest `json:"external_transfer"`
+	}{}
+	if err := json.NewDecoder(r.Body).Decode(&root); err != nil || root.Transfer == nil {
+		writeError(w, http.StatusBadRequest, "invalid transfer")
+		return
+	}
+	req := root.Transfer
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := r.Header.Get("X-Qonto-Idempotency-Key")
+	if id, ok := s.idempotency[key]; ok && key != "" {
+		writeJSON(w, http.StatusOK, map[string]interface{}{"external_transfer": s.transfers[id]})
+		return
+	}
+
+	cents, err := parseAmount(req.Amount)
+	if err != nil || req.Beneficiary.IBAN == "" || req.Beneficiary.Name == "" {
+		writeError(w, http.StatusUnprocessableEntity, "invalid amount or beneficiary")
+		return
+	}
+
+	transfer := goqonto.Transfer{
+		ID:            fmt.Sprintf("transfer-%d", len(s.transfers)+1),
+		Status:        goqonto.TransferStatusPending,
+		BankAccountID: req.BankAccountID,
+		Beneficiary:   req.Beneficiary,
+		Amount:        float64(cents) / 100,
+		AmountCents:   cents,
+		Currency:      req.Currency,
+		Reference:     req.Reference,
+		Note:          req.Note,
+		ScheduledDate: req.ScheduledDate,
+		CreatedAt:     time.Now().UTC(),
+	}
+
+	s.transfers[transfer.ID] = transfer
+	s.transferIDs = append(s.transferIDs, transfer.ID)
+	if key != "" {
+		s.idempotency[key] = transfer.ID
+	}
+
+	writeJSON(w, http.StatusCreated, map[string]interface{}{"external_transfer": transfer})
+}
+
+func (s *Server) getTransfer(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodGet) {
+		return
+	}
+
+	id := strings.TrimPrefix(r.URL.Path, "/v2/external_transfers/")
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t, ok := s.transfers[id]
+	if !ok {
+		writeError(w, http.StatusNotFound, "Transfer not found")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{"external_transfer": t})
+}
+
+// parseAmount parses a decimal amount with at most 2 decimals into cents
+func parseAmount(s string) (int, error) {
+	var units, cents int
+	parts := strings.SplitN(s, ".", 2)
+
+	if _, err := fmt.Sscanf(parts[0], "%d", &units); err != nil || units < 0 {
+		return 0, fmt.Errorf("invalid amount %q", s)
+	}
+
+	if len(parts) == 2 {
+		decimals := parts[1]
+		if len(decimals) == 0 || len(decimals) > 2 {
+			return 0, fmt.Errorf("invalid amount %q", s)
+		}
+		if len(decimals) == 1 {
+			decimals += "0"
+		}
+		if _, err := fmt.Sscanf(decimals, "%d", &cents); err != nil {
+			return 0, fmt.Errorf("invalid amount %q", s)
+		}
+	}
+
+	if units == 0 && cents == 0 {
+		return 0, fmt.Errorf("invalid amount %q", s)
+	}
+	return units*100 + cents, nil
+}
@@ -0,0 +1,273 @@
+// Package qontotest provides an in-memory fake of the Qonto API, to test code using goqonto offline
+package qontotest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pixelfactoryio/goqonto/v2"
+)
+
+// defaultPerPage page size when the request does not set per_page
+const defaultPerPage = 100
+
+// Fault error or latency injected in the responses of the fake API
+type Fault struct {
+	// Requests whose path starts with Path are affected, all requests if empty.
+	Path string
+
+	// Delay before responding.
+	Latency time.Duration
+
+	// Status code returned instead of the normal response, e.g. 429 or 500. Zero only adds latency.
+	Status int
+
+	// Value of the Retry-After header of 429 responses, in seconds.
+	RetryAfter int
+
+	// Number of requests affected, 0 for all of them.
+	Count int
+}
+
+// Server fake Qonto API server
+type Server struct {
+	*httptest.Server
+
+	mu            sync.Mutex
+	credentials   map[string]string
+	organizations []goqonto.Organization
+	transactions  map[string][]goqonto.Transaction
+	labels        []goqonto.Label
+	memberships   []goqonto.Membership
+	attachments   map[string]attachment
+	transfers     map[string]goqonto.Transfer
+	transferIDs   []string
+	idempotency   map[string]string
+	faults        []*Fault
+	requests      []*http.Request
+}
+
+// attachment metadata and content of an attachment
+type attachment struct {
+	goqonto.Attachment
+	content []byte
+}
+
+// NewServer starts a fake API server, to be closed by the caller
+func NewServer() *Server {
+	s := &Server{
+		credentials:  make(map[string]string),
+		transactions: make(map[string][]goqonto.Transaction),
+		attachments:  make(map[string]attachment),
+		transfers:    make(map[string]goqonto.Transfer),
+		idempotency:  make(map[string]string),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v2/organization", s.getCurrentOrganization)
+	mux.HandleFunc("/v2/organizations/", s.getOrganization)
+	mux.HandleFunc("/v2/transactions", s.listTransactions)
+	mux.HandleFunc("/v2/transactions/", s.getTransaction)
+	mux.HandleFunc("/v2/labels", s.listLabels)
+	mux.HandleFunc("/v2/memberships", s.listMemberships)
+	mux.HandleFunc("/v2/attachments/", s.getAttachment)
+	mux.HandleFunc("/v2/external_transfers", s.createTransfer)
+	mux.HandleFunc("/v2/external_transfers/", s.getTransfer)
+	mux.HandleFunc("/files/", s.downloadAttachment)
+
+	s.Server = httptest.NewServer(s.middleware(mux))
+	return s
+}
+
+// Client returns a client of the fake API authenticated with login and secretKey
+func (s *Server) Client(login, secretKey string) *goqonto.Client {
+	httpClient := &http.Client{Transport: authTransport{login: login, secretKey: secretKey}}
+
+	c, _ := goqonto.New(httpClient, goqonto.SetBaseURL(s.URL))
+	return c
+}
+
+// authTransport sets the Authorization header of the requests
+type authTransport struct {
+	login, secretKey string
+}
+
+func (t authTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	r = r.Clone(r.Context())
+	r.Header.Set("Authorization", t.login+":"+t.secretKey)
+	return http.DefaultTransport.RoundTrip(r)
+}
+
+// AddCredentials accepts the login and secret key. Requests are not authenticated until credentials
+// are added.
+func (s *Server) AddCredentials(login, secretKey string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.credentials[login] = secretKey
+}
+
+// AddOrganization adds an organization and its bank accounts
+func (s *Server) AddOrganization(org goqonto.Organization) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.organizations = append(s.organizations, org)
+}
+
+// AddTransactions adds transactions to the bank account with the given IBAN
+func (s *Server) AddTransactions(iban string, transactions ...goqonto.Transaction) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.transactions[iban] = append(s.transactions[iban], transactions...)
+}
+
+// AddLabels adds labels
+func (s *Server) AddLabels(labels ...goqonto.Label) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.labels = append(s.labels, labels...)
+}
+
+// AddMemberships adds memberships
+func (s *Server) AddMemberships(memberships ...goqonto.Membership) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.memberships = append(s.memberships, memberships...)
+}
+
+// AddAttachment adds an attachment whose content is served at its URL, which is set by the server
+func (s *Server) AddAttachment(a goqonto.Attachment, content []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	a.URL = s.URL + "/files/" + a.ID
+	if a.FileSize == "" {
+		a.FileSize = strconv.Itoa(len(content))
+	}
+	s.attachments[a.ID] = attachment{Attachment: a, content: content}
+}
+
+// InjectFault adds a fault, faults are applied in injection order
+func (s *Server) InjectFault(f Fault) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults = append(s.faults, &f)
+}
+
+// ClearFaults removes all the faults
+func (s *Server) ClearFaults() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults = nil
+}
+
+// Transfers returns the transfers created through the fake API, sorted by creation
+func (s *Server) Transfers() []goqonto.Transfer {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	transfers := make([]goqonto.Transfer, len(s.transferIDs))
+	for k, id := range s.transferIDs {
+		transfers[k] = s.transfers[id]
+	}
+	return transfers
+}
+
+// Requests returns the requests received by the fake API
+func (s *Server) Requests() []*http.Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]*http.Request(nil), s.requests...)
+}
+
+// middleware records requests, applies faults and checks authentication
+func (s *Server) middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		s.requests = append(s.requests, r)
+		fault := s.fault(r.URL.Path)
+		authenticated := s.authenticated(r)
+		s.mu.Unlock()
+
+		if fault != nil {
+			if fault.Latency > 0 {
+				select {
+				case <-time.After(fault.Latency):
+				case <-r.Context().Done():
+					return
+				}
+			}
+
+			if fault.Status != 0 {
+				if fault.Status == http.StatusTooManyRequests && fault.RetryAfter > 0 {
+					w.Header().Set("Retry-After", strconv.Itoa(fault.RetryAfter))
+				}
+				writeError(w, fault.Status, http.StatusText(fault.Status))
+				return
+			}
+		}
+
+		if !strings.HasPrefix(r.URL.Path, "/files/") && !authenticated {
+			writeError(w, http.StatusUnauthorized, "Invalid credentials")
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		next.ServeHTTP(w, r)
+	})
+}
+
+// fault returns the first fault matching path and consumes it
+func (s *Server) fault(path string) *Fault {
+	for k, f := range s.faults {
+		if !strings.HasPrefix(path, f.Path) {
+			continue
+		}
+
+		if f.Count > 0 {
+			f.Count--
+			if f.Count == 0 {
+				s.faults = append(s.faults[:k], s.faults[k+1:]...)
+			}
+		}
+		return f
+	}
+	return nil
+}
+
+func (s *Server) authenticated(r *http.Request) bool {
+	if len(s.credentials) == 0 {
+		return true
+	}
+
+	parts := strings.SplitN(r.Header.Get("Authorization"), ":", 2)
+	if len(parts) != 2 {
+		return false
+	}
+
+	secret, ok := s.credentials[parts[0]]
+	return ok && secret == parts[1]
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]interface{}{"code": status, "message": message})
+}
+
+func allowMethod(w http.ResponseWriter, r *http.Request, method string) bool {
+	if r.Method != method {
+		writeError(w, http.StatusMethodNotAllowed, fmt.Sprintf("%s not allowed", r.Method))
+		return false
+	}
+	return true
+}
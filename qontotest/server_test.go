@@ -0,0 +1,229 @@
+package qontotest
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/pixelfactoryio/goqonto/v2"
+)
+
+const iban = "FR7616798000010000005663951"
+
+func newTestServer() *Server {
+	s := NewServer()
+	s.AddCredentials("croissant", "secret")
+	s.AddOrganization(goqonto.Organization{
+		Slug:         "croissant",
+		BankAccounts: []goqonto.BankAccount{{ID: "account-1", IBAN: iban, Currency: "EUR", Main: true}},
+	})
+
+	start := time.Date(2021, time.March, 1, 10, 0, 0, 0, time.UTC)
+	for k := 0; k < 25; k++ {
+		status := goqonto.TransactionStatusCompleted
+		if k%5 == 0 {
+			status = goqonto.TransactionStatusPending
+		}
+		s.AddTransactions(iban, goqonto.Transaction{
+			ID:          fmt.Sprintf("t%02d", k),
+			AmountCents: 100 * (k + 1),
+			Side:        goqonto.TransactionSideDebit,
+			Status:      status,
+			SettledAt:   start.Add(time.Duration(k) * time.Hour),
+			UpdatedAt:   start.Add(time.Duration(k) * time.Hour),
+		})
+	}
+
+	s.AddLabels(goqonto.Label{ID: "l1", Name: "compta"})
+	s.AddMemberships(goqonto.Membership{ID: "m1", FistName: "Jane", LastName: "Doe"})
+	s.AddAttachment(goqonto.Attachment{ID: "a1", FileName: "receipt.pdf"}, []byte("%PDF-1.4"))
+
+	return s
+}
+
+func TestServer_transactions(t *testing.T) {
+	s := newTestServer()
+	defer s.Close()
+
+	client := s.Client("croissant", "secret")
+	ctx := context.Background()
+
+	opt := &goqonto.TransactionsOptions{
+		Slug:    "croissant",
+		IBAN:    iban,
+		Status:  []string{goqonto.TransactionStatusCompleted},
+		SortBy:  goqonto.TransactionSortBySettledAtAsc,
+		PerPage: 7,
+	}
+
+	var ids []string
+	it := client.Transactions.Iterator(ctx, opt)
+	for it.Next() {
+		ids = append(ids, it.Transaction().ID)
+	}
+	if err := it.Err(); err != nil {
+		t.Fatalf("Iterator returned error: %v", err)
+	}
+
+	if len(ids) != 20 || ids[0] != "t01" || ids[19] != "t24" {
+		t.Errorf("Transactions \n got %v\n want 20 completed transactions from t01 to t24\n", ids)
+	}
+
+	opt = &goqonto.TransactionsOptions{
+		Slug:          "croissant",
+		IBAN:          iban,
+		SettledAtFrom: "2021-03-01T20:00:00.000Z",
+		SettledAtTo:   "2021-03-01T22:00:00.000Z",
+	}
+
+	transactions, resp, err := client.Transactions.List(ctx, opt)
+	if err != nil {
+		t.Fatalf("Transactions.List returned error: %v", err)
+	}
+
+	ids = nil
+	for _, trx := range transactions {
+		ids = append(ids, trx.ID)
+	}
+	if !reflect.DeepEqual(ids, []string{"t12", "t11", "t10"}) || resp.Meta.TotalCount != 3 {
+		t.Errorf("Transactions.List \n got %v, %+v\n want [t12 t11 t10]\n", ids, resp.Meta)
+	}
+
+	trx, _, err := client.Transactions.Get(ctx, "t03")
+	if err != nil || trx.AmountCents != 400 {
+		t.Errorf("Transactions.Get \n got %v, %v\n want t03\n", trx, err)
+	}
+}
+
+func TestServer_resources(t *testing.T) {
+	s := newTestServer()
+	defer s.Close()
+
+	client := s.Client("croissant", "secret")
+	ctx := context.Background()
+
+	org, _, err := client.Organizations.Current(ctx)
+	if err != nil || org.Slug != "croissant" {
+		t.Errorf("Organizations.Current \n got %v, %v\n want croissant\n", org, err)
+	}
+
+	labels, _, err := client.Labels.List(ctx, nil)
+	if err != nil || !reflect.DeepEqual(labels, []goqonto.Label{{ID: "l1", Name: "compta"}}) {
+		t.Errorf("Labels.List \n got %v, %v\n", labels, err)
+	}
+
+	members, _, err := client.Memberships.List(ctx, &goqonto.MembershipsOptions{CurrentPage: 1})
+	if err != nil || len(members) != 1 || members[0].LastName != "Doe" {
+		t.Errorf("Memberships.List \n got %v, %v\n", members, err)
+	}
+
+	a, _, err := client.Attachments.Get(ctx, "a1")
+	if err != nil {
+		t.Fatalf("Attachments.Get returned error: %v", err)
+	}
+
+	resp, err := http.Get(a.URL)
+	if err != nil {
+		t.Fatalf("Unable to download attachment: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if b, _ := ioutil.ReadAll(resp.Body); string(b) != "%PDF-1.4" || a.FileSize != "8" {
+		t.Errorf("Attachment content \n got %q, size %s\n want %%PDF-1.4, size 8\n", b, a.FileSize)
+	}
+
+	req := &goqonto.TransferRequest{
+		BankAccountID:  "account-1",
+		Beneficiary:    goqonto.TransferBeneficiary{Name: "ACME", IBAN: "FR1420041010050500013M02606"},
+		Amount:         "12.5",
+		Currency:       "EUR",
+		IdempotencyKey: "key-1",
+	}
+
+	first, _, err := client.Transfers.Create(ctx, req)
+	if err != nil || first.AmountCents != 1250 {
+		t.Fatalf("Transfers.Create \n got %v, %v\n want 1250 cents\n", first, err)
+	}
+
+	second, _, err := client.Transfers.Create(ctx, req)
+	if err != nil || second.ID != first.ID || len(s.Transfers()) != 1 {
+		t.Errorf("Transfers.Create with same idempotency key created a new transfer")
+	}
+}
+
+func TestServer_auth(t *testing.T) {
+	s := newTestServer()
+	defer s.Close()
+
+	_, resp, err := s.Client("croissant", "wrong").Organizations.Get(context.Background(), "croissant")
+	if err == nil || resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("Organizations.Get with wrong credentials \n got %v\n want 401\n", err)
+	}
+}
+
+func TestServer_faults(t *testing.T) {
+	s := newTestServer()
+	defer s.Close()
+
+	client := s.Client("croissant", "secret")
+
+	s.InjectFault(Fault{Path: "/v2/labels", Status: http.StatusTooManyRequests, RetryAfter: 3, Count: 1})
+	s.InjectFault(Fault{Path: "/v2/memberships", Status: http.StatusInternalServerError})
+
+	_, resp, err := client.Labels.List(context.Background(), nil)
+	if err == nil || resp.StatusCode != http.StatusTooManyRequests || resp.Header.Get("Retry-After") != "3" {
+		t.Errorf("Labels.List \n got %v\n want 429 with Retry-After\n", err)
+	}
+
+	if _, _, err := client.Labels.List(context.Background(), nil); err != nil {
+		t.Errorf("Labels.List after fault \n got %v\n want no error\n", err)
+	}
+
+	for k := 0; k < 2; k++ {
+		if _, resp, _ := client.Memberships.List(context.Background(), nil); resp.StatusCode != 500 {
+			t.Errorf("Memberships.List \n got %d\n want 500\n", resp.StatusCode)
+		}
+	}
+
+	s.ClearFaults()
+	s.InjectFault(Fault{Latency: 200 * time.Millisecond})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	if _, _, err := client.Labels.List(ctx, nil); err == nil {
+		t.Errorf("Labels.List with latency \n got no error\n want timeout\n")
+	}
+
+	if got := len(s.Requests()); got != 5 {
+		t.Errorf("Requests \n got %d\n want 5\n", got)
+	}
+}
+
+func TestServer_Transfers(t *testing.T) {
+	s := newTestServer()
+	defer s.Close()
+
+	client := s.Client("croissant", "secret")
+
+	for k := 1; k <= 12; k++ {
+		_, _, err := client.Transfers.Create(context.Background(), &goqonto.TransferRequest{
+			Beneficiary: goqonto.TransferBeneficiary{Name: "ACME", IBAN: "FR1420041010050500013M02606"},
+			Amount:      fmt.Sprintf("%d.00", k),
+			Currency:    "EUR",
+		})
+		if err != nil {
+			t.Fatalf("Transfers.Create returned error: %v", err)
+		}
+	}
+
+	for k, transfer := range s.Transfers() {
+		if want := 100 * (k + 1); transfer.AmountCents != want {
+			t.Errorf("Transfers()[%d] \n got %v\n want %v\n", k, transfer.AmountCents, want)
+		}
+	}
+}
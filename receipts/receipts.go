@@ -0,0 +1,211 @@
+// Package receipts reports the transactions still missing a receipt and builds the reminders sent
+// to the members who initiated them
+package receipts
+
+import (
+	"sort"
+	"time"
+
+	"github.com/pixelfactoryio/goqonto/v2"
+)
+
+// Bucket age range of missing receipts, in days since the transaction was emitted
+type Bucket struct {
+	Name string
+
+	// Inclusive bounds, a zero MaxDays means no upper bound.
+	MinDays int
+	MaxDays int
+}
+
+// DefaultBuckets default age buckets
+var DefaultBuckets = []Bucket{
+	{Name: "0-7 days", MinDays: 0, MaxDays: 7},
+	{Name: "8-30 days", MinDays: 8, MaxDays: 30},
+	{Name: "31-90 days", MinDays: 31, MaxDays: 90},
+	{Name: "over 90 days", MinDays: 91},
+}
+
+// Options report options
+type Options struct {
+	// Age buckets, in increasing order. Defaults to DefaultBuckets.
+	Buckets []Bucket
+
+	// Now returns the current time, used to compute ages. Defaults to time.Now.
+	Now func() time.Time
+}
+
+// Item transaction missing a receipt
+type Item struct {
+	Transaction goqonto.Transaction
+
+	// Days elapsed since the transaction was emitted.
+	AgeDays int
+
+	// Name of the age bucket of the transaction.
+	Bucket string
+}
+
+// CardGroup missing receipts of a card, LastDigits is empty for transactions not made by card
+type CardGroup struct {
+	LastDigits string
+	Items      []Item
+}
+
+// MemberReport missing receipts of the transactions initiated by a member
+type MemberReport struct {
+	// Membership of the initiator, only the ID is set if the membership is unknown.
+	Membership goqonto.Membership
+
+	// Groups by card, the card with the oldest missing receipt first.
+	Cards []CardGroup
+
+	// Number of missing receipts by bucket name.
+	Buckets map[string]int
+
+	Count      int
+	TotalCents int
+}
+
+// Name returns the full name of the member, or the membership ID if it is unknown
+func (m *MemberReport) Name() string {
+	if m.Membership.FistName == "" && m.Membership.LastName == "" {
+		return m.Membership.ID
+	}
+	if m.Membership.LastName == "" {
+		return m.Membership.FistName
+	}
+	if m.Membership.FistName == "" {
+		return m.Membership.LastName
+	}
+	return m.Membership.FistName + " " + m.Membership.LastName
+}
+
+// Report missing receipts grouped by initiator
+type Report struct {
+	GeneratedAt time.Time
+
+	// Age buckets used by the report.
+	Buckets []Bucket
+
+	// Reports by member, sorted by decreasing number of missing receipts.
+	Members []MemberReport
+
+	Count      int
+	TotalCents int
+}
+
+// Missing reports whether the transaction requires a receipt that was neither attached nor declared
+// lost. Declined and reversed transactions never require a receipt.
+func Missing(t goqonto.Transaction) bool {
+	if t.Status == goqonto.TransactionStatusDeclined || t.Status == goqonto.TransactionStatusReversed {
+		return false
+	}
+	return t.AttachmentRequired && !t.AttachmentLost && len(t.AttachmentIds) == 0 && len(t.Attachments) == 0
+}
+
+// Build returns the report of the transactions missing a receipt, grouped by initiator membership
+// and card. Transactions initiated by a membership absent from memberships are still reported.
+func Build(transactions []goqonto.Transaction, memberships []goqonto.Membership, opt *Options) *Report {
+	buckets := DefaultBuckets
+	now := time.Now
+	if opt != nil {
+		if opt.Buckets != nil {
+			buckets = opt.Buckets
+		}
+		if opt.Now != nil {
+			now = opt.Now
+		}
+	}
+
+	report := &Report{GeneratedAt: now(), Buckets: buckets}
+
+	known := make(map[string]goqonto.Membership)
+	for _, m := range memberships {
+		known[m.ID] = m
+	}
+
+	members := make(map[string]*MemberReport)
+	var order []string
+
+	for _, t := range transactions {
+		if !Missing(t) {
+			continue
+		}
+
+		member, ok := members[t.InitiatorID]
+		if !ok {
+			membership, found := known[t.InitiatorID]
+			if !found {
+				membership = goqonto.Membership{ID: t.InitiatorID}
+			}
+			member = &MemberReport{Membership: membership, Buckets: make(map[string]int)}
+			members[t.InitiatorID] = member
+			order = append(order, t.InitiatorID)
+		}
+
+		item := Item{Transaction: t, AgeDays: ageDays(t.EmittedAt, report.GeneratedAt)}
+		item.Bucket = bucketName(buckets, item.AgeDays)
+
+		member.addItem(item)
+		member.Buckets[item.Bucket]++
+		member.Count++
+		member.TotalCents += t.AmountCents
+
+		report.Count++
+		report.TotalCents += t.AmountCents
+	}
+
+	for _, id := range order {
+		member := members[id]
+		member.sort()
+		report.Members = append(report.Members, *member)
+	}
+
+	sort.SliceStable(report.Members, func(i, j int) bool {
+		return report.Members[i].Count > report.Members[j].Count
+	})
+
+	return report
+}
+
+func (m *MemberReport) addItem(item Item) {
+	for k := range m.Cards {
+		if m.Cards[k].LastDigits == item.Transaction.CardLastDigits {
+			m.Cards[k].Items = append(m.Cards[k].Items, item)
+			return
+		}
+	}
+	m.Cards = append(m.Cards, CardGroup{LastDigits: item.Transaction.CardLastDigits, Items: []Item{item}})
+}
+
+// sort orders the items of each card from the oldest, and the cards by their oldest item
+func (m *MemberReport) sort() {
+	for _, c := range m.Cards {
+		items := c.Items
+		sort.SliceStable(items, func(i, j int) bool {
+			return items[i].AgeDays > items[j].AgeDays
+		})
+	}
+
+	sort.SliceStable(m.Cards, func(i, j int) bool {
+		return m.Cards[i].Items[0].AgeDays > m.Cards[j].Items[0].AgeDays
+	})
+}
+
+// ageDays returns the number of whole days between emitted and now
+func ageDays(emitted, now time.Time) int {
+	if emitted.IsZero() || now.Before(emitted) {
+		return 0
+	}
+	return int(now.Sub(emitted).Hours() / 24)
+}
+
+func bucketName(buckets []Bucket, days int) string {
+	for _, b := range buckets {
+		if days >= b.MinDays && (b.MaxDays == 0 || days <= b.MaxDays) {
+			return b.Name
+		}
+	}
+	return ""
+}
@@ -0,0 +1,139 @@
+package receipts
+
+import (
+	"flag"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/pixelfactoryio/goqonto/v2"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+func testGolden(t *testing.T, name string, got []byte) {
+	t.Helper()
+
+	path := filepath.Join("testdata", name)
+	if *update {
+		if err := ioutil.WriteFile(path, got, 0644); err != nil {
+			t.Fatalf("Unable to update golden file %s: %v", path, err)
+		}
+	}
+
+	want, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Unable to read golden file %s: %v", path, err)
+	}
+
+	if string(got) != string(want) {
+		t.Errorf("%s \n got %s\n want %s\n", name, got, want)
+	}
+}
+
+var now = time.Date(2020, time.June, 30, 12, 0, 0, 0, time.UTC)
+
+func missing(id, initiator, card string, cents, age int, label string) goqonto.Transaction {
+	return goqonto.Transaction{
+		TransactionID:      id,
+		InitiatorID:        initiator,
+		CardLastDigits:     card,
+		AmountCents:        cents,
+		Currency:           "EUR",
+		Label:              label,
+		Side:               goqonto.TransactionSideDebit,
+		Status:             goqonto.TransactionStatusCompleted,
+		EmittedAt:          now.AddDate(0, 0, -age),
+		AttachmentRequired: true,
+	}
+}
+
+func testTransactions() []goqonto.Transaction {
+	attached := missing("t5", "m1", "1234", 1000, 3, "Attached")
+	attached.AttachmentIds = []string{"a1"}
+
+	lost := missing("t6", "m1", "1234", 1000, 3, "Lost")
+	lost.AttachmentLost = true
+
+	declined := missing("t7", "m1", "1234", 1000, 3, "Declined")
+	declined.Status = goqonto.TransactionStatusDeclined
+
+	notRequired := missing("t8", "m2", "", 1000, 3, "Qonto fee")
+	notRequired.AttachmentRequired = false
+
+	return []goqonto.Transaction{
+		missing("t1", "m1", "1234", 4590, 2, "Restaurant <Le Bistro>"),
+		missing("t2", "m1", "9876", 12000, 45, "Train"),
+		missing("t3", "m1", "1234", 2500, 10, "Taxi"),
+		missing("t4", "m2", "", 50000, 120, "Supplier"),
+		attached, lost, declined, notRequired,
+	}
+}
+
+func TestBuild(t *testing.T) {
+	memberships := []goqonto.Membership{{ID: "m1", FistName: "Jane", LastName: "Doe"}}
+
+	report := Build(testTransactions(), memberships, &Options{Now: func() time.Time { return now }})
+
+	if report.Count != 4 || report.TotalCents != 69090 {
+		t.Errorf("Build totals \n got %d, %d\n want 4, 69090\n", report.Count, report.TotalCents)
+	}
+
+	if len(report.Members) != 2 {
+		t.Fatalf("Build members \n got %d\n want 2\n", len(report.Members))
+	}
+
+	jane := report.Members[0]
+	if jane.Name() != "Jane Doe" || jane.Count != 3 {
+		t.Errorf("Build first member \n got %s, %d\n want Jane Doe, 3\n", jane.Name(), jane.Count)
+	}
+
+	var cards [][]string
+	for _, c := range jane.Cards {
+		group := []string{c.LastDigits}
+		for _, i := range c.Items {
+			group = append(group, i.Transaction.TransactionID+" "+i.Bucket)
+		}
+		cards = append(cards, group)
+	}
+
+	want := [][]string{{"9876", "t2 31-90 days"}, {"1234", "t3 8-30 days", "t1 0-7 days"}}
+	if !reflect.DeepEqual(cards, want) {
+		t.Errorf("Build cards \n got %v\n want %v\n", cards, want)
+	}
+
+	wantBuckets := map[string]int{"0-7 days": 1, "8-30 days": 1, "31-90 days": 1}
+	if !reflect.DeepEqual(jane.Buckets, wantBuckets) {
+		t.Errorf("Build buckets \n got %v\n want %v\n", jane.Buckets, wantBuckets)
+	}
+
+	unknown := report.Members[1]
+	if unknown.Name() != "m2" || unknown.Cards[0].Items[0].Bucket != "over 90 days" {
+		t.Errorf("Build unknown member \n got %s, %s\n want m2, over 90 days\n", unknown.Name(),
+			unknown.Cards[0].Items[0].Bucket)
+	}
+}
+
+func TestReminders(t *testing.T) {
+	memberships := []goqonto.Membership{{ID: "m1", FistName: "Jane", LastName: "Doe"}}
+	report := Build(testTransactions(), memberships, &Options{Now: func() time.Time { return now }})
+
+	reminders, err := report.Reminders(nil)
+	if err != nil {
+		t.Fatalf("Reminders returned error: %v", err)
+	}
+
+	if len(reminders) != 2 {
+		t.Fatalf("Reminders \n got %d\n want 2\n", len(reminders))
+	}
+
+	r := reminders[0]
+	if r.MembershipID != "m1" || r.Subject != "3 receipts missing" {
+		t.Errorf("Reminders first reminder \n got %s, %s\n want m1, 3 receipts missing\n", r.MembershipID, r.Subject)
+	}
+
+	testGolden(t, "reminder.txt.golden", []byte(r.Text))
+	testGolden(t, "reminder.html.golden", []byte(r.HTML))
+}
@@ -0,0 +1,118 @@
+package receipts
+
+import (
+	"bytes"
+	htmltemplate "html/template"
+	"text/template"
+
+	"github.com/pixelfactoryio/goqonto/v2/export"
+)
+
+// Reminder message asking a member to upload the missing receipts
+type Reminder struct {
+	MembershipID string
+	Subject      string
+	Text         string
+	HTML         string
+}
+
+// Templates reminder templates, executed with a *MemberReport
+type Templates struct {
+	Subject *template.Template
+	Text    *template.Template
+	HTML    *htmltemplate.Template
+}
+
+// Funcs functions available to reminder templates: cents formats an amount in cents, date formats
+// a time as yyyy-MM-dd and card describes the card of a CardGroup
+var Funcs = map[string]interface{}{
+	"cents": export.LocaleDefault.FormatCents,
+	"date": func(t interface{ Format(string) string }) string {
+		return t.Format("2006-01-02")
+	},
+	"card": func(c CardGroup) string {
+		if c.LastDigits == "" {
+			return "Other payments"
+		}
+		return "Card ending in " + c.LastDigits
+	},
+}
+
+const defaultSubject = `{{.Count}} receipt{{if gt .Count 1}}s{{end}} missing`
+
+const defaultText = `Hello {{.Name}},
+
+{{.Count}} of your transactions, {{cents .TotalCents}} in total, still miss a receipt:
+{{range .Cards}}
+{{card .}}
+{{- range .Items}}
+  - {{date .Transaction.EmittedAt}} {{.Transaction.Label}} {{cents .Transaction.AmountCents}} ` +
+	`{{.Transaction.Currency}} ({{.AgeDays}} days)
+{{- end}}
+{{end}}
+Please upload them or declare them lost.
+`
+
+const defaultHTML = `<p>Hello {{.Name}},</p>
+<p>{{.Count}} of your transactions, {{cents .TotalCents}} in total, still miss a receipt:</p>
+{{- range .Cards}}
+<h3>{{card .}}</h3>
+<table>
+<tr><th>Date</th><th>Label</th><th>Amount</th><th>Age</th></tr>
+{{- range .Items}}
+<tr><td>{{date .Transaction.EmittedAt}}</td><td>{{.Transaction.Label}}</td>` +
+	`<td>{{cents .Transaction.AmountCents}} {{.Transaction.Currency}}</td><td>{{.AgeDays}} days</td></tr>
+{{- end}}
+</table>
+{{- end}}
+<p>Please upload them or declare them lost.</p>
+`
+
+// DefaultTemplates English reminder templates
+var DefaultTemplates = &Templates{
+	Subject: template.Must(template.New("subject").Funcs(Funcs).Parse(defaultSubject)),
+	Text:    template.Must(template.New("text").Funcs(Funcs).Parse(defaultText)),
+	HTML:    htmltemplate.Must(htmltemplate.New("html").Funcs(Funcs).Parse(defaultHTML)),
+}
+
+// Reminders returns a reminder for every member of the report, rendered with tmpl or
+// DefaultTemplates if nil. Templates left nil produce empty fields.
+func (r *Report) Reminders(tmpl *Templates) ([]Reminder, error) {
+	if tmpl == nil {
+		tmpl = DefaultTemplates
+	}
+
+	reminders := make([]Reminder, 0, len(r.Members))
+	for k := range r.Members {
+		m := &r.Members[k]
+		reminder := Reminder{MembershipID: m.Membership.ID}
+
+		var buf bytes.Buffer
+		if tmpl.Subject != nil {
+			if err := tmpl.Subject.Execute(&buf, m); err != nil {
+				return nil, err
+			}
+			reminder.Subject = buf.String()
+		}
+
+		buf.Reset()
+		if tmpl.Text != nil {
+			if err := tmpl.Text.Execute(&buf, m); err != nil {
+				return nil, err
+			}
+			reminder.Text = buf.String()
+		}
+
+		buf.Reset()
+		if tmpl.HTML != nil {
+			if err := tmpl.HTML.Execute(&buf, m); err != nil {
+				return nil, err
+			}
+			reminder.HTML = buf.String()
+		}
+
+		reminders = append(reminders, reminder)
+	}
+
+	return reminders, nil
+}
@@ -0,0 +1,469 @@
+// Package reconcile matches Qonto transactions against the entries of an external ledger
+// (invoices, payables, ERP journal lines)
+package reconcile
+
+import (
+	"sort"
+	"time"
+
+	"github.com/pixelfactoryio/goqonto/v2"
+)
+
+// Entry external ledger entry
+type Entry struct {
+	// Identifier of the entry in the external ledger.
+	ID string
+
+	// Expected amount in cents: positive for money received (credit transactions),
+	// negative for money paid (debit transactions).
+	AmountCents int
+
+	// Expected payment date of the entry.
+	Date time.Time
+
+	// Reference expected to be found in the transaction, e.g. an invoice number.
+	Reference string
+
+	// Expected counterparty, compared with the transaction label.
+	Counterparty string
+
+	// Source holds the original ledger record, it is not used for matching.
+	Source interface{}
+}
+
+// Ledger is the external ledger, it can be implemented by any slice type in the same way as
+// sort.Interface
+type Ledger interface {
+	Len() int
+	Entry(i int) Entry
+}
+
+// Entries is a Ledger backed by a slice of entries
+type Entries []Entry
+
+// Len returns the number of entries
+func (e Entries) Len() int { return len(e) }
+
+// Entry returns the i-th entry
+func (e Entries) Entry(i int) Entry { return e[i] }
+
+// Options reconciliation options
+type Options struct {
+	// Maximum number of days between a transaction and a ledger entry. Defaults to 7.
+	DateWindow int
+
+	// Minimum confidence of a match. Defaults to 0.5.
+	MinConfidence float64
+
+	// Matches whose confidences differ by less than AmbiguityMargin are ambiguous. Defaults to 0.1.
+	AmbiguityMargin float64
+
+	// Maximum number of entries (or transactions) matched together in a one-to-many
+	// (or many-to-one) match. Defaults to 3, 1 disables grouped matches.
+	MaxGroupSize int
+}
+
+// Match transactions and ledger entries reconciled together
+type Match struct {
+	Transactions []goqonto.Transaction
+	Entries      []Entry
+
+	// Confidence of the match, between 0 and 1.
+	Confidence float64
+}
+
+// Ambiguous competing matches for the same transactions or entries
+type Ambiguous struct {
+	Candidates []Match
+}
+
+// Result outcome of a reconciliation
+type Result struct {
+	Matched               []Match
+	Ambiguous             []Ambiguous
+	UnmatchedTransactions []goqonto.Transaction
+	UnmatchedEntries      []Entry
+}
+
+// Reconcile matches transactions with ledger entries. Amounts must match exactly: a transaction
+// is matched with a single entry of the same signed amount, or with a group of entries whose
+// amounts sum up to it (one-to-many), or a group of transactions is matched with a single entry
+// (many-to-one). Candidates are scored on date proximity, reference tokens and counterparty
+// similarity. Declined and reversed transactions are ignored.
+func Reconcile(transactions []goqonto.Transaction, ledger Ledger, opt *Options) *Result {
+	r := newReconciler(transactions, ledger, opt)
+
+	r.matchOneToOne()
+	if r.opt.MaxGroupSize > 1 {
+		r.matchOneToMany()
+		r.matchManyToOne()
+	}
+
+	return r.result()
+}
+
+type reconciler struct {
+	opt          Options
+	transactions []goqonto.Transaction
+	entries      []Entry
+
+	// Indexes of the transactions and entries already part of a match or of an ambiguous set.
+	usedTransactions map[int]bool
+	usedEntries      map[int]bool
+
+	matched   []Match
+	ambiguous []Ambiguous
+}
+
+// candidate scored match between transactions and entries, by index
+type candidate struct {
+	transactions []int
+	entries      []int
+	confidence   float64
+}
+
+func newReconciler(transactions []goqonto.Transaction, ledger Ledger, opt *Options) *reconciler {
+	r := &reconciler{
+		usedTransactions: make(map[int]bool),
+		usedEntries:      make(map[int]bool),
+	}
+
+	if opt != nil {
+		r.opt = *opt
+	}
+	if r.opt.DateWindow == 0 {
+		r.opt.DateWindow = 7
+	}
+	if r.opt.MinConfidence == 0 {
+		r.opt.MinConfidence = 0.5
+	}
+	if r.opt.AmbiguityMargin == 0 {
+		r.opt.AmbiguityMargin = 0.1
+	}
+	if r.opt.MaxGroupSize == 0 {
+		r.opt.MaxGroupSize = 3
+	}
+
+	for _, t := range transactions {
+		if t.Status == goqonto.TransactionStatusDeclined || t.Status == goqonto.TransactionStatusReversed {
+			continue
+		}
+		r.transactions = append(r.transactions, t)
+	}
+
+	for i := 0; i < ledger.Len(); i++ {
+		r.entries = append(r.entries, ledger.Entry(i))
+	}
+
+	return r
+}
+
+// matchOneToOne matches transactions with single entries of the same amount
+func (r *reconciler) matchOneToOne() {
+	var candidates []candidate
+
+	for ti, t := range r.transactions {
+		for ei, e := range r.entries {
+			if signedAmount(t) != e.AmountCents || !r.withinWindow(transactionDate(t), e.Date) {
+				continue
+			}
+
+			c := candidate{
+				transactions: []int{ti},
+				entries:      []int{ei},
+				confidence:   r.score([]goqonto.Transaction{t}, []Entry{e}),
+			}
+			if c.confidence >= r.opt.MinConfidence {
+				candidates = append(candidates, c)
+			}
+		}
+	}
+
+	r.assign(candidates)
+}
+
+// matchOneToMany matches the remaining transactions with groups of remaining entries
+func (r *reconciler) matchOneToMany() {
+	var candidates []candidate
+
+	for ti, t := range r.transactions {
+		if r.usedTransactions[ti] {
+			continue
+		}
+
+		var pool []int
+		for ei, e := range r.entries {
+			if !r.usedEntries[ei] && sameSign(e.AmountCents, signedAmount(t)) && r.withinWindow(transactionDate(t), e.Date) {
+				pool = append(pool, ei)
+			}
+		}
+
+		amounts := make([]int, len(pool))
+		for i, ei := range pool {
+			amounts[i] = r.entries[ei].AmountCents
+		}
+
+		for _, subset := range subsetsWithSum(amounts, signedAmount(t), r.opt.MaxGroupSize) {
+			c := candidate{transactions: []int{ti}}
+			group := make([]Entry, 0, len(subset))
+			for _, i := range subset {
+				c.entries = append(c.entries, pool[i])
+				group = append(group, r.entries[pool[i]])
+			}
+
+			c.confidence = r.score([]goqonto.Transaction{t}, group)
+			if c.confidence >= r.opt.MinConfidence {
+				candidates = append(candidates, c)
+			}
+		}
+	}
+
+	r.assign(candidates)
+}
+
+// matchManyToOne matches the remaining entries with groups of remaining transactions
+func (r *reconciler) matchManyToOne() {
+	var candidates []candidate
+
+	for ei, e := range r.entries {
+		if r.usedEntries[ei] {
+			continue
+		}
+
+		var pool []int
+		for ti, t := range r.transactions {
+			if !r.usedTransactions[ti] && sameSign(signedAmount(t), e.AmountCents) &&
+				r.withinWindow(transactionDate(t), e.Date) {
+				pool = append(pool, ti)
+			}
+		}
+
+		amounts := make([]int, len(pool))
+		for i, ti := range pool {
+			amounts[i] = signedAmount(r.transactions[ti])
+		}
+
+		for _, subset := range subsetsWithSum(amounts, e.AmountCents, r.opt.MaxGroupSize) {
+			c := candidate{entries: []int{ei}}
+			group := make([]goqonto.Transaction, 0, len(subset))
+			for _, i := range subset {
+				c.transactions = append(c.transactions, pool[i])
+				group = append(group, r.transactions[pool[i]])
+			}
+
+			c.confidence = r.score(group, []Entry{e})
+			if c.confidence >= r.opt.MinConfidence {
+				candidates = append(candidates, c)
+			}
+		}
+	}
+
+	r.assign(candidates)
+}
+
+// assign accepts candidates by decreasing confidence. A candidate competing with another one for the
+// same transaction or entry with a close confidence is ambiguous: both are set aside.
+func (r *reconciler) assign(candidates []candidate) {
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].confidence > candidates[j].confidence
+	})
+
+	for i, c := range candidates {
+		if r.used(c) {
+			continue
+		}
+
+		group := []candidate{c}
+		for _, other := range candidates[i+1:] {
+			if c.confidence-other.confidence >= r.opt.AmbiguityMargin {
+				break
+			}
+			if !r.used(other) && overlaps(c, other) {
+				group = append(group, other)
+			}
+		}
+
+		if len(group) == 1 {
+			r.matched = append(r.matched, r.match(c))
+			r.use(c)
+			continue
+		}
+
+		amb := Ambiguous{}
+		for _, g := range group {
+			amb.Candidates = append(amb.Candidates, r.match(g))
+		}
+		for _, g := range group {
+			r.use(g)
+		}
+		r.ambiguous = append(r.ambiguous, amb)
+	}
+}
+
+func (r *reconciler) used(c candidate) bool {
+	for _, ti := range c.transactions {
+		if r.usedTransactions[ti] {
+			return true
+		}
+	}
+	for _, ei := range c.entries {
+		if r.usedEntries[ei] {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *reconciler) use(c candidate) {
+	for _, ti := range c.transactions {
+		r.usedTransactions[ti] = true
+	}
+	for _, ei := range c.entries {
+		r.usedEntries[ei] = true
+	}
+}
+
+func (r *reconciler) match(c candidate) Match {
+	m := Match{Confidence: c.confidence}
+	for _, ti := range c.transactions {
+		m.Transactions = append(m.Transactions, r.transactions[ti])
+	}
+	for _, ei := range c.entries {
+		m.Entries = append(m.Entries, r.entries[ei])
+	}
+	return m
+}
+
+func (r *reconciler) result() *Result {
+	res := &Result{
+		Matched:   r.matched,
+		Ambiguous: r.ambiguous,
+	}
+
+	for ti, t := range r.transactions {
+		if !r.usedTransactions[ti] {
+			res.UnmatchedTransactions = append(res.UnmatchedTransactions, t)
+		}
+	}
+
+	for ei, e := range r.entries {
+		if !r.usedEntries[ei] {
+			res.UnmatchedEntries = append(res.UnmatchedEntries, e)
+		}
+	}
+
+	return res
+}
+
+func (r *reconciler) withinWindow(a, b time.Time) bool {
+	return daysBetween(a, b) <= float64(r.opt.DateWindow)
+}
+
+// Score weights, an exact amount match is a prerequisite and accounts for the base score
+const (
+	amountWeight       = 0.4
+	dateWeight         = 0.2
+	referenceWeight    = 0.25
+	counterpartyWeight = 0.15
+
+	// groupPenalty confidence removed per additional item of a grouped match
+	groupPenalty = 0.05
+)
+
+// score returns the confidence of matching the transactions with the entries
+func (r *reconciler) score(transactions []goqonto.Transaction, entries []Entry) float64 {
+	var date, reference, counterparty float64
+
+	for _, t := range transactions {
+		for _, e := range entries {
+			d := 1 - daysBetween(transactionDate(t), e.Date)/float64(r.opt.DateWindow+1)
+			date = maxFloat(date, d)
+			reference = maxFloat(reference, referenceScore(e.Reference, t))
+			counterparty = maxFloat(counterparty, similarity(t.Label, e.Counterparty))
+		}
+	}
+
+	score := amountWeight + dateWeight*date + referenceWeight*reference + counterpartyWeight*counterparty
+	score -= groupPenalty * float64(len(transactions)+len(entries)-2)
+
+	if score < 0 {
+		return 0
+	}
+	return score
+}
+
+// overlaps reports whether two candidates share a transaction or an entry
+func overlaps(a, b candidate) bool {
+	for _, x := range a.transactions {
+		for _, y := range b.transactions {
+			if x == y {
+				return true
+			}
+		}
+	}
+	for _, x := range a.entries {
+		for _, y := range b.entries {
+			if x == y {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// subsetsWithSum returns the index sets of 2 to maxSize amounts summing up to target
+func subsetsWithSum(amounts []int, target, maxSize int) [][]int {
+	var subsets [][]int
+	var walk func(start, sum int, current []int)
+
+	walk = func(start, sum int, current []int) {
+		if len(current) >= 2 && sum == target {
+			subsets = append(subsets, append([]int(nil), current...))
+			return
+		}
+		if len(current) == maxSize {
+			return
+		}
+		for i := start; i < len(amounts); i++ {
+			walk(i+1, sum+amounts[i], append(current, i))
+		}
+	}
+
+	walk(0, 0, nil)
+	return subsets
+}
+
+// signedAmount returns the transaction amount in cents, negative for debits
+func signedAmount(t goqonto.Transaction) int {
+	if t.Side == goqonto.TransactionSideDebit {
+		return -t.AmountCents
+	}
+	return t.AmountCents
+}
+
+// transactionDate returns the settlement date of the transaction, or its emission date if it is pending
+func transactionDate(t goqonto.Transaction) time.Time {
+	if !t.SettledAt.IsZero() {
+		return t.SettledAt
+	}
+	return t.EmittedAt
+}
+
+func daysBetween(a, b time.Time) float64 {
+	d := a.Sub(b).Hours() / 24
+	if d < 0 {
+		return -d
+	}
+	return d
+}
+
+func sameSign(a, b int) bool {
+	return (a < 0) == (b < 0)
+}
+
+func maxFloat(a, b float64) float64 {
+	if a > b {
+		return a
+	}
+	return b
+}
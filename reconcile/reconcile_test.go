@@ -0,0 +1,158 @@
+package reconcile
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/pixelfactoryio/goqonto/v2"
+)
+
+func date(day int) time.Time {
+	return time.Date(2020, time.March, day, 10, 0, 0, 0, time.UTC)
+}
+
+func transaction(id, side string, cents, day int, label, reference string) goqonto.Transaction {
+	return goqonto.Transaction{
+		TransactionID: id,
+		Side:          side,
+		AmountCents:   cents,
+		Status:        goqonto.TransactionStatusCompleted,
+		Label:         label,
+		Reference:     reference,
+		EmittedAt:     date(day),
+		SettledAt:     date(day),
+	}
+}
+
+func ids(m Match) ([]string, []string) {
+	var tx, entries []string
+	for _, t := range m.Transactions {
+		tx = append(tx, t.TransactionID)
+	}
+	for _, e := range m.Entries {
+		entries = append(entries, e.ID)
+	}
+	return tx, entries
+}
+
+func TestReconcileOneToOne(t *testing.T) {
+	transactions := []goqonto.Transaction{
+		transaction("t1", "credit", 120000, 5, "ACME SAS", "Payment invoice INV-2020-042"),
+		transaction("t2", "debit", 4999, 6, "Office Supplies Ltd", ""),
+		transaction("t3", "debit", 700, 6, "Bakery", ""),
+	}
+	transactions = append(transactions, transaction("t4", "debit", 4999, 6, "Declined", ""))
+	transactions[3].Status = goqonto.TransactionStatusDeclined
+
+	ledger := Entries{
+		{ID: "inv-42", AmountCents: 120000, Date: date(1), Reference: "INV-2020-042", Counterparty: "Acme"},
+		{ID: "bill-7", AmountCents: -4999, Date: date(4), Counterparty: "Office Supplies"},
+		{ID: "bill-8", AmountCents: -10000, Date: date(4), Counterparty: "Landlord"},
+	}
+
+	res := Reconcile(transactions, ledger, nil)
+
+	if len(res.Matched) != 2 {
+		t.Fatalf("Reconcile matched \n got %d matches\n want 2", len(res.Matched))
+	}
+
+	want := [][]string{{"t1"}, {"inv-42"}, {"t2"}, {"bill-7"}}
+	var got [][]string
+	for _, m := range res.Matched {
+		tx, entries := ids(m)
+		got = append(got, tx, entries)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Reconcile matches \n got %v\n want %v\n", got, want)
+	}
+
+	if res.Matched[0].Confidence < res.Matched[1].Confidence {
+		t.Errorf("Reconcile reference match confidence %v lower than %v", res.Matched[0].Confidence,
+			res.Matched[1].Confidence)
+	}
+
+	if len(res.UnmatchedTransactions) != 1 || res.UnmatchedTransactions[0].TransactionID != "t3" {
+		t.Errorf("Reconcile unmatched transactions \n got %v\n want [t3]\n", res.UnmatchedTransactions)
+	}
+
+	if len(res.UnmatchedEntries) != 1 || res.UnmatchedEntries[0].ID != "bill-8" {
+		t.Errorf("Reconcile unmatched entries \n got %v\n want [bill-8]\n", res.UnmatchedEntries)
+	}
+}
+
+func TestReconcileGroups(t *testing.T) {
+	transactions := []goqonto.Transaction{
+		transaction("t1", "credit", 30000, 10, "ACME", "INV-101 INV-102"),
+		transaction("t2", "debit", 2500, 11, "Hosting Inc", ""),
+		transaction("t3", "debit", 2500, 12, "Hosting Inc", ""),
+	}
+
+	ledger := Entries{
+		{ID: "inv-101", AmountCents: 10000, Date: date(9), Reference: "INV-101", Counterparty: "Acme"},
+		{ID: "inv-102", AmountCents: 20000, Date: date(9), Reference: "INV-102", Counterparty: "Acme"},
+		{ID: "bill-1", AmountCents: -5000, Date: date(11), Counterparty: "Hosting"},
+	}
+
+	res := Reconcile(transactions, ledger, nil)
+
+	want := [][]string{{"t1"}, {"inv-101", "inv-102"}, {"t2", "t3"}, {"bill-1"}}
+	var got [][]string
+	for _, m := range res.Matched {
+		tx, entries := ids(m)
+		got = append(got, tx, entries)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Reconcile matches \n got %v\n want %v\n", got, want)
+	}
+
+	res = Reconcile(transactions, ledger, &Options{MaxGroupSize: 1})
+	if len(res.Matched) != 0 || len(res.UnmatchedEntries) != 3 {
+		t.Errorf("Reconcile without groups \n got %d matches\n want 0", len(res.Matched))
+	}
+}
+
+func TestReconcileAmbiguous(t *testing.T) {
+	transactions := []goqonto.Transaction{
+		transaction("t1", "debit", 1500, 10, "Taxi", ""),
+	}
+
+	ledger := Entries{
+		{ID: "r1", AmountCents: -1500, Date: date(10), Counterparty: "Taxi"},
+		{ID: "r2", AmountCents: -1500, Date: date(10), Counterparty: "Taxi"},
+	}
+
+	res := Reconcile(transactions, ledger, nil)
+
+	if len(res.Matched) != 0 {
+		t.Errorf("Reconcile matched \n got %v\n want none\n", res.Matched)
+	}
+
+	if len(res.Ambiguous) != 1 || len(res.Ambiguous[0].Candidates) != 2 {
+		t.Fatalf("Reconcile ambiguous \n got %v\n want 1 set of 2 candidates\n", res.Ambiguous)
+	}
+
+	if len(res.UnmatchedTransactions) != 0 || len(res.UnmatchedEntries) != 0 {
+		t.Errorf("Reconcile ambiguous items reported as unmatched")
+	}
+}
+
+func TestSimilarity(t *testing.T) {
+	tests := []struct {
+		a, b string
+		min  float64
+		max  float64
+	}{
+		{"ACME SAS", "Acme", 1, 1},
+		{"Amazon EU SARL", "Amazon", 0.9, 0.9},
+		{"Uber BV", "Ubr", 0.75, 0.75},
+		{"Google", "Orange", 0, 0.5},
+		{"", "Orange", 0, 0},
+	}
+
+	for _, tt := range tests {
+		if got := similarity(tt.a, tt.b); got < tt.min || got > tt.max {
+			t.Errorf("similarity(%q, %q) \n got %v\n want between %v and %v\n", tt.a, tt.b, got, tt.min, tt.max)
+		}
+	}
+}
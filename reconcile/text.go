@@ -0,0 +1,120 @@
+package reconcile
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/pixelfactoryio/goqonto/v2"
+)
+
+// legalForms company legal forms ignored when comparing counterparties
+var legalForms = map[string]bool{
+	"sa": true, "sas": true, "sasu": true, "sarl": true, "eurl": true, "sci": true,
+	"inc": true, "ltd": true, "llc": true, "gmbh": true, "bv": true, "srl": true,
+}
+
+// tokens splits s into lower case alphanumeric tokens
+func tokens(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// normalize returns the tokens of a counterparty name without legal forms, joined by spaces
+func normalize(s string) string {
+	var kept []string
+	for _, t := range tokens(s) {
+		if !legalForms[t] {
+			kept = append(kept, t)
+		}
+	}
+	return strings.Join(kept, " ")
+}
+
+// referenceScore returns 1 if a significant token of the reference (at least 3 characters with a
+// digit, such as an invoice number) is found in the transaction reference, label or note, a partial
+// score if only other tokens are found and 0 otherwise
+func referenceScore(reference string, t goqonto.Transaction) float64 {
+	refTokens := tokens(reference)
+	if len(refTokens) == 0 {
+		return 0
+	}
+
+	haystack := make(map[string]bool)
+	for _, s := range []string{t.Reference, t.Label, t.Note} {
+		for _, tok := range tokens(s) {
+			haystack[tok] = true
+		}
+	}
+
+	found, significant := 0, 0
+	for _, tok := range refTokens {
+		if !haystack[tok] {
+			continue
+		}
+		found++
+		if len(tok) >= 3 && strings.IndexFunc(tok, unicode.IsDigit) >= 0 {
+			significant++
+		}
+	}
+
+	if significant > 0 {
+		return 1
+	}
+	return 0.5 * float64(found) / float64(len(refTokens))
+}
+
+// similarity returns the fuzzy similarity between two counterparty names, between 0 and 1
+func similarity(a, b string) float64 {
+	a, b = normalize(a), normalize(b)
+	if a == "" || b == "" {
+		return 0
+	}
+	if a == b {
+		return 1
+	}
+	if strings.Contains(a, b) || strings.Contains(b, a) {
+		return 0.9
+	}
+
+	ra, rb := []rune(a), []rune(b)
+	longest := len(ra)
+	if len(rb) > longest {
+		longest = len(rb)
+	}
+
+	return 1 - float64(levenshtein(ra, rb))/float64(longest)
+}
+
+// levenshtein returns the edit distance between a and b
+func levenshtein(a, b []rune) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = minInt(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+
+	return prev[len(b)]
+}
+
+func minInt(values ...int) int {
+	m := values[0]
+	for _, v := range values[1:] {
+		if v < m {
+			m = v
+		}
+	}
+	return m
+}
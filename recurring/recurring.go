@@ -0,0 +1,238 @@
+// Package recurring detects recurring debits, such as subscriptions, in a transaction history
+package recurring
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pixelfactoryio/goqonto/v2"
+)
+
+// Period periodicity of recurring payments
+type Period struct {
+	Name string
+
+	// Nominal interval between two payments and tolerated deviation, in days.
+	Days      float64
+	Tolerance float64
+
+	// Calendar interval between two payments, used to estimate the next one.
+	Years, Months, DaysStep int
+}
+
+// Next returns the expected date of the payment following t
+func (p Period) Next(t time.Time) time.Time {
+	return p.after(t, 1)
+}
+
+// after returns the expected date of the n-th payment following t
+func (p Period) after(t time.Time, n int) time.Time {
+	return t.AddDate(n*p.Years, n*p.Months, n*p.DaysStep)
+}
+
+// PeriodWeekly is a payment made every week.
+var PeriodWeekly = Period{Name: "weekly", Days: 7, Tolerance: 2, DaysStep: 7}
+
+// PeriodMonthly is a payment made every month.
+var PeriodMonthly = Period{Name: "monthly", Days: 30.44, Tolerance: 5, Months: 1}
+
+// PeriodYearly is a payment made every year.
+var PeriodYearly = Period{Name: "yearly", Days: 365.25, Tolerance: 15, Years: 1}
+
+// Periods detected periods
+var Periods = []Period{PeriodWeekly, PeriodMonthly, PeriodYearly}
+
+// Options detection options
+type Options struct {
+	// Minimum number of payments of a series. Defaults to 3.
+	MinOccurrences int
+
+	// Relative amount change below which two payments have the same price. Defaults to 0.1.
+	AmountTolerance float64
+
+	// Now returns the current time, used to detect missed payments. Defaults to time.Now.
+	Now func() time.Time
+}
+
+// PriceChange change of the amount of a recurring payment
+type PriceChange struct {
+	Date      time.Time
+	FromCents int
+	ToCents   int
+}
+
+// Subscription series of recurring debits to the same counterparty
+type Subscription struct {
+	// Counterparty label, as found in the most recent transaction.
+	Counterparty string
+	Currency     string
+	Period       Period
+
+	// Payments in chronological order.
+	Transactions []goqonto.Transaction
+
+	// Estimated date and amount of the next payment.
+	NextDate        time.Time
+	NextAmountCents int
+
+	// Missed is true if the next payment is overdue by more than the period tolerance.
+	Missed bool
+
+	// Expected dates of the payments missing between the transactions, in chronological order.
+	MissedDates []time.Time
+
+	PriceChanges []PriceChange
+}
+
+// Detect scans the transactions for recurring debits to the same counterparty with similar amounts and
+// a weekly, monthly or yearly periodicity. Subscriptions are sorted by counterparty.
+func Detect(transactions []goqonto.Transaction, opt *Options) []Subscription {
+	minOccurrences := 3
+	amountTolerance := 0.1
+	now := time.Now
+	if opt != nil {
+		if opt.MinOccurrences > 0 {
+			minOccurrences = opt.MinOccurrences
+		}
+		if opt.AmountTolerance > 0 {
+			amountTolerance = opt.AmountTolerance
+		}
+		if opt.Now != nil {
+			now = opt.Now
+		}
+	}
+
+	series := make(map[string][]goqonto.Transaction)
+	for _, t := range transactions {
+		if t.Side != goqonto.TransactionSideDebit ||
+			t.Status == goqonto.TransactionStatusDeclined || t.Status == goqonto.TransactionStatusReversed {
+			continue
+		}
+		key := t.Currency + "/" + normalize(t.Label)
+		series[key] = append(series[key], t)
+	}
+
+	var subscriptions []Subscription
+	for _, s := range series {
+		if len(s) < minOccurrences {
+			continue
+		}
+
+		sort.SliceStable(s, func(i, j int) bool {
+			return date(s[i]).Before(date(s[j]))
+		})
+
+		period, ok := detectPeriod(s)
+		if !ok {
+			continue
+		}
+
+		changes, ok := priceChanges(s, amountTolerance)
+		if !ok {
+			continue
+		}
+
+		last := s[len(s)-1]
+		sub := Subscription{
+			Counterparty:    last.Label,
+			Currency:        last.Currency,
+			Period:          period,
+			Transactions:    s,
+			NextDate:        period.Next(date(last)),
+			NextAmountCents: last.AmountCents,
+			MissedDates:     missedDates(s, period),
+			PriceChanges:    changes,
+		}
+		sub.Missed = now().Sub(sub.NextDate).Hours()/24 > period.Tolerance
+
+		subscriptions = append(subscriptions, sub)
+	}
+
+	sort.Slice(subscriptions, func(i, j int) bool {
+		if subscriptions[i].Counterparty != subscriptions[j].Counterparty {
+			return subscriptions[i].Counterparty < subscriptions[j].Counterparty
+		}
+		return subscriptions[i].Currency < subscriptions[j].Currency
+	})
+
+	return subscriptions
+}
+
+// detectPeriod returns the period matched by at least 80% of the intervals between payments
+func detectPeriod(s []goqonto.Transaction) (Period, bool) {
+	intervals := make([]float64, 0, len(s)-1)
+	for k := 1; k < len(s); k++ {
+		intervals = append(intervals, date(s[k]).Sub(date(s[k-1])).Hours()/24)
+	}
+
+	for _, p := range Periods {
+		regular := 0
+		for _, d := range intervals {
+			if d >= p.Days-p.Tolerance && d <= p.Days+p.Tolerance {
+				regular++
+			}
+		}
+		if float64(regular) >= 0.8*float64(len(intervals)) {
+			return p, true
+		}
+	}
+
+	return Period{}, false
+}
+
+// missedDates returns the expected dates of the payments skipped by the intervals lasting about
+// several periods
+func missedDates(s []goqonto.Transaction, p Period) []time.Time {
+	var dates []time.Time
+	for k := 1; k < len(s); k++ {
+		from := date(s[k-1])
+		d := date(s[k]).Sub(from).Hours() / 24
+
+		n := int(d/p.Days + 0.5)
+		if n < 2 || abs(d-float64(n)*p.Days) > p.Tolerance {
+			continue
+		}
+		for j := 1; j < n; j++ {
+			dates = append(dates, p.after(from, j))
+		}
+	}
+	return dates
+}
+
+// priceChanges returns the changes of amount beyond tolerance between consecutive payments. Series
+// changing price more than once every three payments are not considered recurring.
+func priceChanges(s []goqonto.Transaction, tolerance float64) ([]PriceChange, bool) {
+	var changes []PriceChange
+	for k := 1; k < len(s); k++ {
+		from, to := s[k-1].AmountCents, s[k].AmountCents
+		if from == 0 || abs(float64(to-from))/float64(from) > tolerance {
+			changes = append(changes, PriceChange{Date: date(s[k]), FromCents: from, ToCents: to})
+		}
+	}
+
+	if 3*len(changes) > len(s)-1 {
+		return nil, false
+	}
+	return changes, true
+}
+
+// normalize returns the counterparty label in upper case with collapsed spaces
+func normalize(label string) string {
+	return strings.ToUpper(strings.Join(strings.Fields(label), " "))
+}
+
+// date returns the settlement date of a transaction, or its emission date if it is not settled
+func date(t goqonto.Transaction) time.Time {
+	if !t.SettledAt.IsZero() {
+		return t.SettledAt
+	}
+	return t.EmittedAt
+}
+
+func abs(v float64) float64 {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
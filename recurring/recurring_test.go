@@ -0,0 +1,114 @@
+package recurring
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/pixelfactoryio/goqonto/v2"
+)
+
+func debit(label string, cents int, at time.Time) goqonto.Transaction {
+	return goqonto.Transaction{
+		Label:       label,
+		Side:        goqonto.TransactionSideDebit,
+		Currency:    "EUR",
+		AmountCents: cents,
+		Status:      goqonto.TransactionStatusCompleted,
+		SettledAt:   at,
+	}
+}
+
+func day(year int, month time.Month, d int) time.Time {
+	return time.Date(year, month, d, 9, 0, 0, 0, time.UTC)
+}
+
+func TestDetect(t *testing.T) {
+	transactions := []goqonto.Transaction{
+		// Monthly subscription with a price increase and a small variation.
+		debit("Slack", 8000, day(2020, time.January, 15)),
+		debit("SLACK ", 8000, day(2020, time.February, 16)),
+		debit("Slack", 8200, day(2020, time.March, 15)),
+		debit("Slack", 12000, day(2020, time.April, 14)),
+		debit("Slack", 12000, day(2020, time.May, 15)),
+		debit("Slack", 12000, day(2020, time.June, 15)),
+
+		// Weekly cleaning.
+		debit("Cleaning", 5000, day(2020, time.June, 2)),
+		debit("Cleaning", 5000, day(2020, time.June, 9)),
+		debit("Cleaning", 5000, day(2020, time.June, 16)),
+
+		// Yearly domain, missed this year.
+		debit("Domains", 1500, day(2017, time.March, 1)),
+		debit("Domains", 1500, day(2018, time.March, 2)),
+		debit("Domains", 1500, day(2019, time.March, 1)),
+
+		// Irregular amounts and dates.
+		debit("Grocery", 1234, day(2020, time.June, 1)),
+		debit("Grocery", 5678, day(2020, time.June, 3)),
+		debit("Grocery", 910, day(2020, time.June, 20)),
+
+		// Too few payments.
+		debit("Once", 1000, day(2020, time.May, 1)),
+		debit("Once", 1000, day(2020, time.June, 1)),
+	}
+
+	now := func() time.Time { return day(2020, time.June, 20) }
+	subs := Detect(transactions, &Options{Now: now})
+
+	type summary struct {
+		Counterparty string
+		Period       string
+		Count        int
+		Next         time.Time
+		NextCents    int
+		Missed       bool
+	}
+
+	var got []summary
+	for _, s := range subs {
+		got = append(got, summary{
+			s.Counterparty, s.Period.Name, len(s.Transactions), s.NextDate, s.NextAmountCents, s.Missed,
+		})
+	}
+
+	want := []summary{
+		{"Cleaning", "weekly", 3, day(2020, time.June, 23), 5000, false},
+		{"Domains", "yearly", 3, day(2020, time.March, 1), 1500, true},
+		{"Slack", "monthly", 6, day(2020, time.July, 15), 12000, false},
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Detect \n got %v\n want %v\n", got, want)
+	}
+
+	wantChanges := []PriceChange{{Date: day(2020, time.April, 14), FromCents: 8200, ToCents: 12000}}
+	if !reflect.DeepEqual(subs[2].PriceChanges, wantChanges) {
+		t.Errorf("Detect price changes \n got %v\n want %v\n", subs[2].PriceChanges, wantChanges)
+	}
+}
+
+func TestDetect_missedDates(t *testing.T) {
+	transactions := []goqonto.Transaction{
+		debit("Notion", 1000, day(2020, time.January, 15)),
+		debit("Notion", 1000, day(2020, time.February, 15)),
+		debit("Notion", 1000, day(2020, time.March, 15)),
+		debit("Notion", 1000, day(2020, time.May, 15)),
+		debit("Notion", 1000, day(2020, time.June, 15)),
+		debit("Notion", 1000, day(2020, time.July, 15)),
+	}
+
+	now := func() time.Time { return day(2020, time.July, 20) }
+	subs := Detect(transactions, &Options{Now: now})
+	if len(subs) != 1 {
+		t.Fatalf("Detect subscriptions \n got %v\n want %v\n", len(subs), 1)
+	}
+
+	want := []time.Time{day(2020, time.April, 15)}
+	if !reflect.DeepEqual(subs[0].MissedDates, want) {
+		t.Errorf("Detect missed dates \n got %v\n want %v\n", subs[0].MissedDates, want)
+	}
+	if subs[0].Missed {
+		t.Errorf("Detect missed \n got %v\n want %v\n", subs[0].Missed, false)
+	}
+}
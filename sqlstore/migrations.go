@@ -0,0 +1,145 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// Migration versioned schema change
+type Migration struct {
+	Version     int
+	Description string
+	Statements  []string
+}
+
+// Migrations schema of the store, applied in order by Migrate
+var Migrations = []Migration{
+	{
+		Version:     1,
+		Description: "create bank accounts, memberships, labels, transactions and attachments",
+		Statements: []string{
+			`CREATE TABLE bank_accounts (
+				iban                     TEXT PRIMARY KEY,
+				id                       TEXT NOT NULL,
+				organization_slug        TEXT NOT NULL,
+				slug                     TEXT NOT NULL,
+				bic                      TEXT NOT NULL,
+				currency                 TEXT NOT NULL,
+				balance_cents            INTEGER NOT NULL,
+				authorized_balance_cents INTEGER NOT NULL,
+				name                     TEXT NOT NULL,
+				status                   TEXT NOT NULL,
+				main                     BOOLEAN NOT NULL,
+				updated_at               TEXT NOT NULL
+			)`,
+			`CREATE TABLE memberships (
+				id         TEXT PRIMARY KEY,
+				first_name TEXT NOT NULL,
+				last_name  TEXT NOT NULL
+			)`,
+			`CREATE TABLE labels (
+				id        TEXT PRIMARY KEY,
+				name      TEXT NOT NULL,
+				parent_id TEXT NOT NULL
+			)`,
+			`CREATE TABLE transactions (
+				id                  TEXT PRIMARY KEY,
+				transaction_id      TEXT NOT NULL,
+				iban                TEXT NOT NULL,
+				amount_cents        INTEGER NOT NULL,
+				currency            TEXT NOT NULL,
+				local_amount_cents  INTEGER NOT NULL,
+				local_currency      TEXT NOT NULL,
+				side                TEXT NOT NULL,
+				operation_type      TEXT NOT NULL,
+				label               TEXT NOT NULL,
+				settled_at          TEXT NOT NULL,
+				emitted_at          TEXT NOT NULL,
+				updated_at          TEXT NOT NULL,
+				status              TEXT NOT NULL,
+				note                TEXT NOT NULL,
+				reference           TEXT NOT NULL,
+				vat_amount_cents    INTEGER NOT NULL,
+				vat_rate            REAL NOT NULL,
+				initiator_id        TEXT NOT NULL,
+				card_last_digits    TEXT NOT NULL,
+				category            TEXT NOT NULL,
+				attachment_lost     BOOLEAN NOT NULL,
+				attachment_required BOOLEAN NOT NULL
+			)`,
+			`CREATE TABLE transaction_labels (
+				transaction_id TEXT NOT NULL,
+				label_id       TEXT NOT NULL,
+				PRIMARY KEY (transaction_id, label_id)
+			)`,
+			`CREATE TABLE attachments (
+				id                TEXT PRIMARY KEY,
+				transaction_id    TEXT NOT NULL,
+				created_at        TEXT NOT NULL,
+				file_name         TEXT NOT NULL,
+				file_size         TEXT NOT NULL,
+				file_content_type TEXT NOT NULL,
+				url               TEXT NOT NULL
+			)`,
+			`CREATE INDEX transactions_iban_settled_at ON transactions (iban, settled_at)`,
+			`CREATE INDEX attachments_transaction_id ON attachments (transaction_id)`,
+		},
+	},
+	{
+		Version:     2,
+		Description: "keep the history of the transaction status changes",
+		Statements: []string{
+			`CREATE TABLE transaction_status_changes (
+				transaction_id  TEXT NOT NULL,
+				status          TEXT NOT NULL,
+				previous_status TEXT NOT NULL,
+				changed_at      TEXT NOT NULL,
+				PRIMARY KEY (transaction_id, changed_at, status)
+			)`,
+		},
+	},
+}
+
+// Migrate creates the schema_migrations table and applies the pending migrations, each in its own
+// transaction. It returns the version of the schema.
+func (s *Store) Migrate(ctx context.Context) (int, error) {
+	_, err := s.db.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+		version    INTEGER PRIMARY KEY,
+		applied_at TEXT NOT NULL
+	)`)
+	if err != nil {
+		return 0, err
+	}
+
+	var version int
+	err = s.db.QueryRowContext(ctx, `SELECT COALESCE(MAX(version), 0) FROM schema_migrations`).Scan(&version)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, m := range Migrations {
+		if m.Version <= version {
+			continue
+		}
+
+		err := s.inTx(ctx, func(tx *sql.Tx) error {
+			for _, stmt := range m.Statements {
+				if _, err := tx.ExecContext(ctx, stmt); err != nil {
+					return err
+				}
+			}
+			_, err := tx.ExecContext(ctx, `INSERT INTO schema_migrations (version, applied_at) VALUES (?, ?)`,
+				m.Version, formatTime(time.Now()))
+			return err
+		})
+		if err != nil {
+			return version, fmt.Errorf("sqlstore: migration %d (%s): %v", m.Version, m.Description, err)
+		}
+
+		version = m.Version
+	}
+
+	return version, nil
+}
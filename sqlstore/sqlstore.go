@@ -0,0 +1,290 @@
+// Package sqlstore upserts the Qonto organizations, memberships, labels, transactions and
+// attachments in a database/sql database, so that they can be queried with SQL.
+//
+// Queries use ? placeholders, as SQLite and MySQL do, and rely on UPDATE reporting the matched rows,
+// which requires the clientFoundRows parameter with MySQL. The schema is created by Store.Migrate.
+//
+// sqlstore is a separate module requiring Go 1.26, tested with make test-sqlstore.
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/pixelfactoryio/goqonto/v2"
+)
+
+// timeLayout layout of the stored times, in UTC so that they sort as text
+const timeLayout = "2006-01-02T15:04:05.000000000Z"
+
+// Store database of synced Qonto records
+type Store struct {
+	db  *sql.DB
+	now func() time.Time
+}
+
+// StatusChange transaction status change
+type StatusChange struct {
+	TransactionID  string
+	Status         string
+	PreviousStatus string
+	ChangedAt      time.Time
+}
+
+// New returns a Store writing to db
+func New(db *sql.DB) *Store {
+	return &Store{db: db, now: time.Now}
+}
+
+// UpsertOrganization upserts the bank accounts of an organization
+func (s *Store) UpsertOrganization(ctx context.Context, org goqonto.Organization) error {
+	return s.inTx(ctx, func(tx *sql.Tx) error {
+		for _, ba := range org.BankAccounts {
+			err := upsert(ctx, tx,
+				`UPDATE bank_accounts SET id = ?, organization_slug = ?, slug = ?, bic = ?, currency = ?,
+				balance_cents = ?, authorized_balance_cents = ?, name = ?, status = ?, main = ?, updated_at = ?
+				WHERE iban = ?`,
+				`INSERT INTO bank_accounts (id, organization_slug, slug, bic, currency, balance_cents,
+				authorized_balance_cents, name, status, main, updated_at, iban)
+				VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+				ba.ID, org.Slug, ba.Slug, ba.BIC, ba.Currency, ba.BalanceCents, ba.AuthorizedBalanceCents,
+				ba.Name, ba.Status, ba.Main, formatTime(ba.UpdatedAt), ba.IBAN)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// UpsertMemberships upserts memberships
+func (s *Store) UpsertMemberships(ctx context.Context, memberships ...goqonto.Membership) error {
+	return s.inTx(ctx, func(tx *sql.Tx) error {
+		for _, m := range memberships {
+			err := upsert(ctx, tx,
+				`UPDATE memberships SET first_name = ?, last_name = ? WHERE id = ?`,
+				`INSERT INTO memberships (first_name, last_name, id) VALUES (?, ?, ?)`,
+				m.FistName, m.LastName, m.ID)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// UpsertLabels upserts labels
+func (s *Store) UpsertLabels(ctx context.Context, labels ...goqonto.Label) error {
+	return s.inTx(ctx, func(tx *sql.Tx) error {
+		return upsertLabels(ctx, tx, labels)
+	})
+}
+
+// UpsertAttachments upserts the attachments of a transaction
+func (s *Store) UpsertAttachments(ctx context.Context, transactionID string, attachments ...goqonto.Attachment) error {
+	return s.inTx(ctx, func(tx *sql.Tx) error {
+		return upsertAttachments(ctx, tx, transactionID, attachments)
+	})
+}
+
+// UpsertTransactions upserts the transactions of the bank account identified by its IBAN, along with
+// their labels and attachments. A status change is recorded when a transaction is inserted or when
+// its status differs from the stored one, at the transaction update time or, when it is unknown, at
+// the sync time.
+func (s *Store) UpsertTransactions(ctx context.Context, iban string, transactions ...goqonto.Transaction) error {
+	syncedAt := s.now()
+	return s.inTx(ctx, func(tx *sql.Tx) error {
+		for _, t := range transactions {
+			if err := upsertTransaction(ctx, tx, iban, t, syncedAt); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// StatusChanges returns the status changes of a transaction, oldest first
+func (s *Store) StatusChanges(ctx context.Context, transactionID string) ([]StatusChange, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT status, previous_status, changed_at FROM transaction_status_changes
+		WHERE transaction_id = ? ORDER BY changed_at`, transactionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var changes []StatusChange
+	for rows.Next() {
+		c := StatusChange{TransactionID: transactionID}
+		var changedAt string
+		if err := rows.Scan(&c.Status, &c.PreviousStatus, &changedAt); err != nil {
+			return nil, err
+		}
+		if c.ChangedAt, err = parseTime(changedAt); err != nil {
+			return nil, err
+		}
+		changes = append(changes, c)
+	}
+
+	return changes, rows.Err()
+}
+
+func upsertTransaction(ctx context.Context, tx *sql.Tx, iban string, t goqonto.Transaction, syncedAt time.Time) error {
+	previous := ""
+	err := tx.QueryRowContext(ctx, `SELECT status FROM transactions WHERE id = ?`, t.ID).Scan(&previous)
+	inserted := err == sql.ErrNoRows
+	if err != nil && !inserted {
+		return err
+	}
+
+	err = upsert(ctx, tx,
+		`UPDATE transactions SET transaction_id = ?, iban = ?, amount_cents = ?, currency = ?,
+		local_amount_cents = ?, local_currency = ?, side = ?, operation_type = ?, label = ?, settled_at = ?,
+		emitted_at = ?, updated_at = ?, status = ?, note = ?, reference = ?, vat_amount_cents = ?, vat_rate = ?,
+		initiator_id = ?, card_last_digits = ?, category = ?, attachment_lost = ?, attachment_required = ?
+		WHERE id = ?`,
+		`INSERT INTO transactions (transaction_id, iban, amount_cents, currency, local_amount_cents,
+		local_currency, side, operation_type, label, settled_at, emitted_at, updated_at, status, note, reference,
+		vat_amount_cents, vat_rate, initiator_id, card_last_digits, category, attachment_lost, attachment_required,
+		id) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		t.TransactionID, iban, t.AmountCents, t.Currency, t.LocalAmountCents, t.LocalCurrency, t.Side,
+		t.OperationType, t.Label, formatTime(t.SettledAt), formatTime(t.EmittedAt), formatTime(t.UpdatedAt),
+		t.Status, t.Note, t.Reference, t.VatAmountCents, t.VatRate, t.InitiatorID, t.CardLastDigits,
+		t.Category, t.AttachmentLost, t.AttachmentRequired, t.ID)
+	if err != nil {
+		return err
+	}
+
+	if inserted || previous != t.Status {
+		changedAt := t.UpdatedAt
+		if changedAt.IsZero() {
+			changedAt = syncedAt
+		}
+		_, err := tx.ExecContext(ctx,
+			`INSERT INTO transaction_status_changes (transaction_id, status, previous_status, changed_at)
+			VALUES (?, ?, ?, ?)`, t.ID, t.Status, previous, formatTime(changedAt))
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := upsertLabels(ctx, tx, t.Labels); err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM transaction_labels WHERE transaction_id = ?`, t.ID); err != nil {
+		return err
+	}
+
+	ids := make(map[string]bool)
+	for _, id := range t.LabelIds {
+		ids[id] = true
+	}
+	for _, l := range t.Labels {
+		ids[l.ID] = true
+	}
+	for id := range ids {
+		_, err := tx.ExecContext(ctx, `INSERT INTO transaction_labels (transaction_id, label_id) VALUES (?, ?)`,
+			t.ID, id)
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := upsertAttachments(ctx, tx, t.ID, t.Attachments); err != nil {
+		return err
+	}
+
+	// Attachments only known by their ID are stored without details until they are upserted.
+	for _, id := range t.AttachmentIds {
+		var exists int
+		err := tx.QueryRowContext(ctx, `SELECT COUNT(*) FROM attachments WHERE id = ?`, id).Scan(&exists)
+		if err != nil {
+			return err
+		}
+		if exists > 0 {
+			continue
+		}
+		if err := upsertAttachments(ctx, tx, t.ID, []goqonto.Attachment{{ID: id}}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func upsertLabels(ctx context.Context, tx *sql.Tx, labels []goqonto.Label) error {
+	for _, l := range labels {
+		err := upsert(ctx, tx,
+			`UPDATE labels SET name = ?, parent_id = ? WHERE id = ?`,
+			`INSERT INTO labels (name, parent_id, id) VALUES (?, ?, ?)`,
+			l.Name, l.ParentID, l.ID)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func upsertAttachments(ctx context.Context, tx *sql.Tx, transactionID string, attachments []goqonto.Attachment) error {
+	for _, a := range attachments {
+		err := upsert(ctx, tx,
+			`UPDATE attachments SET transaction_id = ?, created_at = ?, file_name = ?, file_size = ?,
+			file_content_type = ?, url = ? WHERE id = ?`,
+			`INSERT INTO attachments (transaction_id, created_at, file_name, file_size, file_content_type, url, id)
+			VALUES (?, ?, ?, ?, ?, ?, ?)`,
+			transactionID, formatTime(a.CreatedAt), a.FileName, a.FileSize, a.FileContentType, a.URL, a.ID)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// upsert runs the update statement and, when it affects no row, the insert statement with the same
+// arguments. Unlike INSERT ... ON CONFLICT it does not depend on the SQL dialect.
+func upsert(ctx context.Context, tx *sql.Tx, update, insert string, args ...interface{}) error {
+	res, err := tx.ExecContext(ctx, update, args...)
+	if err != nil {
+		return err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil || n > 0 {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, insert, args...)
+	return err
+}
+
+// inTx runs f in a transaction, committed if f succeeds and rolled back otherwise
+func (s *Store) inTx(ctx context.Context, f func(tx *sql.Tx) error) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	if err := f(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// formatTime formats a time in UTC, the zero time being stored as an empty string
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(timeLayout)
+}
+
+func parseTime(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse(timeLayout, s)
+}
@@ -0,0 +1,278 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"reflect"
+	"testing"
+	"time"
+
+	_ "modernc.org/sqlite"
+
+	"github.com/pixelfactoryio/goqonto/v2"
+)
+
+const iban = "FR7616798000010000005663951"
+
+func newStore(t *testing.T) (*Store, *sql.DB) {
+	db, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Every connection to :memory: opens a new database.
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+
+	s := New(db)
+	version, err := s.Migrate(context.Background())
+	if err != nil {
+		t.Fatalf("Migrate returned error: %v", err)
+	}
+	if want := Migrations[len(Migrations)-1].Version; version != want {
+		t.Fatalf("Migrate \n got %v\n want %v\n", version, want)
+	}
+
+	return s, db
+}
+
+func count(t *testing.T, db *sql.DB, query string, args ...interface{}) int {
+	t.Helper()
+	var n int
+	if err := db.QueryRow(query, args...).Scan(&n); err != nil {
+		t.Fatal(err)
+	}
+	return n
+}
+
+func TestStore_Migrate(t *testing.T) {
+	s, db := newStore(t)
+
+	version, err := s.Migrate(context.Background())
+	if err != nil {
+		t.Fatalf("Migrate returned error: %v", err)
+	}
+	if want := len(Migrations); version != want {
+		t.Errorf("Migrate \n got %v\n want %v\n", version, want)
+	}
+	if got := count(t, db, `SELECT COUNT(*) FROM schema_migrations`); got != len(Migrations) {
+		t.Errorf("schema_migrations \n got %v\n want %v\n", got, len(Migrations))
+	}
+}
+
+func TestStore_UpsertOrganization(t *testing.T) {
+	s, db := newStore(t)
+	ctx := context.Background()
+
+	org := goqonto.Organization{
+		Slug: "croissant",
+		BankAccounts: []goqonto.BankAccount{
+			{ID: "account-1", IBAN: iban, Currency: "EUR", BalanceCents: 1000, Main: true},
+		},
+	}
+	if err := s.UpsertOrganization(ctx, org); err != nil {
+		t.Fatalf("UpsertOrganization returned error: %v", err)
+	}
+
+	org.BankAccounts[0].BalanceCents = 2500
+	if err := s.UpsertOrganization(ctx, org); err != nil {
+		t.Fatalf("UpsertOrganization returned error: %v", err)
+	}
+
+	var balance int
+	var main bool
+	err := db.QueryRow(`SELECT balance_cents, main FROM bank_accounts WHERE iban = ?`, iban).Scan(&balance, &main)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if balance != 2500 || !main {
+		t.Errorf("bank account \n got %v %v\n want %v %v\n", balance, main, 2500, true)
+	}
+	if got := count(t, db, `SELECT COUNT(*) FROM bank_accounts`); got != 1 {
+		t.Errorf("bank accounts \n got %v\n want %v\n", got, 1)
+	}
+}
+
+func TestStore_UpsertMemberships(t *testing.T) {
+	s, db := newStore(t)
+	ctx := context.Background()
+
+	err := s.UpsertMemberships(ctx, goqonto.Membership{ID: "m1", FistName: "Alice", LastName: "Martin"})
+	if err != nil {
+		t.Fatalf("UpsertMemberships returned error: %v", err)
+	}
+	err = s.UpsertMemberships(ctx, goqonto.Membership{ID: "m1", FistName: "Alice", LastName: "Dubois"})
+	if err != nil {
+		t.Fatalf("UpsertMemberships returned error: %v", err)
+	}
+
+	var lastName string
+	if err := db.QueryRow(`SELECT last_name FROM memberships WHERE id = 'm1'`).Scan(&lastName); err != nil {
+		t.Fatal(err)
+	}
+	if lastName != "Dubois" {
+		t.Errorf("last_name \n got %v\n want %v\n", lastName, "Dubois")
+	}
+}
+
+func TestStore_UpsertTransactions(t *testing.T) {
+	s, db := newStore(t)
+	ctx := context.Background()
+
+	emitted := time.Date(2021, time.March, 1, 10, 0, 0, 0, time.UTC)
+	settled := emitted.Add(48 * time.Hour)
+
+	travel := goqonto.Label{ID: "l1", Name: "Travel"}
+	train := goqonto.Label{ID: "l2", Name: "Train", ParentID: "l1"}
+	if err := s.UpsertLabels(ctx, travel); err != nil {
+		t.Fatalf("UpsertLabels returned error: %v", err)
+	}
+
+	tx := goqonto.Transaction{
+		ID:            "t1",
+		AmountCents:   4200,
+		Currency:      "EUR",
+		Side:          goqonto.TransactionSideDebit,
+		OperationType: goqonto.TransactionOperationTypeCard,
+		Label:         "SNCF",
+		Status:        goqonto.TransactionStatusPending,
+		EmittedAt:     emitted,
+		UpdatedAt:     emitted,
+		VatRate:       10,
+		LabelIds:      []string{"l1"},
+		Labels:        []goqonto.Label{travel},
+	}
+	if err := s.UpsertTransactions(ctx, iban, tx); err != nil {
+		t.Fatalf("UpsertTransactions returned error: %v", err)
+	}
+
+	// The same state does not add a status change.
+	if err := s.UpsertTransactions(ctx, iban, tx); err != nil {
+		t.Fatalf("UpsertTransactions returned error: %v", err)
+	}
+
+	tx.Status = goqonto.TransactionStatusCompleted
+	tx.SettledAt = settled
+	tx.UpdatedAt = settled
+	tx.LabelIds = []string{"l2"}
+	tx.Labels = []goqonto.Label{train}
+	tx.AttachmentIds = []string{"a1", "a2"}
+	tx.Attachments = []goqonto.Attachment{{ID: "a1", FileName: "sncf.pdf", CreatedAt: settled}}
+	if err := s.UpsertTransactions(ctx, iban, tx); err != nil {
+		t.Fatalf("UpsertTransactions returned error: %v", err)
+	}
+
+	var status, settledAt string
+	err := db.QueryRow(`SELECT status, settled_at FROM transactions WHERE id = 't1'`).Scan(&status, &settledAt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status != goqonto.TransactionStatusCompleted || settledAt != "2021-03-03T10:00:00.000000000Z" {
+		t.Errorf("transaction \n got %v %v\n want %v %v\n",
+			status, settledAt, "completed", "2021-03-03T10:00:00.000000000Z")
+	}
+
+	var labelID string
+	err = db.QueryRow(`SELECT label_id FROM transaction_labels WHERE transaction_id = 't1'`).Scan(&labelID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if labelID != "l2" || count(t, db, `SELECT COUNT(*) FROM transaction_labels`) != 1 {
+		t.Errorf("transaction labels \n got %v\n want %v\n", labelID, "l2")
+	}
+	if got := count(t, db, `SELECT COUNT(*) FROM labels WHERE parent_id = 'l1'`); got != 1 {
+		t.Errorf("child labels \n got %v\n want %v\n", got, 1)
+	}
+
+	if got := count(t, db, `SELECT COUNT(*) FROM attachments WHERE transaction_id = 't1'`); got != 2 {
+		t.Errorf("attachments \n got %v\n want %v\n", got, 2)
+	}
+
+	err = s.UpsertAttachments(ctx, "t1", goqonto.Attachment{ID: "a2", FileName: "sncf-2.pdf"})
+	if err != nil {
+		t.Fatalf("UpsertAttachments returned error: %v", err)
+	}
+	if got := count(t, db, `SELECT COUNT(*) FROM attachments WHERE file_name = 'sncf-2.pdf'`); got != 1 {
+		t.Errorf("attachment file name \n got %v\n want %v\n", got, 1)
+	}
+
+	changes, err := s.StatusChanges(ctx, "t1")
+	if err != nil {
+		t.Fatalf("StatusChanges returned error: %v", err)
+	}
+
+	want := []StatusChange{
+		{TransactionID: "t1", Status: goqonto.TransactionStatusPending, ChangedAt: emitted},
+		{
+			TransactionID:  "t1",
+			Status:         goqonto.TransactionStatusCompleted,
+			PreviousStatus: goqonto.TransactionStatusPending,
+			ChangedAt:      settled,
+		},
+	}
+	if !reflect.DeepEqual(changes, want) {
+		t.Errorf("StatusChanges \n got %v\n want %v\n", changes, want)
+	}
+}
+
+func TestStore_UpsertTransactions_noUpdatedAt(t *testing.T) {
+	s, _ := newStore(t)
+	ctx := context.Background()
+
+	synced := time.Date(2021, time.March, 1, 10, 0, 0, 0, time.UTC)
+	s.now = func() time.Time { return synced }
+
+	tx := goqonto.Transaction{ID: "t1", Status: goqonto.TransactionStatusPending}
+	if err := s.UpsertTransactions(ctx, iban, tx); err != nil {
+		t.Fatalf("UpsertTransactions returned error: %v", err)
+	}
+
+	// Another status change without update time does not collide with the first one.
+	s.now = func() time.Time { return synced.Add(time.Hour) }
+	tx.Status = goqonto.TransactionStatusDeclined
+	if err := s.UpsertTransactions(ctx, iban, tx); err != nil {
+		t.Fatalf("UpsertTransactions returned error: %v", err)
+	}
+	tx.Status = goqonto.TransactionStatusPending
+	if err := s.UpsertTransactions(ctx, iban, tx); err != nil {
+		t.Fatalf("UpsertTransactions returned error: %v", err)
+	}
+
+	changes, err := s.StatusChanges(ctx, "t1")
+	if err != nil {
+		t.Fatalf("StatusChanges returned error: %v", err)
+	}
+
+	var got []time.Time
+	for _, c := range changes {
+		got = append(got, c.ChangedAt)
+	}
+	want := []time.Time{synced, synced.Add(time.Hour), synced.Add(time.Hour)}
+	if len(changes) != 3 || !reflect.DeepEqual(got, want) {
+		t.Errorf("status changes at \n got %v\n want %v\n", got, want)
+	}
+}
+
+func TestStore_UpsertTransactions_rollback(t *testing.T) {
+	s, db := newStore(t)
+	ctx := context.Background()
+
+	// The status change of the second transaction already exists, so that its upsert fails.
+	tx := goqonto.Transaction{ID: "t1", Status: goqonto.TransactionStatusPending}
+	dup := goqonto.Transaction{
+		ID:        "t2",
+		Status:    goqonto.TransactionStatusPending,
+		UpdatedAt: time.Date(2021, time.March, 1, 10, 0, 0, 0, time.UTC),
+	}
+	_, err := db.Exec(`INSERT INTO transaction_status_changes VALUES (?, ?, ?, ?)`,
+		dup.ID, dup.Status, "", formatTime(dup.UpdatedAt))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.UpsertTransactions(ctx, iban, tx, dup); err == nil {
+		t.Fatalf("Expected error to be returned")
+	}
+	if got := count(t, db, `SELECT COUNT(*) FROM transactions`); got != 0 {
+		t.Errorf("transactions \n got %v\n want %v\n", got, 0)
+	}
+}
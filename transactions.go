@@ -242,3 +242,70 @@ func (s *TransactionsService) Get(ctx context.Context, id string) (*Transaction,
 
 	return root.Transaction, resp, nil
 }
+
+// TransactionIterator iterates over all the pages of transactions matching TransactionsOptions
+type TransactionIterator struct {
+	ctx     context.Context
+	service *TransactionsService
+	opt     TransactionsOptions
+
+	page    []Transaction
+	current Transaction
+	done    bool
+	err     error
+}
+
+// Iterator returns a TransactionIterator over all the transactions matching opt, fetching the pages lazily
+func (s *TransactionsService) Iterator(ctx context.Context, opt *TransactionsOptions) *TransactionIterator {
+	it := &TransactionIterator{
+		ctx:     ctx,
+		service: s,
+	}
+
+	if opt != nil {
+		it.opt = *opt
+	}
+
+	if it.opt.CurrentPage == 0 {
+		it.opt.CurrentPage = 1
+	}
+
+	return it
+}
+
+// Next advances the iterator to the next transaction, which will then be available through the
+// Transaction method. It returns false when the iteration stops, either by reaching the end of
+// the transactions or because of an error.
+func (it *TransactionIterator) Next() bool {
+	for len(it.page) == 0 {
+		if it.done || it.err != nil {
+			return false
+		}
+
+		transactions, resp, err := it.service.List(it.ctx, &it.opt)
+		if err != nil {
+			it.err = err
+			return false
+		}
+
+		it.page = transactions
+		if resp.Meta == nil || resp.Meta.NextPage == 0 || len(transactions) == 0 {
+			it.done = true
+		} else {
+			it.opt.CurrentPage = int64(resp.Meta.NextPage)
+		}
+	}
+
+	it.current, it.page = it.page[0], it.page[1:]
+	return true
+}
+
+// Transaction returns the current transaction
+func (it *TransactionIterator) Transaction() Transaction {
+	return it.current
+}
+
+// Err returns the first error encountered by the iterator
+func (it *TransactionIterator) Err() error {
+	return it.err
+}
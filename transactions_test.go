@@ -1,6 +1,7 @@
 package goqonto
 
 import (
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"reflect"
@@ -221,3 +222,62 @@ func TestTransactionsService_Get_Error(t *testing.T) {
 		t.Errorf("Expected empty body")
 	}
 }
+
+func TestTransactionsService_Iterator(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc(fmt.Sprintf("/%s", transactionsBasePath), func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, http.MethodGet)
+
+		opt := new(TransactionsOptions)
+		if err := json.NewDecoder(r.Body).Decode(opt); err != nil {
+			t.Fatalf("Unable to decode request body: %v", err)
+		}
+
+		switch opt.CurrentPage {
+		case 1:
+			fmt.Fprint(w, `{"transactions":[{"id":"t1"},{"id":"t2"}],"meta":{"current_page":1,"next_page":2}}`)
+		case 2:
+			fmt.Fprint(w, `{"transactions":[{"id":"t3"}],"meta":{"current_page":2,"next_page":null}}`)
+		default:
+			t.Errorf("Unexpected page %d", opt.CurrentPage)
+		}
+	})
+
+	it := client.Transactions.Iterator(ctx, &TransactionsOptions{Slug: "mycompany-9134"})
+
+	var got []string
+	for it.Next() {
+		got = append(got, it.Transaction().ID)
+	}
+
+	if err := it.Err(); err != nil {
+		t.Errorf("TransactionIterator returned error: %v", err)
+	}
+
+	want := []string{"t1", "t2", "t3"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("TransactionIterator \n got %v\n want %v\n", got, want)
+	}
+}
+
+func TestTransactionsService_Iterator_Error(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprint(w, `{ "message": "Internal error" }`)
+	})
+
+	it := client.Transactions.Iterator(ctx, nil)
+
+	if it.Next() {
+		t.Errorf("Expected Next to return false")
+	}
+
+	if it.Err() == nil {
+		t.Errorf("Expected error to be returned")
+	}
+}
@@ -0,0 +1,136 @@
+package goqonto
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// transfersBasePath Qonto API External Transfers Endpoint
+const transfersBasePath = "v2/external_transfers"
+
+// idempotencyKeyHeader header used to safely retry transfer creations
+const idempotencyKeyHeader = "X-Qonto-Idempotency-Key"
+
+// TransferStatusPending is a transfer that has been created but not yet processed.
+const TransferStatusPending = "pending"
+
+// TransferStatusProcessing is a transfer that is being processed.
+const TransferStatusProcessing = "processing"
+
+// TransferStatusCanceled is a transfer that has been canceled.
+const TransferStatusCanceled = "canceled"
+
+// TransferStatusDeclined is a transfer that has been declined.
+const TransferStatusDeclined = "declined"
+
+// TransferStatusSettled is a transfer that has been executed.
+const TransferStatusSettled = "settled"
+
+// TransfersService provides access to the external transfers in Qonto API
+type TransfersService service
+
+// TransferBeneficiary creditor of an external transfer
+type TransferBeneficiary struct {
+	Name string `json:"name"`
+	IBAN string `json:"iban"`
+	BIC  string `json:"bic,omitempty"`
+}
+
+// TransferRequest external transfer creation parameters
+// https://api-doc.qonto.eu/2.0/transfers/create-an-external-transfer
+type TransferRequest struct {
+	// ID of the debited bank account.
+	BankAccountID string `json:"bank_account_id,omitempty"`
+
+	// IBAN of the debited bank account, used when BankAccountID is not known.
+	DebitIBAN string `json:"debit_iban,omitempty"`
+
+	Beneficiary TransferBeneficiary `json:"beneficiary"`
+
+	// Amount as a decimal string, e.g. "12.30".
+	Amount   string `json:"amount"`
+	Currency string `json:"currency"`
+
+	// Message sent along the transfer.
+	Reference string `json:"reference"`
+
+	// Note added on the transaction.
+	Note string `json:"note,omitempty"`
+
+	// Execution date (yyyy-MM-dd), defaults to the current day.
+	ScheduledDate string `json:"scheduled_date,omitempty"`
+
+	// Key sent in the X-Qonto-Idempotency-Key header so that a retried request
+	// does not create the transfer twice.
+	IdempotencyKey string `json:"-"`
+}
+
+// Transfer struct
+// https://api-doc.qonto.eu/2.0/transfers/show-an-external-transfer
+type Transfer struct {
+	ID            string              `json:"id"`
+	Slug          string              `json:"slug,omitempty"`
+	Status        string              `json:"status"`
+	BankAccountID string              `json:"bank_account_id,omitempty"`
+	Beneficiary   TransferBeneficiary `json:"beneficiary"`
+	Amount        float64             `json:"amount"`
+	AmountCents   int                 `json:"amount_cents"`
+	Currency      string              `json:"currency"`
+	Reference     string              `json:"reference"`
+	Note          string              `json:"note,omitempty"`
+	ScheduledDate string              `json:"scheduled_date,omitempty"`
+	TransactionID string              `json:"transaction_id,omitempty"`
+	CreatedAt     time.Time           `json:"created_at"`
+}
+
+// transferRequestRoot root key in the JSON request for transfers
+type transferRequestRoot struct {
+	Transfer *TransferRequest `json:"external_transfer"`
+}
+
+// transferRoot root key in the JSON response for transfers
+type transferRoot struct {
+	Transfer *Transfer `json:"external_transfer"`
+}
+
+// Create an external Transfer
+func (s *TransfersService) Create(ctx context.Context, createRequest *TransferRequest) (*Transfer, *Response, error) {
+
+	req, err := s.client.NewRequest(ctx, http.MethodPost, transfersBasePath, &transferRequestRoot{createRequest})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if createRequest.IdempotencyKey != "" {
+		req.Header.Set(idempotencyKeyHeader, createRequest.IdempotencyKey)
+	}
+
+	root := new(transferRoot)
+	resp, err := s.client.Do(ctx, req, root)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return root.Transfer, resp, nil
+}
+
+// Get an external Transfer
+func (s *TransfersService) Get(ctx context.Context, id string) (*Transfer, *Response, error) {
+
+	path := fmt.Sprintf("%s/%s", transfersBasePath, id)
+
+	req, err := s.client.NewRequest(ctx, http.MethodGet, path, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	root := new(transferRoot)
+	resp, err := s.client.Do(ctx, req, root)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return root.Transfer, resp, nil
+}
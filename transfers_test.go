@@ -0,0 +1,143 @@
+package goqonto
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+)
+
+var (
+	transferFixture = `{
+		"external_transfer": {
+			"id": "7b7a5ed6-3903-4782-889d-b4f64bd7bef9",
+			"slug": "croissant-transfer-1",
+			"status": "pending",
+			"bank_account_id": "0d4c2b5e-0ca0-4d38-9bb7-1a4c1e2bc0a9",
+			"beneficiary": {
+				"name": "ACME Corp",
+				"iban": "FR1420041010050500013M02606",
+				"bic": "PSSTFRPPLIL"
+			},
+			"amount": 150.5,
+			"amount_cents": 15050,
+			"currency": "EUR",
+			"reference": "Invoice 42",
+			"scheduled_date": "2021-03-15",
+			"created_at": "2021-03-12T10:15:02.123Z"
+		}
+	}`
+
+	transferCreatedAt, _ = time.Parse(time.RFC3339, "2021-03-12T10:15:02.123Z")
+
+	transfer = Transfer{
+		ID:            "7b7a5ed6-3903-4782-889d-b4f64bd7bef9",
+		Slug:          "croissant-transfer-1",
+		Status:        TransferStatusPending,
+		BankAccountID: "0d4c2b5e-0ca0-4d38-9bb7-1a4c1e2bc0a9",
+		Beneficiary: TransferBeneficiary{
+			Name: "ACME Corp",
+			IBAN: "FR1420041010050500013M02606",
+			BIC:  "PSSTFRPPLIL",
+		},
+		Amount:        150.5,
+		AmountCents:   15050,
+		Currency:      "EUR",
+		Reference:     "Invoice 42",
+		ScheduledDate: "2021-03-15",
+		CreatedAt:     transferCreatedAt,
+	}
+)
+
+func TestTransfersService_Create(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc(fmt.Sprintf("/%s", transfersBasePath), func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, http.MethodPost)
+		testHeader(t, r, "Accept", mediaType)
+		testHeader(t, r, "Content-Type", mediaType)
+		testHeader(t, r, idempotencyKeyHeader, "E2E-42")
+		testBody(t, r, `{"external_transfer":{"bank_account_id":"0d4c2b5e-0ca0-4d38-9bb7-1a4c1e2bc0a9",`+
+			`"beneficiary":{"name":"ACME Corp","iban":"FR1420041010050500013M02606","bic":"PSSTFRPPLIL"},`+
+			`"amount":"150.50","currency":"EUR","reference":"Invoice 42","scheduled_date":"2021-03-15"}}`+"\n")
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprint(w, transferFixture)
+	})
+
+	createRequest := &TransferRequest{
+		BankAccountID: "0d4c2b5e-0ca0-4d38-9bb7-1a4c1e2bc0a9",
+		Beneficiary: TransferBeneficiary{
+			Name: "ACME Corp",
+			IBAN: "FR1420041010050500013M02606",
+			BIC:  "PSSTFRPPLIL",
+		},
+		Amount:         "150.50",
+		Currency:       "EUR",
+		Reference:      "Invoice 42",
+		ScheduledDate:  "2021-03-15",
+		IdempotencyKey: "E2E-42",
+	}
+
+	got, _, err := client.Transfers.Create(ctx, createRequest)
+	if err != nil {
+		t.Errorf("Transfers.Create returned error: %v", err)
+	}
+
+	want := &transfer
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Transfers.Create \n got %v\n want %v\n", got, want)
+	}
+}
+
+func TestTransfersService_Create_Error(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, http.MethodPost)
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		fmt.Fprint(w, `{ "message": "Invalid IBAN" }`)
+	})
+
+	got, resp, err := client.Transfers.Create(ctx, &TransferRequest{})
+
+	if err == nil {
+		t.Fatalf("Expected error to be returned")
+	}
+
+	if resp.StatusCode != http.StatusUnprocessableEntity {
+		t.Errorf("Expected 422 Status")
+	}
+
+	if got != nil {
+		t.Errorf("Expected empty body")
+	}
+}
+
+func TestTransfersService_Get(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc(
+		fmt.Sprintf("/%s/7b7a5ed6-3903-4782-889d-b4f64bd7bef9", transfersBasePath),
+		func(w http.ResponseWriter, r *http.Request) {
+			testMethod(t, r, http.MethodGet)
+			testHeader(t, r, "Accept", mediaType)
+			testHeader(t, r, "Content-Type", mediaType)
+			fmt.Fprint(w, transferFixture)
+		})
+
+	got, _, err := client.Transfers.Get(ctx, "7b7a5ed6-3903-4782-889d-b4f64bd7bef9")
+	if err != nil {
+		t.Errorf("Transfers.Get returned error: %v", err)
+	}
+
+	want := &transfer
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Transfers.Get \n got %v\n want %v\n", got, want)
+	}
+}
@@ -0,0 +1,260 @@
+// Package vat summarizes the VAT of Qonto transactions over a period, to prepare VAT returns such as
+// the French CA3
+package vat
+
+import (
+	"encoding/csv"
+	"errors"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/pixelfactoryio/goqonto/v2"
+)
+
+// dateLayout layout used to format the period
+const dateLayout = "2006-01-02"
+
+// RateMultiple is the VatRate of transactions mixing several VAT rates
+const RateMultiple = -1
+
+// ErrInvalidPeriod is returned when the end of the period is before its start
+var ErrInvalidPeriod = errors.New("vat: end of period is before its start")
+
+// IssueMissingVAT is a transaction without VAT rate nor VAT amount.
+const IssueMissingVAT = "missing_vat"
+
+// IssueMissingRate is a transaction with a VAT amount but no VAT rate.
+const IssueMissingRate = "missing_rate"
+
+// IssueMismatch is a transaction whose VAT amount does not match its rate and amount.
+const IssueMismatch = "mismatch"
+
+// Options VAT summary options
+type Options struct {
+	// Location in which days are computed. Defaults to UTC.
+	Location *time.Location
+
+	// Difference in cents tolerated between the VAT amount and the amount computed from the rate.
+	// Defaults to 1.
+	ToleranceCents int
+
+	// Exempt reports whether a transaction is legitimately not subject to VAT. Transactions without
+	// VAT are flagged with IssueMissingVAT unless Exempt returns true.
+	Exempt func(t goqonto.Transaction) bool
+}
+
+// Line VAT totals of the transactions of a side at a rate
+type Line struct {
+	Rate float64
+	Side string
+
+	Count int
+
+	// Amount including VAT, base amount excluding VAT and VAT amount.
+	AmountCents int
+	BaseCents   int
+	VATCents    int
+}
+
+// Issue transaction with missing or inconsistent VAT
+type Issue struct {
+	Transaction goqonto.Transaction
+	Reason      string
+
+	// VAT computed from the amount and the rate, for IssueMismatch.
+	ExpectedVATCents int
+}
+
+// Report VAT summary of a period
+type Report struct {
+	From time.Time
+	To   time.Time
+
+	// Lines sorted by side and rate.
+	Lines []Line
+
+	// VAT of credit transactions.
+	CollectedCents int
+
+	// VAT of debit transactions.
+	DeductibleCents int
+
+	Issues []Issue
+}
+
+// NetCents returns the VAT due for the period, negative for a VAT credit
+func (r *Report) NetCents() int {
+	return r.CollectedCents - r.DeductibleCents
+}
+
+// ExpectedVATCents returns the VAT included in an amount at a rate, rounded to the nearest cent
+func ExpectedVATCents(amountCents int, rate float64) int {
+	return int(math.Round(float64(amountCents) * rate / (100 + rate)))
+}
+
+// Summarize groups the completed transactions settled between from and to (inclusive days) by VAT
+// rate and side, computes the collected and deductible VAT from VatAmountCents and flags the
+// transactions with missing or inconsistent VAT. Transactions mixing several rates are summed under
+// RateMultiple and are not checked.
+func Summarize(transactions []goqonto.Transaction, from, to time.Time, opt *Options) (*Report, error) {
+	loc := time.UTC
+	tolerance := 1
+	var exempt func(goqonto.Transaction) bool
+	if opt != nil {
+		if opt.Location != nil {
+			loc = opt.Location
+		}
+		if opt.ToleranceCents != 0 {
+			tolerance = opt.ToleranceCents
+		}
+		exempt = opt.Exempt
+	}
+
+	fy, fm, fd := from.In(loc).Date()
+	ty, tm, td := to.In(loc).Date()
+	start := time.Date(fy, fm, fd, 0, 0, 0, 0, loc)
+	end := time.Date(ty, tm, td, 0, 0, 0, 0, loc).AddDate(0, 0, 1)
+	if !end.After(start) {
+		return nil, ErrInvalidPeriod
+	}
+
+	report := &Report{From: start, To: end.AddDate(0, 0, -1)}
+	lines := make(map[string]*Line)
+
+	for _, t := range transactions {
+		if t.Status != goqonto.TransactionStatusCompleted || t.SettledAt.Before(start) || !t.SettledAt.Before(end) {
+			continue
+		}
+
+		key := t.Side + "/" + strconv.FormatFloat(t.VatRate, 'f', -1, 64)
+		line, ok := lines[key]
+		if !ok {
+			line = &Line{Rate: t.VatRate, Side: t.Side}
+			lines[key] = line
+		}
+
+		line.Count++
+		line.AmountCents += t.AmountCents
+		line.VATCents += t.VatAmountCents
+		line.BaseCents += t.AmountCents - t.VatAmountCents
+
+		if t.Side == goqonto.TransactionSideDebit {
+			report.DeductibleCents += t.VatAmountCents
+		} else {
+			report.CollectedCents += t.VatAmountCents
+		}
+
+		if issue, ok := check(t, tolerance, exempt); ok {
+			report.Issues = append(report.Issues, issue)
+		}
+	}
+
+	for _, line := range lines {
+		report.Lines = append(report.Lines, *line)
+	}
+
+	sort.Slice(report.Lines, func(i, j int) bool {
+		if report.Lines[i].Side != report.Lines[j].Side {
+			return report.Lines[i].Side < report.Lines[j].Side
+		}
+		return report.Lines[i].Rate < report.Lines[j].Rate
+	})
+
+	return report, nil
+}
+
+// check returns the VAT issue of a transaction, if any
+func check(t goqonto.Transaction, tolerance int, exempt func(goqonto.Transaction) bool) (Issue, bool) {
+	switch {
+	case t.VatRate == RateMultiple:
+		return Issue{}, false
+	case t.VatRate == 0 && t.VatAmountCents == 0:
+		if exempt != nil && exempt(t) {
+			return Issue{}, false
+		}
+		return Issue{Transaction: t, Reason: IssueMissingVAT}, true
+	case t.VatRate == 0:
+		return Issue{Transaction: t, Reason: IssueMissingRate}, true
+	}
+
+	expected := ExpectedVATCents(t.AmountCents, t.VatRate)
+	if diff := expected - t.VatAmountCents; diff > tolerance || diff < -tolerance {
+		return Issue{Transaction: t, Reason: IssueMismatch, ExpectedVATCents: expected}, true
+	}
+
+	return Issue{}, false
+}
+
+// WriteCSV writes the lines of the report as CSV with a rate,side,count,amount_cents,base_cents,vat_cents
+// header, followed by collected, deductible and net totals
+func (r *Report) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+
+	if err := cw.Write([]string{"rate", "side", "count", "amount_cents", "base_cents", "vat_cents"}); err != nil {
+		return err
+	}
+
+	for _, l := range r.Lines {
+		record := []string{
+			strconv.FormatFloat(l.Rate, 'f', -1, 64),
+			l.Side,
+			strconv.Itoa(l.Count),
+			strconv.Itoa(l.AmountCents),
+			strconv.Itoa(l.BaseCents),
+			strconv.Itoa(l.VATCents),
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+
+	totals := [][]string{
+		{"", "collected", "", "", "", strconv.Itoa(r.CollectedCents)},
+		{"", "deductible", "", "", "", strconv.Itoa(r.DeductibleCents)},
+		{"", "net", "", "", "", strconv.Itoa(r.NetCents())},
+	}
+	if err := cw.WriteAll(totals); err != nil {
+		return err
+	}
+
+	return cw.Error()
+}
+
+// WriteIssuesCSV writes the issues of the report as CSV with a
+// transaction_id,settled_at,label,amount_cents,vat_rate,vat_amount_cents,expected_vat_cents,reason header
+func (r *Report) WriteIssuesCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+
+	header := []string{
+		"transaction_id", "settled_at", "label", "amount_cents", "vat_rate", "vat_amount_cents",
+		"expected_vat_cents", "reason",
+	}
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+
+	for _, i := range r.Issues {
+		record := []string{
+			i.Transaction.TransactionID,
+			i.Transaction.SettledAt.In(r.From.Location()).Format(dateLayout),
+			i.Transaction.Label,
+			strconv.Itoa(i.Transaction.AmountCents),
+			strconv.FormatFloat(i.Transaction.VatRate, 'f', -1, 64),
+			strconv.Itoa(i.Transaction.VatAmountCents),
+			"",
+			i.Reason,
+		}
+		if i.Reason == IssueMismatch {
+			record[6] = strconv.Itoa(i.ExpectedVATCents)
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,195 @@
+package vat
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/pixelfactoryio/goqonto/v2"
+)
+
+func tx(id, side string, cents int, rate float64, vat int, day int) goqonto.Transaction {
+	return goqonto.Transaction{
+		TransactionID:  id,
+		Label:          id,
+		Side:           side,
+		AmountCents:    cents,
+		VatRate:        rate,
+		VatAmountCents: vat,
+		Status:         goqonto.TransactionStatusCompleted,
+		SettledAt:      time.Date(2020, time.March, day, 12, 0, 0, 0, time.UTC),
+	}
+}
+
+func testTransactions() []goqonto.Transaction {
+	pending := tx("pending", "debit", 1200, 20, 200, 10)
+	pending.Status = goqonto.TransactionStatusPending
+
+	return []goqonto.Transaction{
+		tx("sale", "credit", 120000, 20, 20000, 2),
+		tx("hotel", "debit", 11000, 10, 1000, 5),
+		tx("laptop", "debit", 240000, 20, 40000, 6),
+		tx("wrong", "debit", 1200, 20, 300, 7),
+		tx("norate", "debit", 1200, 0, 200, 8),
+		tx("novat", "debit", 500, 0, 0, 9),
+		tx("mixed", "debit", 3000, RateMultiple, 400, 9),
+		tx("april", "debit", 1200, 20, 200, 40),
+		pending,
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	from := time.Date(2020, time.March, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2020, time.March, 31, 0, 0, 0, 0, time.UTC)
+
+	r, err := Summarize(testTransactions(), from, to, nil)
+	if err != nil {
+		t.Fatalf("Summarize returned error: %v", err)
+	}
+
+	want := []Line{
+		{Rate: 20, Side: "credit", Count: 1, AmountCents: 120000, BaseCents: 100000, VATCents: 20000},
+		{Rate: RateMultiple, Side: "debit", Count: 1, AmountCents: 3000, BaseCents: 2600, VATCents: 400},
+		{Rate: 0, Side: "debit", Count: 2, AmountCents: 1700, BaseCents: 1500, VATCents: 200},
+		{Rate: 10, Side: "debit", Count: 1, AmountCents: 11000, BaseCents: 10000, VATCents: 1000},
+		{Rate: 20, Side: "debit", Count: 2, AmountCents: 241200, BaseCents: 200900, VATCents: 40300},
+	}
+	if !reflect.DeepEqual(r.Lines, want) {
+		t.Errorf("Summarize lines \n got %+v\n want %+v\n", r.Lines, want)
+	}
+
+	if r.CollectedCents != 20000 || r.DeductibleCents != 41900 || r.NetCents() != -21900 {
+		t.Errorf("Summarize totals \n got %d, %d, %d\n want 20000, 41900, -21900\n",
+			r.CollectedCents, r.DeductibleCents, r.NetCents())
+	}
+
+	var issues []string
+	for _, i := range r.Issues {
+		issues = append(issues, i.Transaction.TransactionID+" "+i.Reason)
+	}
+	wantIssues := []string{"wrong mismatch", "norate missing_rate", "novat missing_vat"}
+	if !reflect.DeepEqual(issues, wantIssues) {
+		t.Errorf("Summarize issues \n got %v\n want %v\n", issues, wantIssues)
+	}
+
+	if r.Issues[0].ExpectedVATCents != 200 {
+		t.Errorf("Summarize expected VAT \n got %d\n want 200\n", r.Issues[0].ExpectedVATCents)
+	}
+
+	var buf bytes.Buffer
+	if err := r.WriteCSV(&buf); err != nil {
+		t.Fatalf("WriteCSV returned error: %v", err)
+	}
+
+	wantCSV := "rate,side,count,amount_cents,base_cents,vat_cents\n" +
+		"20,credit,1,120000,100000,20000\n" +
+		"-1,debit,1,3000,2600,400\n" +
+		"0,debit,2,1700,1500,200\n" +
+		"10,debit,1,11000,10000,1000\n" +
+		"20,debit,2,241200,200900,40300\n" +
+		",collected,,,,20000\n" +
+		",deductible,,,,41900\n" +
+		",net,,,,-21900\n"
+	if buf.String() != wantCSV {
+		t.Errorf("WriteCSV \n got %s\n want %s\n", buf.String(), wantCSV)
+	}
+
+	buf.Reset()
+	if err := r.WriteIssuesCSV(&buf); err != nil {
+		t.Fatalf("WriteIssuesCSV returned error: %v", err)
+	}
+
+	wantIssuesCSV := "transaction_id,settled_at,label,amount_cents,vat_rate,vat_amount_cents,expected_vat_cents,reason\n" +
+		"wrong,2020-03-07,wrong,1200,20,300,200,mismatch\n" +
+		"norate,2020-03-08,norate,1200,0,200,,missing_rate\n" +
+		"novat,2020-03-09,novat,500,0,0,,missing_vat\n"
+	if buf.String() != wantIssuesCSV {
+		t.Errorf("WriteIssuesCSV \n got %s\n want %s\n", buf.String(), wantIssuesCSV)
+	}
+}
+
+func TestSummarizeExempt(t *testing.T) {
+	from := time.Date(2020, time.March, 1, 0, 0, 0, 0, time.UTC)
+
+	exempt := func(t goqonto.Transaction) bool { return t.Label == "novat" }
+	r, err := Summarize(testTransactions(), from, from.AddDate(0, 0, 30), &Options{Exempt: exempt, ToleranceCents: 100})
+	if err != nil {
+		t.Fatalf("Summarize returned error: %v", err)
+	}
+
+	if len(r.Issues) != 1 || r.Issues[0].Reason != IssueMissingRate {
+		t.Errorf("Summarize issues \n got %v\n want [missing_rate]\n", r.Issues)
+	}
+
+	if _, err := Summarize(nil, from, from.AddDate(0, 0, -1), nil); err != ErrInvalidPeriod {
+		t.Errorf("Summarize \n got %v\n want %v\n", err, ErrInvalidPeriod)
+	}
+}
+
+func TestSummarizeLocation(t *testing.T) {
+	paris, err := time.LoadLocation("Europe/Paris")
+	if err != nil {
+		t.Skipf("Europe/Paris time zone unavailable: %v", err)
+	}
+
+	// Midnight in Paris, the previous day in UTC.
+	from := time.Date(2020, time.February, 29, 23, 0, 0, 0, time.UTC)
+	to := time.Date(2020, time.March, 30, 22, 30, 0, 0, time.UTC)
+
+	r, err := Summarize(testTransactions(), from, to, &Options{Location: paris})
+	if err != nil {
+		t.Fatalf("Summarize returned error: %v", err)
+	}
+
+	wantFrom := time.Date(2020, time.March, 1, 0, 0, 0, 0, paris)
+	wantTo := time.Date(2020, time.March, 31, 0, 0, 0, 0, paris)
+	if !r.From.Equal(wantFrom) || !r.To.Equal(wantTo) {
+		t.Errorf("Summarize period \n got %v - %v\n want %v - %v\n", r.From, r.To, wantFrom, wantTo)
+	}
+}
+
+func TestWriteIssuesCSVLocation(t *testing.T) {
+	paris, err := time.LoadLocation("Europe/Paris")
+	if err != nil {
+		t.Skipf("Europe/Paris time zone unavailable: %v", err)
+	}
+
+	// Settled on April 1 in Paris, March 31 in UTC.
+	late := tx("late", "debit", 1200, 0, 200, 31)
+	late.SettledAt = time.Date(2020, time.March, 31, 22, 30, 0, 0, time.UTC)
+
+	from := time.Date(2020, time.April, 1, 0, 0, 0, 0, paris)
+	r, err := Summarize([]goqonto.Transaction{late}, from, from.AddDate(0, 0, 29), &Options{Location: paris})
+	if err != nil {
+		t.Fatalf("Summarize returned error: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := r.WriteIssuesCSV(&buf); err != nil {
+		t.Fatalf("WriteIssuesCSV returned error: %v", err)
+	}
+
+	if !bytes.Contains(buf.Bytes(), []byte("late,2020-04-01,")) {
+		t.Errorf("WriteIssuesCSV \n got %s\n want the 2020-04-01 settlement date\n", buf.String())
+	}
+}
+
+func TestExpectedVATCents(t *testing.T) {
+	tests := []struct {
+		amount int
+		rate   float64
+		want   int
+	}{
+		{12000, 20, 2000},
+		{1055, 5.5, 55},
+		{1021, 2.1, 21},
+		{999, 10, 91},
+	}
+
+	for _, tt := range tests {
+		if got := ExpectedVATCents(tt.amount, tt.rate); got != tt.want {
+			t.Errorf("ExpectedVATCents(%d, %v) \n got %d\n want %d\n", tt.amount, tt.rate, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,196 @@
+package goqonto
+
+import (
+	"context"
+	"time"
+)
+
+// TransactionEventCreated is a transaction seen for the first time, or not updated for the watch
+// retention period.
+const TransactionEventCreated = "created"
+
+// TransactionEventUpdated is a transaction updated without changing status.
+const TransactionEventUpdated = "updated"
+
+// TransactionEventStatusChanged is a transaction whose status changed.
+const TransactionEventStatusChanged = "status_changed"
+
+// updatedAtLayout layout of the updated_at_from query string
+const updatedAtLayout = "2006-01-02T15:04:05.000Z07:00"
+
+// TransactionEvent change of a transaction detected by Watch
+type TransactionEvent struct {
+	Type        string
+	Transaction Transaction
+
+	// Status of the transaction before a TransactionEventStatusChanged event.
+	PreviousStatus string
+}
+
+// WatchOptions options of TransactionsService.Watch
+type WatchOptions struct {
+	// Transactions filters. UpdatedAtFrom, SortBy and CurrentPage are set by Watch.
+	TransactionsOptions
+
+	// Interval between the end of a poll and the start of the next one. Defaults to 1 minute.
+	Interval time.Duration
+
+	// Transactions updated before Since are ignored. Defaults to the time Watch is called.
+	Since time.Time
+
+	// Every poll fetches the transactions updated since the last seen update minus Overlap, to catch
+	// updates committed late. Defaults to 1 minute.
+	Overlap time.Duration
+
+	// Statuses of the transactions not updated for Retention are forgotten, their next update is
+	// reported as created. Defaults to 30 days.
+	Retention time.Duration
+
+	// Capacity of the events channel. Defaults to 0: polling waits for the events to be received.
+	Buffer int
+}
+
+// Watch polls the transactions matching opt and sends an event on the returned channel for every
+// transaction created or updated since opt.Since. Polling stops and both channels are closed when ctx
+// is canceled. Errors do not stop polling, they are sent on the errors channel, which holds a single
+// error: errors are dropped while the previous one has not been received.
+func (s *TransactionsService) Watch(ctx context.Context, opt *WatchOptions) (<-chan TransactionEvent, <-chan error) {
+	w := &watcher{
+		service:  s,
+		recent:   make(map[string]time.Time),
+		statuses: make(map[string]watchedStatus),
+	}
+
+	if opt != nil {
+		w.opt = *opt
+	}
+	if w.opt.Interval <= 0 {
+		w.opt.Interval = time.Minute
+	}
+	if w.opt.Overlap <= 0 {
+		w.opt.Overlap = time.Minute
+	}
+	if w.opt.Retention <= 0 {
+		w.opt.Retention = 30 * 24 * time.Hour
+	}
+	if w.opt.Since.IsZero() {
+		w.opt.Since = time.Now()
+	}
+	w.cursor = w.opt.Since
+
+	events := make(chan TransactionEvent, w.opt.Buffer)
+	errs := make(chan error, 1)
+
+	go func() {
+		defer close(events)
+		defer close(errs)
+
+		for {
+			if err := w.poll(ctx, events); err != nil {
+				if ctx.Err() != nil {
+					return
+				}
+				select {
+				case errs <- err:
+				default:
+				}
+			}
+
+			timer := time.NewTimer(w.opt.Interval)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			case <-timer.C:
+			}
+		}
+	}()
+
+	return events, errs
+}
+
+type watcher struct {
+	service *TransactionsService
+	opt     WatchOptions
+
+	// Most recent update seen.
+	cursor time.Time
+
+	// Last seen update of the transactions updated within the overlap window, by ID, to skip the
+	// updates fetched again.
+	recent map[string]time.Time
+
+	// Last seen status of the transactions updated within the retention period, by ID.
+	statuses map[string]watchedStatus
+}
+
+// watchedStatus last seen status of a transaction
+type watchedStatus struct {
+	status    string
+	updatedAt time.Time
+}
+
+// poll fetches the transactions updated since the cursor and sends their events
+func (w *watcher) poll(ctx context.Context, events chan<- TransactionEvent) error {
+	opt := w.opt.TransactionsOptions
+	opt.UpdatedAtFrom = w.cursor.Add(-w.opt.Overlap).UTC().Format(updatedAtLayout)
+	opt.SortBy = TransactionSortByUpdatedAtAsc
+	opt.CurrentPage = 0
+
+	it := w.service.Iterator(ctx, &opt)
+	for it.Next() {
+		t := it.Transaction()
+		if t.UpdatedAt.Before(w.opt.Since) {
+			continue
+		}
+
+		if at, ok := w.recent[t.ID]; ok && !t.UpdatedAt.After(at) {
+			continue
+		}
+
+		e := TransactionEvent{Transaction: t}
+		previous, ok := w.statuses[t.ID]
+		switch {
+		case !ok:
+			e.Type = TransactionEventCreated
+		case t.Status != previous.status:
+			e.Type = TransactionEventStatusChanged
+			e.PreviousStatus = previous.status
+		default:
+			e.Type = TransactionEventUpdated
+		}
+
+		select {
+		case events <- e:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+
+		w.recent[t.ID] = t.UpdatedAt
+		w.statuses[t.ID] = watchedStatus{status: t.Status, updatedAt: t.UpdatedAt}
+		if t.UpdatedAt.After(w.cursor) {
+			w.cursor = t.UpdatedAt
+		}
+	}
+
+	w.prune()
+	return it.Err()
+}
+
+// prune forgets the updates before the window of the next poll and the statuses of the transactions
+// not updated within the retention period
+func (w *watcher) prune() {
+	from := w.cursor.Add(-w.opt.Overlap)
+	for id, at := range w.recent {
+		if at.Before(from) {
+			delete(w.recent, id)
+		}
+	}
+
+	retained := w.cursor.Add(-w.opt.Retention)
+	for id, s := range w.statuses {
+		if s.updatedAt.Before(retained) {
+			delete(w.statuses, id)
+		}
+	}
+}
@@ -0,0 +1,186 @@
+package goqonto
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func watchFixture(transactions ...string) string {
+	return fmt.Sprintf(`{"transactions": [%s], "meta": {"current_page": 1, "next_page": null}}`,
+		strings.Join(transactions, ","))
+}
+
+func watchTransaction(id, status, updatedAt string) string {
+	return fmt.Sprintf(`{"id": %q, "status": %q, "updated_at": %q}`, id, status, updatedAt)
+}
+
+func TestTransactionsService_Watch(t *testing.T) {
+	setup()
+	defer teardown()
+
+	polls := []string{
+		watchFixture(
+			watchTransaction("t0", "completed", "2021-03-01T09:00:00Z"),
+			watchTransaction("t1", "pending", "2021-03-01T10:00:00Z"),
+		),
+		watchFixture(
+			watchTransaction("t1", "pending", "2021-03-01T10:00:00Z"),
+			watchTransaction("t2", "pending", "2021-03-01T10:01:00Z"),
+		),
+		// Status changed and updates long after the overlap window.
+		watchFixture(watchTransaction("t1", "completed", "2021-03-01T14:00:00Z")),
+		watchFixture(watchTransaction("t2", "pending", "2021-03-01T15:00:00Z")),
+	}
+
+	var mu sync.Mutex
+	var froms []string
+
+	mux.HandleFunc(fmt.Sprintf("/%s", transactionsBasePath), func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, http.MethodGet)
+
+		opt := new(TransactionsOptions)
+		if err := json.NewDecoder(r.Body).Decode(opt); err != nil {
+			t.Errorf("Unable to decode request body: %v", err)
+		}
+		if opt.Slug != "croissant" || opt.SortBy != TransactionSortByUpdatedAtAsc {
+			t.Errorf("Watch request options \n got %+v\n", opt)
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		froms = append(froms, opt.UpdatedAtFrom)
+		if len(froms) > len(polls) {
+			fmt.Fprint(w, watchFixture())
+			return
+		}
+		fmt.Fprint(w, polls[len(froms)-1])
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	events, errs := client.Transactions.Watch(ctx, &WatchOptions{
+		TransactionsOptions: TransactionsOptions{Slug: "croissant"},
+		Interval:            time.Millisecond,
+		Since:               time.Date(2021, time.March, 1, 9, 30, 0, 0, time.UTC),
+	})
+
+	var got []string
+	for len(got) < 4 {
+		select {
+		case e := <-events:
+			got = append(got, e.Type+" "+e.Transaction.ID+" "+e.PreviousStatus+e.Transaction.Status)
+		case err := <-errs:
+			t.Fatalf("Watch returned error: %v", err)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("Watch timed out after %v", got)
+		}
+	}
+
+	want := []string{
+		"created t1 pending",
+		"created t2 pending",
+		"status_changed t1 pendingcompleted",
+		"updated t2 pending",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Watch events \n got %v\n want %v\n", got, want)
+	}
+
+	cancel()
+	for range events {
+	}
+	if _, ok := <-errs; ok {
+		t.Errorf("Watch errors channel not closed")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	wantFroms := []string{"2021-03-01T09:29:00.000Z", "2021-03-01T09:59:00.000Z", "2021-03-01T10:00:00.000Z"}
+	if !reflect.DeepEqual(froms[:3], wantFroms) {
+		t.Errorf("Watch updated_at_from \n got %v\n want %v\n", froms[:3], wantFroms)
+	}
+}
+
+func TestTransactionsService_Watch_Error(t *testing.T) {
+	setup()
+	defer teardown()
+
+	calls := 0
+	mux.HandleFunc(fmt.Sprintf("/%s", transactionsBasePath), func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+			fmt.Fprint(w, `{"message": "Internal error"}`)
+			return
+		}
+		fmt.Fprint(w, watchFixture(watchTransaction("t1", "pending", "2021-03-01T10:00:00Z")))
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	events, errs := client.Transactions.Watch(ctx, &WatchOptions{
+		Interval: time.Millisecond,
+		Since:    time.Date(2021, time.March, 1, 0, 0, 0, 0, time.UTC),
+	})
+
+	select {
+	case err := <-errs:
+		if _, ok := err.(*ErrorResponse); !ok {
+			t.Errorf("Watch error \n got %T\n want *ErrorResponse\n", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Watch did not report the error")
+	}
+
+	select {
+	case e := <-events:
+		if e.Transaction.ID != "t1" {
+			t.Errorf("Watch event \n got %v\n want t1\n", e.Transaction.ID)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Watch did not resume after the error")
+	}
+}
+
+func TestWatcher_prune(t *testing.T) {
+	now := time.Date(2021, time.March, 1, 10, 0, 0, 0, time.UTC)
+
+	w := &watcher{
+		opt:    WatchOptions{Overlap: time.Minute, Retention: time.Hour},
+		cursor: now,
+		recent: map[string]time.Time{
+			"old":    now.Add(-2 * time.Minute),
+			"recent": now.Add(-time.Minute),
+		},
+		statuses: map[string]watchedStatus{
+			"expired":  {status: "pending", updatedAt: now.Add(-2 * time.Hour)},
+			"old":      {status: "pending", updatedAt: now.Add(-2 * time.Minute)},
+			"retained": {status: "pending", updatedAt: now.Add(-time.Hour)},
+		},
+	}
+	w.prune()
+
+	wantRecent := map[string]time.Time{"recent": now.Add(-time.Minute)}
+	if !reflect.DeepEqual(w.recent, wantRecent) {
+		t.Errorf("recent \n got %v\n want %v\n", w.recent, wantRecent)
+	}
+
+	wantStatuses := map[string]watchedStatus{
+		"old":      {status: "pending", updatedAt: now.Add(-2 * time.Minute)},
+		"retained": {status: "pending", updatedAt: now.Add(-time.Hour)},
+	}
+	if !reflect.DeepEqual(w.statuses, wantStatuses) {
+		t.Errorf("statuses \n got %v\n want %v\n", w.statuses, wantStatuses)
+	}
+}
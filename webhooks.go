@@ -0,0 +1,151 @@
+package goqonto
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// webhooksBasePath Qonto API Webhook subscriptions Endpoint
+const webhooksBasePath = "v2/webhook_subscriptions"
+
+// WebhooksService provides access to the webhook subscriptions in Qonto API
+type WebhooksService service
+
+// WebhooksOptions Qonto API Webhook subscriptions query strings
+type WebhooksOptions struct {
+	CurrentPage int64 `json:"current_page,omitempty"`
+	PerPage     int64 `json:"per_page,omitempty"`
+}
+
+// Webhook struct
+type Webhook struct {
+	ID         string   `json:"id"`
+	URL        string   `json:"url"`
+	EventTypes []string `json:"event_types"`
+
+	// Secret used to sign the deliveries, only returned on creation and secret rotation.
+	Secret string `json:"secret,omitempty"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// WebhookRequest struct used to create and update webhook subscriptions
+type WebhookRequest struct {
+	URL        string   `json:"url,omitempty"`
+	EventTypes []string `json:"event_types,omitempty"`
+
+	// Generates a new secret, returned in Webhook.Secret.
+	RotateSecret bool `json:"rotate_secret,omitempty"`
+}
+
+// webhookRequestRoot root key in the JSON request for webhook subscriptions
+type webhookRequestRoot struct {
+	Webhook *WebhookRequest `json:"webhook_subscription"`
+}
+
+// webhookRoot root key in the JSON response for webhook subscriptions
+type webhookRoot struct {
+	Webhook *Webhook `json:"webhook_subscription"`
+}
+
+// webhooksRoot root key in the JSON response for the list of webhook subscriptions
+type webhooksRoot struct {
+	Webhooks []Webhook `json:"webhook_subscriptions"`
+}
+
+// webhookTestRequest JSON request triggering a test delivery
+type webhookTestRequest struct {
+	EventType string `json:"event_type"`
+}
+
+// List all the webhook subscriptions
+func (s *WebhooksService) List(ctx context.Context, opt *WebhooksOptions) ([]Webhook, *Response, error) {
+
+	req, err := s.client.NewRequest(ctx, http.MethodGet, webhooksBasePath, opt)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	type respWithMeta struct {
+		webhooksRoot
+		metaRoot
+	}
+
+	root := new(respWithMeta)
+	resp, err := s.client.Do(ctx, req, root)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	if m := &root.metaRoot; m != nil {
+		resp.Meta = &m.Meta
+	}
+
+	return root.Webhooks, resp, nil
+}
+
+// Get a webhook subscription
+func (s *WebhooksService) Get(ctx context.Context, id string) (*Webhook, *Response, error) {
+	path := fmt.Sprintf("%s/%s", webhooksBasePath, id)
+	return s.do(ctx, http.MethodGet, path, nil)
+}
+
+// Create a webhook subscription
+func (s *WebhooksService) Create(ctx context.Context, createRequest *WebhookRequest) (*Webhook, *Response, error) {
+	return s.do(ctx, http.MethodPost, webhooksBasePath, &webhookRequestRoot{createRequest})
+}
+
+// Update the URL or event types of a webhook subscription, or rotate its secret
+func (s *WebhooksService) Update(
+	ctx context.Context,
+	id string,
+	updateRequest *WebhookRequest,
+) (*Webhook, *Response, error) {
+	path := fmt.Sprintf("%s/%s", webhooksBasePath, id)
+	return s.do(ctx, http.MethodPatch, path, &webhookRequestRoot{updateRequest})
+}
+
+// Delete a webhook subscription
+func (s *WebhooksService) Delete(ctx context.Context, id string) (*Response, error) {
+
+	path := fmt.Sprintf("%s/%s", webhooksBasePath, id)
+
+	req, err := s.client.NewRequest(ctx, http.MethodDelete, path, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.client.Do(ctx, req, nil)
+}
+
+// Test triggers the delivery of a test event of the given type to a webhook subscription
+func (s *WebhooksService) Test(ctx context.Context, id, eventType string) (*Response, error) {
+
+	path := fmt.Sprintf("%s/%s/test", webhooksBasePath, id)
+
+	req, err := s.client.NewRequest(ctx, http.MethodPost, path, &webhookTestRequest{eventType})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.client.Do(ctx, req, nil)
+}
+
+func (s *WebhooksService) do(ctx context.Context, method, path string, body interface{}) (*Webhook, *Response, error) {
+
+	req, err := s.client.NewRequest(ctx, method, path, body)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	root := new(webhookRoot)
+	resp, err := s.client.Do(ctx, req, root)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return root.Webhook, resp, nil
+}
@@ -0,0 +1,358 @@
+// Package webhooks receives Qonto webhook events: it verifies their signature, de-duplicates them and
+// dispatches them to the handlers registered for their type
+package webhooks
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pixelfactoryio/goqonto/v2"
+)
+
+// SignatureHeader is the header holding the signature of webhook requests, formatted as
+// t=<unix timestamp>,v1=<hex HMAC-SHA256 of "<timestamp>.<body>">. Several v1 signatures may be
+// sent while the secret is rotated.
+const SignatureHeader = "X-Qonto-Signature"
+
+// EventTransactionCreated is sent when a transaction is created.
+const EventTransactionCreated = "transaction.created"
+
+// EventTransactionUpdated is sent when a transaction is updated, e.g. settled or declined.
+const EventTransactionUpdated = "transaction.updated"
+
+const (
+	defaultTolerance = 5 * time.Minute
+	maxBodySize      = 1 << 20
+)
+
+// Errors returned when verifying a request
+var (
+	ErrInvalidSignatureHeader = errors.New("webhooks: invalid signature header")
+	ErrInvalidSignature       = errors.New("webhooks: invalid signature")
+	ErrTimestampOutOfRange    = errors.New("webhooks: timestamp out of tolerance")
+)
+
+// Event webhook event
+type Event struct {
+	ID        string          `json:"id"`
+	Type      string          `json:"type"`
+	CreatedAt time.Time       `json:"created_at"`
+	Data      json.RawMessage `json:"data"`
+}
+
+// transactionData data of transaction events
+type transactionData struct {
+	Transaction *goqonto.Transaction `json:"transaction"`
+}
+
+// Transaction decodes the transaction of a transaction event
+func (e *Event) Transaction() (*goqonto.Transaction, error) {
+	data := new(transactionData)
+	if err := json.Unmarshal(e.Data, data); err != nil {
+		return nil, err
+	}
+	if data.Transaction == nil {
+		return nil, fmt.Errorf("webhooks: event %s has no transaction", e.ID)
+	}
+	return data.Transaction, nil
+}
+
+// HandlerFunc handles an event. Returning an error makes the request fail so that Qonto delivers the
+// event again.
+type HandlerFunc func(ctx context.Context, e *Event) error
+
+// Deduplicator remembers the events already handled
+type Deduplicator interface {
+	// Claim returns false if the event was already claimed.
+	Claim(ctx context.Context, id string) (bool, error)
+
+	// Release forgets a claimed event whose handling failed.
+	Release(ctx context.Context, id string) error
+}
+
+// Handler http.Handler receiving webhook events
+type Handler struct {
+	secrets   []string
+	tolerance time.Duration
+	now       func() time.Time
+	dedup     Deduplicator
+
+	handlers map[string][]HandlerFunc
+	fallback []HandlerFunc
+}
+
+// HandlerOpt are options for New.
+type HandlerOpt func(*Handler) error
+
+// New returns a webhook handler verifying signatures with secret. Events are de-duplicated in memory
+// for 24 hours unless another Deduplicator is set.
+func New(secret string, opts ...HandlerOpt) (*Handler, error) {
+	if secret == "" {
+		return nil, errors.New("webhooks: empty secret")
+	}
+
+	h := &Handler{
+		secrets:   []string{secret},
+		tolerance: defaultTolerance,
+		now:       time.Now,
+		handlers:  make(map[string][]HandlerFunc),
+	}
+
+	for _, opt := range opts {
+		if err := opt(h); err != nil {
+			return nil, err
+		}
+	}
+
+	if h.dedup == nil {
+		d := NewMemoryDeduplicator(24 * time.Hour)
+		d.now = h.now
+		h.dedup = d
+	}
+
+	return h, nil
+}
+
+// SetTolerance is a handler option for setting the maximum age of a request timestamp.
+func SetTolerance(d time.Duration) HandlerOpt {
+	return func(h *Handler) error {
+		if d <= 0 {
+			return errors.New("webhooks: tolerance must be positive")
+		}
+		h.tolerance = d
+		return nil
+	}
+}
+
+// AddSecret is a handler option for accepting signatures made with another secret, while rotating it.
+func AddSecret(secret string) HandlerOpt {
+	return func(h *Handler) error {
+		if secret == "" {
+			return errors.New("webhooks: empty secret")
+		}
+		h.secrets = append(h.secrets, secret)
+		return nil
+	}
+}
+
+// SetDeduplicator is a handler option for setting the event de-duplicator.
+func SetDeduplicator(d Deduplicator) HandlerOpt {
+	return func(h *Handler) error {
+		h.dedup = d
+		return nil
+	}
+}
+
+// SetClock is a handler option for setting the function returning the current time. It is also used
+// by the default de-duplicator.
+func SetClock(now func() time.Time) HandlerOpt {
+	return func(h *Handler) error {
+		h.now = now
+		return nil
+	}
+}
+
+// On registers a handler for an event type. Handlers are called in registration order.
+func (h *Handler) On(eventType string, fn HandlerFunc) {
+	h.handlers[eventType] = append(h.handlers[eventType], fn)
+}
+
+// OnAny registers a handler for events without a handler for their type.
+func (h *Handler) OnAny(fn HandlerFunc) {
+	h.fallback = append(h.fallback, fn)
+}
+
+// ServeHTTP verifies, decodes, de-duplicates and dispatches an event. It responds with 401 to
+// requests with an invalid signature, 400 to invalid payloads, 500 when a handler fails and 200
+// otherwise, including for duplicate events.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, maxBodySize))
+	if err != nil {
+		http.Error(w, "unable to read body", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.Verify(r.Header.Get(SignatureHeader), body); err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	e := new(Event)
+	if err := json.Unmarshal(body, e); err != nil || e.ID == "" || e.Type == "" {
+		http.Error(w, "invalid event", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.dispatch(r.Context(), e); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func (h *Handler) dispatch(ctx context.Context, e *Event) error {
+	claimed, err := h.dedup.Claim(ctx, e.ID)
+	if err != nil || !claimed {
+		return err
+	}
+
+	handlers, ok := h.handlers[e.Type]
+	if !ok {
+		handlers = h.fallback
+	}
+
+	for _, fn := range handlers {
+		if err := fn(ctx, e); err != nil {
+			if rerr := h.dedup.Release(ctx, e.ID); rerr != nil {
+				return rerr
+			}
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Verify checks the signature header of a request body against the handler secrets and the
+// timestamp tolerance
+func (h *Handler) Verify(header string, body []byte) error {
+	timestamp, signatures, err := parseSignatureHeader(header)
+	if err != nil {
+		return err
+	}
+
+	age := h.now().Sub(time.Unix(timestamp, 0))
+	if age > h.tolerance || age < -h.tolerance {
+		return ErrTimestampOutOfRange
+	}
+
+	for _, secret := range h.secrets {
+		expected := computeSignature(secret, timestamp, body)
+		for _, s := range signatures {
+			if hmac.Equal(expected, s) {
+				return nil
+			}
+		}
+	}
+
+	return ErrInvalidSignature
+}
+
+// Sign returns the signature header of a body sent at timestamp, as computed by Qonto
+func Sign(secret string, timestamp time.Time, body []byte) string {
+	signature := computeSignature(secret, timestamp.Unix(), body)
+	return fmt.Sprintf("t=%d,v1=%s", timestamp.Unix(), hex.EncodeToString(signature))
+}
+
+func computeSignature(secret string, timestamp int64, body []byte) []byte {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return mac.Sum(nil)
+}
+
+func parseSignatureHeader(header string) (int64, [][]byte, error) {
+	var timestamp int64
+	var signatures [][]byte
+
+	for _, part := range strings.Split(header, ",") {
+		kv := strings.SplitN(strings.TrimSpace(part), "=", 2)
+		if len(kv) != 2 {
+			return 0, nil, ErrInvalidSignatureHeader
+		}
+
+		switch kv[0] {
+		case "t":
+			t, err := strconv.ParseInt(kv[1], 10, 64)
+			if err != nil {
+				return 0, nil, ErrInvalidSignatureHeader
+			}
+			timestamp = t
+		case "v1":
+			s, err := hex.DecodeString(kv[1])
+			if err != nil {
+				return 0, nil, ErrInvalidSignatureHeader
+			}
+			signatures = append(signatures, s)
+		}
+	}
+
+	if timestamp == 0 || len(signatures) == 0 {
+		return 0, nil, ErrInvalidSignatureHeader
+	}
+
+	return timestamp, signatures, nil
+}
+
+// MemoryDeduplicator in-memory Deduplicator forgetting events after a retention period. Expired
+// events are swept at most once per retention period.
+type MemoryDeduplicator struct {
+	mu        sync.Mutex
+	retention time.Duration
+	now       func() time.Time
+	seen      map[string]time.Time
+
+	// Time of the last sweep of the expired events.
+	swept time.Time
+}
+
+// NewMemoryDeduplicator returns a MemoryDeduplicator keeping event IDs for retention
+func NewMemoryDeduplicator(retention time.Duration) *MemoryDeduplicator {
+	return &MemoryDeduplicator{retention: retention, now: time.Now, seen: make(map[string]time.Time)}
+}
+
+// Claim returns false if the event was claimed during the retention period
+func (d *MemoryDeduplicator) Claim(_ context.Context, id string) (bool, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	now := d.now()
+	if now.Sub(d.swept) >= d.retention {
+		d.sweep(now)
+	}
+
+	if at, ok := d.seen[id]; ok && now.Sub(at) <= d.retention {
+		return false, nil
+	}
+
+	d.seen[id] = now
+	return true, nil
+}
+
+// sweep forgets the expired events
+func (d *MemoryDeduplicator) sweep(now time.Time) {
+	for k, at := range d.seen {
+		if now.Sub(at) > d.retention {
+			delete(d.seen, k)
+		}
+	}
+	d.swept = now
+}
+
+// Release forgets the event
+func (d *MemoryDeduplicator) Release(_ context.Context, id string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	delete(d.seen, id)
+	return nil
+}
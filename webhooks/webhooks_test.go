@@ -0,0 +1,195 @@
+package webhooks
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+const (
+	secret = "whsec_test"
+
+	eventFixture = `{
+		"id": "evt_1",
+		"type": "transaction.created",
+		"created_at": "2020-06-01T10:00:00Z",
+		"data": {
+			"transaction": {
+				"transaction_id": "mycompany-bank-account-1-transaction-491",
+				"amount_cents": 12600,
+				"side": "debit",
+				"status": "pending"
+			}
+		}
+	}`
+)
+
+var now = time.Date(2020, time.June, 1, 10, 0, 0, 0, time.UTC)
+
+func newHandler(t *testing.T, opts ...HandlerOpt) *Handler {
+	h, err := New(secret, append([]HandlerOpt{SetClock(func() time.Time { return now })}, opts...)...)
+	if err != nil {
+		t.Fatalf("New returned error: %v", err)
+	}
+	return h
+}
+
+func deliver(h http.Handler, body, signature string) int {
+	req := httptest.NewRequest(http.MethodPost, "/webhooks", strings.NewReader(body))
+	req.Header.Set(SignatureHeader, signature)
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec.Code
+}
+
+func TestHandler(t *testing.T) {
+	h := newHandler(t)
+
+	var amounts []int
+	h.On(EventTransactionCreated, func(ctx context.Context, e *Event) error {
+		trx, err := e.Transaction()
+		if err != nil {
+			return err
+		}
+		amounts = append(amounts, trx.AmountCents)
+		return nil
+	})
+
+	signature := Sign(secret, now.Add(-time.Minute), []byte(eventFixture))
+
+	if code := deliver(h, eventFixture, signature); code != http.StatusOK {
+		t.Errorf("ServeHTTP \n got %d\n want %d\n", code, http.StatusOK)
+	}
+
+	// Duplicate deliveries are acknowledged without being dispatched again.
+	if code := deliver(h, eventFixture, signature); code != http.StatusOK {
+		t.Errorf("ServeHTTP duplicate \n got %d\n want %d\n", code, http.StatusOK)
+	}
+
+	if len(amounts) != 1 || amounts[0] != 12600 {
+		t.Errorf("ServeHTTP dispatched \n got %v\n want [12600]\n", amounts)
+	}
+}
+
+func TestHandler_rejected(t *testing.T) {
+	h := newHandler(t)
+	h.OnAny(func(ctx context.Context, e *Event) error {
+		t.Errorf("Handler called for rejected event %s", e.ID)
+		return nil
+	})
+
+	body := []byte(eventFixture)
+	tests := []struct {
+		name      string
+		body      string
+		signature string
+		want      int
+	}{
+		{"missing signature", eventFixture, "", http.StatusUnauthorized},
+		{"wrong secret", eventFixture, Sign("other", now, body), http.StatusUnauthorized},
+		{"tampered body", strings.Replace(eventFixture, "12600", "99999", 1), Sign(secret, now, body),
+			http.StatusUnauthorized},
+		{"replayed", eventFixture, Sign(secret, now.Add(-10*time.Minute), body), http.StatusUnauthorized},
+		{"invalid payload", "{}", Sign(secret, now, []byte("{}")), http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		if code := deliver(h, tt.body, tt.signature); code != tt.want {
+			t.Errorf("ServeHTTP %s \n got %d\n want %d\n", tt.name, code, tt.want)
+		}
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/webhooks", nil)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("ServeHTTP GET \n got %d\n want %d\n", rec.Code, http.StatusMethodNotAllowed)
+	}
+}
+
+func TestHandler_retry(t *testing.T) {
+	h := newHandler(t, AddSecret("whsec_old"))
+
+	calls := 0
+	h.On(EventTransactionCreated, func(ctx context.Context, e *Event) error {
+		calls++
+		if calls == 1 {
+			return errors.New("database unavailable")
+		}
+		return nil
+	})
+
+	signature := Sign("whsec_old", now, []byte(eventFixture))
+
+	if code := deliver(h, eventFixture, signature); code != http.StatusInternalServerError {
+		t.Errorf("ServeHTTP failing handler \n got %d\n want %d\n", code, http.StatusInternalServerError)
+	}
+
+	// The failed event is released and handled again on retry.
+	if code := deliver(h, eventFixture, signature); code != http.StatusOK || calls != 2 {
+		t.Errorf("ServeHTTP retry \n got %d, %d calls\n want %d, 2 calls\n", code, calls, http.StatusOK)
+	}
+}
+
+func TestMemoryDeduplicator(t *testing.T) {
+	d := NewMemoryDeduplicator(time.Hour)
+	d.now = func() time.Time { return now }
+
+	ctx := context.Background()
+	if ok, _ := d.Claim(ctx, "evt_1"); !ok {
+		t.Errorf("Claim first \n got false\n want true\n")
+	}
+	if ok, _ := d.Claim(ctx, "evt_1"); ok {
+		t.Errorf("Claim duplicate \n got true\n want false\n")
+	}
+
+	d.now = func() time.Time { return now.Add(2 * time.Hour) }
+	if ok, _ := d.Claim(ctx, "evt_1"); !ok {
+		t.Errorf("Claim after retention \n got false\n want true\n")
+	}
+}
+
+func TestMemoryDeduplicator_sweep(t *testing.T) {
+	d := NewMemoryDeduplicator(time.Hour)
+	ctx := context.Background()
+
+	at := now
+	d.now = func() time.Time { return at }
+	d.Claim(ctx, "evt_1")
+
+	at = now.Add(50 * time.Minute)
+	d.Claim(ctx, "evt_2")
+
+	at = now.Add(70 * time.Minute)
+	d.Claim(ctx, "evt_3")
+	if _, ok := d.seen["evt_1"]; ok || len(d.seen) != 2 {
+		t.Errorf("seen events after sweep \n got %v\n want evt_2 and evt_3\n", d.seen)
+	}
+
+	// Expired events are kept until the next sweep, but can be claimed again.
+	at = now.Add(115 * time.Minute)
+	d.Claim(ctx, "evt_4")
+	if len(d.seen) != 3 {
+		t.Errorf("seen events before sweep \n got %d\n want %d\n", len(d.seen), 3)
+	}
+	if ok, _ := d.Claim(ctx, "evt_2"); !ok {
+		t.Errorf("Claim expired \n got false\n want true\n")
+	}
+}
+
+func TestNew_options(t *testing.T) {
+	if _, err := New(secret, AddSecret("")); err == nil {
+		t.Errorf("AddSecret(\"\") expected error to be returned")
+	}
+
+	h := newHandler(t)
+	d := h.dedup.(*MemoryDeduplicator)
+	if got := d.now(); !got.Equal(now) {
+		t.Errorf("de-duplicator clock \n got %v\n want %v\n", got, now)
+	}
+}
@@ -0,0 +1,208 @@
+package goqonto
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+)
+
+var (
+	webhookFixture = `{
+		"webhook_subscription": {
+			"id": "3c1e8f0e-5d7a-4b1e-9a63-2d6f0b9c8e41",
+			"url": "https://example.com/qonto/webhooks",
+			"event_types": ["transaction.created", "transaction.updated"],
+			"secret": "whsec_5f2b",
+			"created_at": "2021-03-12T10:15:02.123Z",
+			"updated_at": "2021-03-12T10:15:02.123Z"
+		}
+	}`
+
+	webhooksFixture = `{
+		"webhook_subscriptions": [
+			{
+				"id": "3c1e8f0e-5d7a-4b1e-9a63-2d6f0b9c8e41",
+				"url": "https://example.com/qonto/webhooks",
+				"event_types": ["transaction.created", "transaction.updated"],
+				"created_at": "2021-03-12T10:15:02.123Z",
+				"updated_at": "2021-03-12T10:15:02.123Z"
+			}
+		],
+		"meta": {
+			"current_page": 1,
+			"next_page": null,
+			"prev_page": null,
+			"total_pages": 1,
+			"total_count": 1,
+			"per_page": 10
+		}
+	}`
+
+	webhookCreatedAt, _ = time.Parse(time.RFC3339, "2021-03-12T10:15:02.123Z")
+
+	webhook = Webhook{
+		ID:         "3c1e8f0e-5d7a-4b1e-9a63-2d6f0b9c8e41",
+		URL:        "https://example.com/qonto/webhooks",
+		EventTypes: []string{"transaction.created", "transaction.updated"},
+		Secret:     "whsec_5f2b",
+		CreatedAt:  webhookCreatedAt,
+		UpdatedAt:  webhookCreatedAt,
+	}
+
+	webhookPath = fmt.Sprintf("/%s/3c1e8f0e-5d7a-4b1e-9a63-2d6f0b9c8e41", webhooksBasePath)
+)
+
+func TestWebhooksService_List(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc(fmt.Sprintf("/%s", webhooksBasePath), func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, http.MethodGet)
+		testHeader(t, r, "Accept", mediaType)
+		testBody(t, r, `{"current_page":1,"per_page":10}`+"\n")
+		fmt.Fprint(w, webhooksFixture)
+	})
+
+	got, resp, err := client.Webhooks.List(ctx, &WebhooksOptions{CurrentPage: 1, PerPage: 10})
+	if err != nil {
+		t.Fatalf("Webhooks.List returned error: %v", err)
+	}
+
+	want := webhook
+	want.Secret = ""
+
+	if !reflect.DeepEqual(got, []Webhook{want}) {
+		t.Errorf("Webhooks.List \n got %v\n want %v\n", got, []Webhook{want})
+	}
+
+	if resp.Meta == nil || resp.Meta.TotalCount != 1 {
+		t.Errorf("Webhooks.List meta \n got %v\n want total_count 1\n", resp.Meta)
+	}
+}
+
+func TestWebhooksService_Get(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc(webhookPath, func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, http.MethodGet)
+		fmt.Fprint(w, webhookFixture)
+	})
+
+	got, _, err := client.Webhooks.Get(ctx, webhook.ID)
+	if err != nil {
+		t.Errorf("Webhooks.Get returned error: %v", err)
+	}
+
+	if !reflect.DeepEqual(got, &webhook) {
+		t.Errorf("Webhooks.Get \n got %v\n want %v\n", got, &webhook)
+	}
+}
+
+func TestWebhooksService_Create(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc(fmt.Sprintf("/%s", webhooksBasePath), func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, http.MethodPost)
+		testHeader(t, r, "Content-Type", mediaType)
+		testBody(t, r, `{"webhook_subscription":{"url":"https://example.com/qonto/webhooks",`+
+			`"event_types":["transaction.created","transaction.updated"]}}`+"\n")
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprint(w, webhookFixture)
+	})
+
+	createRequest := &WebhookRequest{
+		URL:        "https://example.com/qonto/webhooks",
+		EventTypes: []string{"transaction.created", "transaction.updated"},
+	}
+
+	got, _, err := client.Webhooks.Create(ctx, createRequest)
+	if err != nil {
+		t.Errorf("Webhooks.Create returned error: %v", err)
+	}
+
+	if !reflect.DeepEqual(got, &webhook) {
+		t.Errorf("Webhooks.Create \n got %v\n want %v\n", got, &webhook)
+	}
+}
+
+func TestWebhooksService_Update(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc(webhookPath, func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, http.MethodPatch)
+		testBody(t, r, `{"webhook_subscription":{"rotate_secret":true}}`+"\n")
+		fmt.Fprint(w, webhookFixture)
+	})
+
+	got, _, err := client.Webhooks.Update(ctx, webhook.ID, &WebhookRequest{RotateSecret: true})
+	if err != nil {
+		t.Errorf("Webhooks.Update returned error: %v", err)
+	}
+
+	if !reflect.DeepEqual(got, &webhook) {
+		t.Errorf("Webhooks.Update \n got %v\n want %v\n", got, &webhook)
+	}
+}
+
+func TestWebhooksService_Delete(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc(webhookPath, func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, http.MethodDelete)
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	resp, err := client.Webhooks.Delete(ctx, webhook.ID)
+	if err != nil {
+		t.Errorf("Webhooks.Delete returned error: %v", err)
+	}
+
+	if resp.StatusCode != http.StatusNoContent {
+		t.Errorf("Webhooks.Delete \n got %d\n want %d\n", resp.StatusCode, http.StatusNoContent)
+	}
+}
+
+func TestWebhooksService_Test(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc(webhookPath+"/test", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, http.MethodPost)
+		testBody(t, r, `{"event_type":"transaction.created"}`+"\n")
+		w.WriteHeader(http.StatusAccepted)
+	})
+
+	resp, err := client.Webhooks.Test(ctx, webhook.ID, "transaction.created")
+	if err != nil {
+		t.Errorf("Webhooks.Test returned error: %v", err)
+	}
+
+	if resp.StatusCode != http.StatusAccepted {
+		t.Errorf("Webhooks.Test \n got %d\n want %d\n", resp.StatusCode, http.StatusAccepted)
+	}
+}
+
+func TestWebhooksService_Delete_Error(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{ "message": "Not found" }`)
+	})
+
+	resp, err := client.Webhooks.Delete(ctx, "unknown")
+	if err == nil {
+		t.Fatalf("Expected error to be returned")
+	}
+
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected 404 Status")
+	}
+}